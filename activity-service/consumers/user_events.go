@@ -0,0 +1,67 @@
+package consumers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"dailytrackr/activity-service/models"
+	"dailytrackr/activity-service/services"
+	"dailytrackr/shared/config"
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/events"
+)
+
+// ConsumerName identifies the activity service's offset on the event stream
+const ConsumerName = "activity-service"
+
+// UserEvents keeps activities in step with changes made by the user service
+type UserEvents struct {
+	db           *sql.DB
+	activityRepo *models.ActivityRepository
+	photoService *services.PhotoService
+}
+
+// NewUserEvents creates a new user events consumer
+func NewUserEvents(db *sql.DB, cfg *config.Config) *UserEvents {
+	return &UserEvents{
+		db:           db,
+		activityRepo: models.NewActivityRepository(db),
+		photoService: services.NewPhotoService(cfg),
+	}
+}
+
+// Handler routes the events this service consumes
+func (h *UserEvents) Handler() events.Handler {
+	return events.Route(map[string]events.Handler{
+		events.UserDeleted: h.userDeleted,
+	})
+}
+
+// userDeleted removes a deleted user's photos, then their activities, and
+// confirms the purge. Photos this service cannot have stored are left alone;
+// one it stored but cannot delete fails the event, so it is delivered again
+// while the activities still point at every photo, and the purge is only
+// confirmed once all of them are gone. Redelivery after that is harmless: the
+// second pass finds nothing left to delete and confirms again.
+func (h *UserEvents) userDeleted(ctx context.Context, event events.Event) error {
+	photoURLs, err := h.activityRepo.PhotoURLsByUserID(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range photoURLs {
+		if err := h.photoService.DeleteStoredPhoto(ctx, url); err != nil {
+			return fmt.Errorf("error deleting photo of deleted user %d: %v", event.UserID, err)
+		}
+	}
+
+	deleted, err := h.activityRepo.DeleteByUserID(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("activities removed for deleted user", "user_id", event.UserID, "activities", deleted, "photos", len(photoURLs))
+	return events.RecordPurged(ctx, h.db, event.UserID, constants.ActivityService, deleted)
+}
@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"database/sql"
-	"math"
+	"errors"
 	"strconv"
 	"time"
 
@@ -11,6 +11,11 @@ import (
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
 	"dailytrackr/shared/dto"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/i18n"
+	"dailytrackr/shared/middleware/fibermw"
+	"dailytrackr/shared/money"
+	"dailytrackr/shared/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -35,29 +40,27 @@ func (h *ActivityHandlers) CreateActivity(c *fiber.Ctx) error {
 	// Get user ID from middleware (will be set by auth middleware)
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	var req dto.CreateActivityRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid request body"))
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return sendError(c, utils.ValidationError(err))
 	}
 
 	// Parse start time
-	startTime, err := time.Parse(constants.DateTimeFormat, req.StartTime)
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid start_time format. Use: 2006-01-02T15:04:05Z",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid start_time format. Use RFC 3339, e.g. 2006-01-02T15:04:05Z"))
+	}
+
+	cost, err := h.cost(c, userID.(int64), req.Cost, req.Currency, nil)
+	if err != nil {
+		return sendError(c, apperrors.Internal("Failed to get default currency", err))
 	}
 
 	// Create activity
@@ -66,16 +69,12 @@ func (h *ActivityHandlers) CreateActivity(c *fiber.Ctx) error {
 		Title:        req.Title,
 		StartTime:    startTime,
 		DurationMins: req.DurationMins,
-		Cost:         req.Cost,
+		Cost:         cost,
 		Note:         req.Note,
 	}
 
-	if err := h.activityRepo.Create(activity); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to create activity",
-			"error":   err.Error(),
-		})
+	if err := h.activityRepo.Create(c.UserContext(), activity); err != nil {
+		return sendError(c, apperrors.Internal("Failed to create activity", err))
 	}
 
 	// Convert to response DTO
@@ -83,111 +82,89 @@ func (h *ActivityHandlers) CreateActivity(c *fiber.Ctx) error {
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"success": true,
-		"message": constants.MsgActivityCreated,
+		"message": i18n.T(fibermw.Lang(c), constants.MsgActivityCreated),
 		"data":    response,
 	})
 }
 
-// GetActivities handles getting user activities with pagination
+// GetActivities handles getting user activities with cursor or page pagination
 func (h *ActivityHandlers) GetActivities(c *fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "20"))
-
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 || limit > 100 {
-		limit = 20
+	params, err := utils.ParsePageParams(c.Query("cursor"), c.Query("page"), c.Query("limit"))
+	if err != nil {
+		return sendError(c, apperrors.Validation(constants.ErrInvalidCursor))
 	}
 
-	offset := (page - 1) * limit
+	query := models.ActivityQuery{
+		UserID: userID.(int64),
+		Cursor: params.Cursor,
+		Offset: params.Offset(),
+		Limit:  params.Limit,
+	}
 
 	// Check for date range filter
 	startDateStr := c.Query("start_date")
 	endDateStr := c.Query("end_date")
 
-	var activities []models.Activity
-	var total int
-	var err error
-
 	if startDateStr != "" && endDateStr != "" {
-		// Parse dates
-		startDate, err := time.Parse(constants.DateFormat, startDateStr)
+		// Parse dates as days in the user's time zone
+		loc := fibermw.Location(c)
+		startDate, err := utils.ParseDate(startDateStr, loc)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid start_date format. Use: 2006-01-02",
-			})
+			return sendError(c, apperrors.Validation("Invalid start_date format. Use: 2006-01-02"))
 		}
 
-		endDate, err := time.Parse(constants.DateFormat, endDateStr)
+		endDate, err := utils.ParseDate(endDateStr, loc)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid end_date format. Use: 2006-01-02",
-			})
+			return sendError(c, apperrors.Validation("Invalid end_date format. Use: 2006-01-02"))
 		}
 
 		// Set end date to end of day
 		endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 
-		activities, err = h.activityRepo.GetActivitiesByDateRange(userID.(int64), startDate, endDate)
-		total = len(activities)
-
-		// Apply pagination to filtered results
-		start := offset
-		end := offset + limit
-		if start > len(activities) {
-			activities = []models.Activity{}
-		} else {
-			if end > len(activities) {
-				end = len(activities)
-			}
-			activities = activities[start:end]
+		query.StartDate = &startDate
+		query.EndDate = &endDate
+	}
+
+	activities, more, err := h.activityRepo.List(c.UserContext(), query)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			return sendError(c, apperrors.Validation(constants.ErrInvalidCursor))
 		}
-	} else {
-		// Get activities with pagination
-		activities, total, err = h.activityRepo.GetByUserID(userID.(int64), limit, offset)
+		return sendError(c, apperrors.Internal("Failed to get activities", err))
 	}
 
+	total, err := h.activityRepo.Count(c.UserContext(), query)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to get activities",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to count activities", err))
 	}
 
 	// Convert to response DTOs
-	var responses []dto.ActivityResponse
+	responses := []dto.ActivityResponse{}
 	for _, activity := range activities {
 		responses = append(responses, h.convertToActivityResponse(&activity))
 	}
 
-	// Calculate total pages
-	totalPages := int(math.Ceil(float64(total) / float64(limit)))
+	var first, last *utils.Cursor
+	if len(activities) > 0 {
+		first, last = activities[0].Cursor(), activities[len(activities)-1].Cursor()
+	}
 
 	listResponse := dto.ActivityListResponse{
-		Activities: responses,
-		Total:      total,
-		Page:       page,
-		Limit:      limit,
+		Activities:         responses,
+		PaginationResponse: utils.NewPaginationResponse(params, total, more, first, last),
 	}
 
 	return c.JSON(fiber.Map{
 		"success":     true,
-		"message":     "Activities retrieved successfully",
+		"message":     i18n.T(fibermw.Lang(c), constants.MsgActivitiesRetrieved),
 		"data":        listResponse,
-		"total_pages": totalPages,
+		"total_pages": listResponse.TotalPages, // kept for clients that predate data.total_pages
 	})
 }
 
@@ -195,41 +172,28 @@ func (h *ActivityHandlers) GetActivities(c *fiber.Ctx) error {
 func (h *ActivityHandlers) GetActivity(c *fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	activityID, err := strconv.ParseInt(c.Params("id"), 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid activity ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid activity ID"))
 	}
 
 	var activity models.Activity
-	err = h.activityRepo.GetByID(activityID, userID.(int64), &activity)
+	err = h.activityRepo.GetByID(c.UserContext(), activityID, userID.(int64), &activity)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": constants.ErrActivityNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrActivityNotFound))
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to get activity",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get activity", err))
 	}
 
 	response := h.convertToActivityResponse(&activity)
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"message": "Activity retrieved successfully",
+		"message": i18n.T(fibermw.Lang(c), constants.MsgActivityRetrieved),
 		"data":    response,
 	})
 }
@@ -238,44 +202,31 @@ func (h *ActivityHandlers) GetActivity(c *fiber.Ctx) error {
 func (h *ActivityHandlers) UpdateActivity(c *fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	activityID, err := strconv.ParseInt(c.Params("id"), 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid activity ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid activity ID"))
 	}
 
 	var req dto.UpdateActivityRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid request body"))
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return sendError(c, utils.ValidationError(err))
 	}
 
 	// Get existing activity
 	var activity models.Activity
-	err = h.activityRepo.GetByID(activityID, userID.(int64), &activity)
+	err = h.activityRepo.GetByID(c.UserContext(), activityID, userID.(int64), &activity)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": constants.ErrActivityNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrActivityNotFound))
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to get activity",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get activity", err))
 	}
 
 	// Update fields if provided
@@ -283,12 +234,9 @@ func (h *ActivityHandlers) UpdateActivity(c *fiber.Ctx) error {
 		activity.Title = req.Title
 	}
 	if req.StartTime != "" {
-		startTime, err := time.Parse(constants.DateTimeFormat, req.StartTime)
+		startTime, err := time.Parse(time.RFC3339, req.StartTime)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"message": "Invalid start_time format. Use: 2006-01-02T15:04:05Z",
-			})
+			return sendError(c, apperrors.Validation("Invalid start_time format. Use RFC 3339, e.g. 2006-01-02T15:04:05Z"))
 		}
 		activity.StartTime = startTime
 	}
@@ -296,35 +244,30 @@ func (h *ActivityHandlers) UpdateActivity(c *fiber.Ctx) error {
 		activity.DurationMins = req.DurationMins
 	}
 	if req.Cost != nil {
-		activity.Cost = req.Cost
+		activity.Cost, err = h.cost(c, userID.(int64), req.Cost, req.Currency, activity.Cost)
+		if err != nil {
+			return sendError(c, apperrors.Internal("Failed to get default currency", err))
+		}
 	}
 	if req.Note != "" {
 		activity.Note = req.Note
 	}
 
-	if err := h.activityRepo.Update(&activity); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to update activity",
-			"error":   err.Error(),
-		})
+	if err := h.activityRepo.Update(c.UserContext(), &activity); err != nil {
+		return sendError(c, apperrors.Internal("Failed to update activity", err))
 	}
 
 	// Get updated activity
-	err = h.activityRepo.GetByID(activityID, userID.(int64), &activity)
+	err = h.activityRepo.GetByID(c.UserContext(), activityID, userID.(int64), &activity)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to get updated activity",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get updated activity", err))
 	}
 
 	response := h.convertToActivityResponse(&activity)
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"message": constants.MsgActivityUpdated,
+		"message": i18n.T(fibermw.Lang(c), constants.MsgActivityUpdated),
 		"data":    response,
 	})
 }
@@ -333,38 +276,25 @@ func (h *ActivityHandlers) UpdateActivity(c *fiber.Ctx) error {
 func (h *ActivityHandlers) DeleteActivity(c *fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	activityID, err := strconv.ParseInt(c.Params("id"), 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid activity ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid activity ID"))
 	}
 
-	err = h.activityRepo.Delete(activityID, userID.(int64))
+	err = h.activityRepo.Delete(c.UserContext(), activityID, userID.(int64))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": constants.ErrActivityNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrActivityNotFound))
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to delete activity",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to delete activity", err))
 	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"message": constants.MsgActivityDeleted,
+		"message": i18n.T(fibermw.Lang(c), constants.MsgActivityDeleted),
 	})
 }
 
@@ -372,65 +302,40 @@ func (h *ActivityHandlers) DeleteActivity(c *fiber.Ctx) error {
 func (h *ActivityHandlers) UploadPhoto(c *fiber.Ctx) error {
 	userID := c.Locals("user_id")
 	if userID == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	activityID, err := strconv.ParseInt(c.Params("id"), 10, 64)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "Invalid activity ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid activity ID"))
 	}
 
 	// Check if activity exists
 	var activity models.Activity
-	err = h.activityRepo.GetByID(activityID, userID.(int64), &activity)
+	err = h.activityRepo.GetByID(c.UserContext(), activityID, userID.(int64), &activity)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"success": false,
-				"message": constants.ErrActivityNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrActivityNotFound))
 		}
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to get activity",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get activity", err))
 	}
 
 	// Get file from form
 	file, err := c.FormFile("photo")
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"success": false,
-			"message": "No photo file provided",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "No photo file provided"))
 	}
 
 	// Upload photo using photo service
-	photoURL, err := h.photoService.UploadPhoto(file)
+	photoURL, err := h.photoService.UploadPhoto(c.UserContext(), file)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to upload photo",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to upload photo", err))
 	}
 
 	// Update activity with photo URL
-	err = h.activityRepo.UpdatePhotoURL(activityID, userID.(int64), photoURL)
+	err = h.activityRepo.UpdatePhotoURL(c.UserContext(), activityID, userID.(int64), photoURL)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Failed to update activity photo",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to update activity photo", err))
 	}
 
 	// ✅ FIXED: Use common DTO PhotoUploadResponse
@@ -441,11 +346,31 @@ func (h *ActivityHandlers) UploadPhoto(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"message": constants.MsgPhotoUploaded,
+		"message": i18n.T(fibermw.Lang(c), constants.MsgPhotoUploaded),
 		"data":    response,
 	})
 }
 
+// cost builds an activity cost from a request. A missing currency keeps the
+// activity's current one, or falls back to the user's default currency.
+func (h *ActivityHandlers) cost(c *fiber.Ctx, userID int64, amount *int64, currency string, current *money.Money) (*money.Money, error) {
+	if amount == nil {
+		return nil, nil
+	}
+	if currency == "" && current != nil {
+		currency = current.Currency
+	}
+	if currency == "" {
+		var err error
+		if currency, err = h.activityRepo.DefaultCurrency(c.UserContext(), userID); err != nil {
+			return nil, err
+		}
+	}
+
+	cost := money.New(*amount, currency)
+	return &cost, nil
+}
+
 // convertToActivityResponse converts Activity model to ActivityResponse DTO
 func (h *ActivityHandlers) convertToActivityResponse(activity *models.Activity) dto.ActivityResponse {
 	return dto.ActivityResponse{
@@ -461,3 +386,9 @@ func (h *ActivityHandlers) convertToActivityResponse(activity *models.Activity)
 		UpdatedAt:    activity.UpdatedAt,
 	}
 }
+
+// sendError writes err as an RFC 7807 problem response
+func sendError(c *fiber.Ctx, err error) error {
+	problem := apperrors.ToProblem(err, c.Path()).Localize(fibermw.Lang(c))
+	return c.Status(problem.Status).JSON(problem, apperrors.ContentTypeProblem)
+}
@@ -1,52 +1,95 @@
 package main
 
 import (
-	"log"
+	"context"
+	"os"
 	"time"
 
+	"dailytrackr/activity-service/consumers"
 	"dailytrackr/activity-service/handlers"
+	"dailytrackr/activity-service/models"
 	"dailytrackr/activity-service/routes"
 	"dailytrackr/shared/config"
+	"dailytrackr/shared/constants"
 	"dailytrackr/shared/database"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/events"
+	"dailytrackr/shared/health"
+	"dailytrackr/shared/lifecycle"
+	"dailytrackr/shared/logger"
+	"dailytrackr/shared/metrics"
+	"dailytrackr/shared/middleware/fibermw"
+	"dailytrackr/shared/sessions"
+	"dailytrackr/shared/tracing"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/joho/godotenv"
 )
 
 func main() {
-	// Load .env file
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: .env file not found, using system environment variables")
-	}
+	// Load .env file (LoadConfig reports a missing file)
+	_ = godotenv.Load()
 
 	// Load configuration
 	cfg := config.LoadConfig()
+	log := logger.Init(cfg, "activity-service")
+
+	shutdownTracing, err := tracing.Init(cfg, "activity-service")
+	if err != nil {
+		log.Error("failed to initialise tracing", "error", err)
+		os.Exit(1)
+	}
+
+	runner := lifecycle.New(log, cfg.ShutdownTimeout)
+	runner.OnStop("tracing", shutdownTracing)
 
 	// Initialize database connection
 	db, err := database.GetMySQLConnection(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	runner.OnStop("database", lifecycle.Closer(db.Close))
+	metrics.RegisterDBStats(metrics.PoolStats(db))
+
+	// Domain events: relay the outbox and follow user changes
+	migrations := append(events.Migrations, models.Migrations...)
+	if err := database.Migrate(context.Background(), db, cfg, migrations); err != nil {
+		log.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
+	transport, err := events.NewTransport(cfg)
+	if err != nil {
+		log.Error("failed to connect event transport", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
+	runner.OnStop("events", lifecycle.Closer(transport.Close))
+	relay := events.NewRelay(db, constants.ActivityService, transport, log, cfg.EventRelayInterval, cfg.EventRelayBatchSize)
+	runner.Go("outbox-relay", relay.Run)
+	runner.Go("user-events", events.Consume(transport, consumers.ConsumerName, consumers.NewUserEvents(db, cfg).Handler(), log))
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
+			// Map Fiber's own errors (404 route, 405, body limit) onto typed errors
 			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
+				err = apperrors.FromStatus(e.Code, e.Message, nil)
 			}
 
-			return c.Status(code).JSON(fiber.Map{
-				"success": false,
-				"message": "Internal server error",
-				"error":   err.Error(),
-			})
+			problem := apperrors.ToProblem(err, c.Path()).Localize(fibermw.Lang(c))
+			return c.Status(problem.Status).JSON(problem, apperrors.ContentTypeProblem)
 		},
 	})
 
+	app.Use(fibermw.RequestID())
+	app.Use(fibermw.Tracing())
+	app.Use(fibermw.Language())
+	app.Use(fibermw.AccessLog(log))
+	app.Use(fibermw.Metrics())
+
 	// ================================================
 	// FIXED CORS MIDDLEWARE - No AllowCredentials with wildcard
 	// ================================================
@@ -73,35 +116,32 @@ func main() {
 		return c.Next()
 	})
 
-	app.Use(logger.New())
+	// Prometheus metrics endpoint
+	app.Get("/metrics", fibermw.MetricsHandler())
 
 	// Health check endpoint
-	app.Get("/health", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"service": "activity-service",
-			"status":  "healthy",
-			"version": "1.0.0",
-			"cors":    "enabled",
-		})
-	})
+	checks := health.NewRegistry("activity-service", "1.0.0").
+		Critical("database", health.Ping(db)).
+		Optional("cloudinary", health.Cloudinary(cfg)).
+		Optional("event_outbox", relay.Lag())
+	app.Get("/health", fibermw.Health(checks))
 
 	// Initialize handlers
 	activityHandlers := handlers.NewActivityHandlers(db, cfg)
 
 	// Setup routes
-	routes.SetupActivityRoutes(app, activityHandlers)
+	routes.SetupActivityRoutes(app, activityHandlers, sessions.NewChecker(db))
 
 	// Start server
 	port := ":" + cfg.ActivityPort
-	log.Printf("🚀 Activity Service starting on port %s", port)
-	log.Printf("📋 Available endpoints:")
-	log.Printf("   - GET  /health")
-	log.Printf("   - POST /api/v1/activities")
-	log.Printf("   - GET  /api/v1/activities")
-	log.Printf("   - GET  /api/v1/activities/:id")
-	log.Printf("   - PUT  /api/v1/activities/:id")
-	log.Printf("   - DELETE /api/v1/activities/:id")
-	log.Printf("   - POST /api/v1/activities/:id/photo")
-
-	log.Fatal(app.Listen(port))
+	for _, route := range app.GetRoutes(true) {
+		log.Debug("route registered", "method", route.Method, "path", route.Path)
+	}
+
+	log.Info("activity service starting", "port", cfg.ActivityPort)
+	runner.AddServer("http", fibermw.Server(app, port))
+	if err := runner.Run(); err != nil {
+		log.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
 }
@@ -5,55 +5,76 @@ import (
 
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/middleware/fibermw"
+	"dailytrackr/shared/sessions"
 	"dailytrackr/shared/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // AuthMiddleware validates JWT tokens for Fiber
-func AuthMiddleware() fiber.Handler {
+func AuthMiddleware(checker *sessions.Checker) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Get authorization header
 		authHeader := c.Get(constants.AuthorizationHeader)
 		if authHeader == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": constants.ErrMissingToken,
-			})
+			return unauthorized(c, constants.ErrMissingToken)
 		}
 
 		// Check if header has Bearer prefix
 		if !strings.HasPrefix(authHeader, constants.BearerPrefix) {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": constants.ErrInvalidToken,
-			})
+			return unauthorized(c, constants.ErrInvalidToken)
 		}
 
 		// Extract token
 		token := strings.TrimPrefix(authHeader, constants.BearerPrefix)
 		if token == "" {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": constants.ErrInvalidToken,
-			})
+			return unauthorized(c, constants.ErrInvalidToken)
 		}
 
 		// Load config and validate token
 		cfg := config.LoadConfig()
 		claims, err := utils.ValidateJWT(token, cfg.JWTSecret)
 		if err != nil {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"success": false,
-				"message": constants.ErrInvalidToken,
-			})
+			return unauthorized(c, constants.ErrInvalidToken)
+		}
+
+		// Reject tokens signed out since they were issued, e.g. by a password reset
+		active, err := checker.Active(c.UserContext(), claims)
+		if err != nil {
+			problem := apperrors.ToProblem(apperrors.Internal("failed to check session", err), c.Path()).Localize(fibermw.Lang(c))
+			return c.Status(problem.Status).JSON(problem, apperrors.ContentTypeProblem)
+		}
+		if !active {
+			return unauthorized(c, constants.ErrInvalidToken)
+		}
+
+		// Unverified accounts may only do what UNVERIFIED_POLICY allows
+		if !claims.Allows(cfg.UnverifiedPolicy, c.Method()) {
+			return forbidden(c, constants.ErrEmailNotVerified)
 		}
 
 		// Set user information in context
 		c.Locals("user_id", claims.UserID)
 		c.Locals("username", claims.Username)
 		c.Locals("email", claims.Email)
+		fibermw.SetLanguagePreference(c, claims.Language)
+		fibermw.SetTimezone(c, claims.Timezone)
+		fibermw.SetPermissions(c, claims.Permissions)
 
 		return c.Next()
 	}
 }
+
+// unauthorized writes a 401 problem response
+func unauthorized(c *fiber.Ctx, message string) error {
+	problem := apperrors.ToProblem(apperrors.Unauthorized(message), c.Path()).Localize(fibermw.Lang(c))
+	return c.Status(problem.Status).JSON(problem, apperrors.ContentTypeProblem)
+}
+
+// forbidden writes a 403 problem response
+func forbidden(c *fiber.Ctx, message string) error {
+	problem := apperrors.ToProblem(apperrors.Forbidden(message), c.Path()).Localize(fibermw.Lang(c))
+	return c.Status(problem.Status).JSON(problem, apperrors.ContentTypeProblem)
+}
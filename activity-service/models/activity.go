@@ -1,22 +1,30 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/events"
+	"dailytrackr/shared/money"
+	"dailytrackr/shared/tracing"
+	"dailytrackr/shared/utils"
 )
 
 // Activity represents the activity model
 type Activity struct {
-	ID           int64     `json:"id" db:"id"`
-	UserID       int64     `json:"user_id" db:"user_id"`
-	Title        string    `json:"title" db:"title"`
-	StartTime    time.Time `json:"start_time" db:"start_time"`
-	DurationMins int       `json:"duration_mins" db:"duration_mins"`
-	Cost         *int      `json:"cost,omitempty" db:"cost"`
-	PhotoURL     string    `json:"photo_url,omitempty" db:"photo_url"`
-	Note         string    `json:"note,omitempty" db:"note"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID           int64        `json:"id" db:"id"`
+	UserID       int64        `json:"user_id" db:"user_id"`
+	Title        string       `json:"title" db:"title"`
+	StartTime    time.Time    `json:"start_time" db:"start_time"`
+	DurationMins int          `json:"duration_mins" db:"duration_mins"`
+	Cost         *money.Money `json:"cost,omitempty" db:"cost_amount"` // with cost_currency
+	PhotoURL     string       `json:"photo_url,omitempty" db:"photo_url"`
+	Note         string       `json:"note,omitempty" db:"note"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
 }
 
 // ActivityRepository handles database operations for activities
@@ -30,53 +38,68 @@ func NewActivityRepository(db *sql.DB) *ActivityRepository {
 }
 
 // Create creates a new activity in the database
-func (r *ActivityRepository) Create(activity *Activity) error {
+func (r *ActivityRepository) Create(ctx context.Context, activity *Activity) error {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.Create")
+	defer span.End()
+
 	query := `
-		INSERT INTO activities (user_id, title, start_time, duration_mins, cost, note) 
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO activities (user_id, title, start_time, duration_mins, cost_amount, cost_currency, note) 
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query,
-		activity.UserID,
-		activity.Title,
-		activity.StartTime,
-		activity.DurationMins,
-		activity.Cost,
-		activity.Note,
-	)
-	if err != nil {
-		return err
-	}
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		costAmount, costCurrency := activity.costColumns()
+		result, err := tx.ExecContext(ctx, query,
+			activity.UserID,
+			activity.Title,
+			activity.StartTime,
+			activity.DurationMins,
+			costAmount,
+			costCurrency,
+			activity.Note,
+		)
+		if err != nil {
+			return err
+		}
 
-	id, err := result.LastInsertId()
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		activity.ID = id
+		return recordActivityEvent(ctx, tx, events.ActivityCreated, activity, nil)
+	})
 	if err != nil {
 		return err
 	}
 
-	activity.ID = id
-
 	// Get the created activity to populate timestamps
-	return r.GetByID(activity.ID, activity.UserID, activity)
+	return r.GetByID(ctx, activity.ID, activity.UserID, activity)
 }
 
 // GetByID retrieves an activity by ID for a specific user
-func (r *ActivityRepository) GetByID(id, userID int64, activity *Activity) error {
+func (r *ActivityRepository) GetByID(ctx context.Context, id, userID int64, activity *Activity) error {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.GetByID")
+	defer span.End()
+
 	query := `
-		SELECT id, user_id, title, start_time, duration_mins, cost, photo_url, note, created_at, updated_at
+		SELECT id, user_id, title, start_time, duration_mins, cost_amount, cost_currency, photo_url, note, created_at, updated_at
 		FROM activities 
 		WHERE id = ? AND user_id = ?
 	`
 
-	var cost sql.NullInt64
-	var photoURL, note sql.NullString
+	var costAmount sql.NullInt64
+	var costCurrency, photoURL, note sql.NullString
 
-	err := r.db.QueryRow(query, id, userID).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(
 		&activity.ID,
 		&activity.UserID,
 		&activity.Title,
 		&activity.StartTime,
 		&activity.DurationMins,
-		&cost,
+		&costAmount,
+		&costCurrency,
 		&photoURL,
 		&note,
 		&activity.CreatedAt,
@@ -88,12 +111,7 @@ func (r *ActivityRepository) GetByID(id, userID int64, activity *Activity) error
 	}
 
 	// Handle NULL values
-	if cost.Valid {
-		costInt := int(cost.Int64)
-		activity.Cost = &costInt
-	} else {
-		activity.Cost = nil
-	}
+	activity.Cost = costFromColumns(costAmount, costCurrency)
 
 	if photoURL.Valid {
 		activity.PhotoURL = photoURL.String
@@ -110,217 +128,324 @@ func (r *ActivityRepository) GetByID(id, userID int64, activity *Activity) error
 	return nil
 }
 
-// GetByUserID retrieves all activities for a user with pagination
-func (r *ActivityRepository) GetByUserID(userID int64, limit, offset int) ([]Activity, int, error) {
-	// Get total count
-	var total int
-	countQuery := "SELECT COUNT(*) FROM activities WHERE user_id = ?"
-	err := r.db.QueryRow(countQuery, userID).Scan(&total)
-	if err != nil {
-		return nil, 0, err
+// ActivityQuery selects one page of a user's activities, newest first
+type ActivityQuery struct {
+	UserID    int64
+	StartDate *time.Time // optional start_time range, inclusive
+	EndDate   *time.Time
+	Cursor    *utils.Cursor // keyset position; nil pages by Offset
+	Offset    int
+	Limit     int
+}
+
+// filter returns the WHERE clause and arguments shared by List and Count
+func (q ActivityQuery) filter() (string, []interface{}) {
+	where := "user_id = ?"
+	args := []interface{}{q.UserID}
+
+	if q.StartDate != nil {
+		where += " AND start_time >= ?"
+		args = append(args, *q.StartDate)
+	}
+	if q.EndDate != nil {
+		where += " AND start_time <= ?"
+		args = append(args, *q.EndDate)
+	}
+
+	return where, args
+}
+
+// List retrieves one page of activities ordered by (start_time, id) descending.
+// The boolean reports whether more rows exist beyond the page in the direction
+// it was read, so concurrent inserts never shift a cursor page.
+func (r *ActivityRepository) List(ctx context.Context, q ActivityQuery) ([]Activity, bool, error) {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.List")
+	defer span.End()
+
+	where, args := q.filter()
+	order := "start_time DESC, id DESC"
+
+	if q.Cursor != nil {
+		key, err := time.Parse(time.RFC3339Nano, q.Cursor.Key)
+		if err != nil {
+			return nil, false, utils.ErrInvalidCursor
+		}
+
+		if q.Cursor.Backward {
+			where += " AND (start_time > ? OR (start_time = ? AND id > ?))"
+			order = "start_time ASC, id ASC"
+		} else {
+			where += " AND (start_time < ? OR (start_time = ? AND id < ?))"
+		}
+		args = append(args, key, key, q.Cursor.ID)
 	}
 
-	// Get activities with pagination
+	// Fetch one extra row to learn whether another page follows
 	query := `
-		SELECT id, user_id, title, start_time, duration_mins, cost, photo_url, note, created_at, updated_at
+		SELECT id, user_id, title, start_time, duration_mins, cost_amount, cost_currency, photo_url, note, created_at, updated_at
 		FROM activities 
-		WHERE user_id = ? 
-		ORDER BY start_time DESC 
+		WHERE ` + where + `
+		ORDER BY ` + order + `
 		LIMIT ? OFFSET ?
 	`
+	offset := q.Offset
+	if q.Cursor != nil {
+		offset = 0
+	}
+	args = append(args, q.Limit+1, offset)
 
-	rows, err := r.db.Query(query, userID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, false, err
 	}
 	defer rows.Close()
 
 	var activities []Activity
 	for rows.Next() {
 		var activity Activity
-		var cost sql.NullInt64
-		var photoURL, note sql.NullString
-
-		err := rows.Scan(
-			&activity.ID,
-			&activity.UserID,
-			&activity.Title,
-			&activity.StartTime,
-			&activity.DurationMins,
-			&cost,
-			&photoURL,
-			&note,
-			&activity.CreatedAt,
-			&activity.UpdatedAt,
-		)
-		if err != nil {
-			return nil, 0, err
+		if err := scanActivity(rows, &activity); err != nil {
+			return nil, false, err
 		}
+		activities = append(activities, activity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
 
-		// Handle NULL values
-		if cost.Valid {
-			costInt := int(cost.Int64)
-			activity.Cost = &costInt
-		} else {
-			activity.Cost = nil
-		}
+	more := len(activities) > q.Limit
+	if more {
+		activities = activities[:q.Limit]
+	}
 
-		if photoURL.Valid {
-			activity.PhotoURL = photoURL.String
-		} else {
-			activity.PhotoURL = ""
+	// Backward pages are read oldest first; restore newest-first order
+	if q.Cursor != nil && q.Cursor.Backward {
+		for i, j := 0, len(activities)-1; i < j; i, j = i+1, j-1 {
+			activities[i], activities[j] = activities[j], activities[i]
 		}
+	}
 
-		if note.Valid {
-			activity.Note = note.String
-		} else {
-			activity.Note = ""
-		}
+	return activities, more, nil
+}
 
-		activities = append(activities, activity)
-	}
+// Count returns how many activities match the query's filters, ignoring its cursor
+func (r *ActivityRepository) Count(ctx context.Context, q ActivityQuery) (int, error) {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.Count")
+	defer span.End()
+
+	where, args := q.filter()
 
-	return activities, total, nil
+	var total int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM activities WHERE "+where, args...).Scan(&total)
+	return total, err
 }
 
-// Update updates an activity
-func (r *ActivityRepository) Update(activity *Activity) error {
-	query := `
-		UPDATE activities 
-		SET title = ?, start_time = ?, duration_mins = ?, cost = ?, note = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ? AND user_id = ?
-	`
+// Cursor returns the keyset position of an activity
+func (a *Activity) Cursor() *utils.Cursor {
+	return &utils.Cursor{Key: a.StartTime.Format(time.RFC3339Nano), ID: a.ID}
+}
+
+// scanActivity reads one activities row, mapping NULL columns to zero values
+func scanActivity(rows *sql.Rows, activity *Activity) error {
+	var costAmount sql.NullInt64
+	var costCurrency, photoURL, note sql.NullString
 
-	result, err := r.db.Exec(query,
-		activity.Title,
-		activity.StartTime,
-		activity.DurationMins,
-		activity.Cost,
-		activity.Note,
-		activity.ID,
-		activity.UserID,
+	err := rows.Scan(
+		&activity.ID,
+		&activity.UserID,
+		&activity.Title,
+		&activity.StartTime,
+		&activity.DurationMins,
+		&costAmount,
+		&costCurrency,
+		&photoURL,
+		&note,
+		&activity.CreatedAt,
+		&activity.UpdatedAt,
 	)
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
+	// Handle NULL values
+	activity.Cost = costFromColumns(costAmount, costCurrency)
+
+	activity.PhotoURL = photoURL.String
+	activity.Note = note.String
+
+	return nil
+}
+
+// DefaultCurrency returns the currency saved in a user's profile, applied to
+// costs sent without one
+func (r *ActivityRepository) DefaultCurrency(ctx context.Context, userID int64) (string, error) {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.DefaultCurrency")
+	defer span.End()
+
+	var currency string
+	err := r.db.QueryRowContext(ctx, "SELECT currency FROM users WHERE id = ?", userID).Scan(&currency)
+	if err == sql.ErrNoRows {
+		return constants.DefaultCurrency, nil
 	}
+	return currency, err
+}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+// costColumns returns the cost_amount and cost_currency values to store, NULL when there is no cost
+func (a *Activity) costColumns() (interface{}, interface{}) {
+	if a.Cost == nil {
+		return nil, nil
 	}
+	return a.Cost.Amount, a.Cost.Currency
+}
 
-	return nil
+// costFromColumns rebuilds a cost from its nullable columns
+func costFromColumns(amount sql.NullInt64, currency sql.NullString) *money.Money {
+	if !amount.Valid {
+		return nil
+	}
+	cost := money.New(amount.Int64, currency.String)
+	return &cost
+}
+
+// Update updates an activity
+func (r *ActivityRepository) Update(ctx context.Context, activity *Activity) error {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.Update")
+	defer span.End()
+
+	query := `
+		UPDATE activities 
+		SET title = ?, start_time = ?, duration_mins = ?, cost_amount = ?, cost_currency = ?, note = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ?
+	`
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		previousStart, err := lockStartTime(ctx, tx, activity.ID, activity.UserID)
+		if err != nil {
+			return err
+		}
+
+		costAmount, costCurrency := activity.costColumns()
+		_, err = tx.ExecContext(ctx, query,
+			activity.Title,
+			activity.StartTime,
+			activity.DurationMins,
+			costAmount,
+			costCurrency,
+			activity.Note,
+			activity.ID,
+			activity.UserID,
+		)
+		if err != nil {
+			return err
+		}
+
+		var moved *time.Time
+		if !previousStart.Equal(activity.StartTime) {
+			moved = &previousStart
+		}
+		return recordActivityEvent(ctx, tx, events.ActivityUpdated, activity, moved)
+	})
 }
 
 // UpdatePhotoURL updates the photo URL for an activity
-func (r *ActivityRepository) UpdatePhotoURL(id, userID int64, photoURL string) error {
+func (r *ActivityRepository) UpdatePhotoURL(ctx context.Context, id, userID int64, photoURL string) error {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.UpdatePhotoURL")
+	defer span.End()
+
 	query := `
 		UPDATE activities 
 		SET photo_url = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND user_id = ?
 	`
 
-	result, err := r.db.Exec(query, photoURL, id, userID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		startTime, err := lockStartTime(ctx, tx, id, userID)
+		if err != nil {
+			return err
+		}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
+		if _, err := tx.ExecContext(ctx, query, photoURL, id, userID); err != nil {
+			return err
+		}
 
-	return nil
+		activity := &Activity{ID: id, UserID: userID, StartTime: startTime}
+		return recordActivityEvent(ctx, tx, events.ActivityUpdated, activity, nil)
+	})
 }
 
 // Delete deletes an activity
-func (r *ActivityRepository) Delete(id, userID int64) error {
-	query := "DELETE FROM activities WHERE id = ? AND user_id = ?"
+func (r *ActivityRepository) Delete(ctx context.Context, id, userID int64) error {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.Delete")
+	defer span.End()
 
-	result, err := r.db.Exec(query, id, userID)
-	if err != nil {
-		return err
-	}
+	query := "DELETE FROM activities WHERE id = ? AND user_id = ?"
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		startTime, err := lockStartTime(ctx, tx, id, userID)
+		if err != nil {
+			return err
+		}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
+		if _, err := tx.ExecContext(ctx, query, id, userID); err != nil {
+			return err
+		}
 
-	return nil
+		activity := &Activity{ID: id, UserID: userID, StartTime: startTime}
+		return recordActivityEvent(ctx, tx, events.ActivityDeleted, activity, nil)
+	})
 }
 
-// GetActivitiesByDateRange retrieves activities within a date range
-func (r *ActivityRepository) GetActivitiesByDateRange(userID int64, startDate, endDate time.Time) ([]Activity, error) {
-	query := `
-		SELECT id, user_id, title, start_time, duration_mins, cost, photo_url, note, created_at, updated_at
-		FROM activities 
-		WHERE user_id = ? AND start_time >= ? AND start_time <= ?
-		ORDER BY start_time DESC
-	`
+// PhotoURLsByUserID returns the photos attached to a user's activities
+func (r *ActivityRepository) PhotoURLsByUserID(ctx context.Context, userID int64) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.PhotoURLsByUserID")
+	defer span.End()
 
-	rows, err := r.db.Query(query, userID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, "SELECT photo_url FROM activities WHERE user_id = ? AND photo_url IS NOT NULL AND photo_url <> ''", userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var activities []Activity
+	var photoURLs []string
 	for rows.Next() {
-		var activity Activity
-		var cost sql.NullInt64
-		var photoURL, note sql.NullString
-
-		err := rows.Scan(
-			&activity.ID,
-			&activity.UserID,
-			&activity.Title,
-			&activity.StartTime,
-			&activity.DurationMins,
-			&cost,
-			&photoURL,
-			&note,
-			&activity.CreatedAt,
-			&activity.UpdatedAt,
-		)
-		if err != nil {
+		var url string
+		if err := rows.Scan(&url); err != nil {
 			return nil, err
 		}
+		photoURLs = append(photoURLs, url)
+	}
+	return photoURLs, rows.Err()
+}
 
-		// Handle NULL values
-		if cost.Valid {
-			costInt := int(cost.Int64)
-			activity.Cost = &costInt
-		} else {
-			activity.Cost = nil
-		}
+// DeleteByUserID removes every activity of a user and returns how many there were
+func (r *ActivityRepository) DeleteByUserID(ctx context.Context, userID int64) (int64, error) {
+	ctx, span := tracing.Start(ctx, "ActivityRepository.DeleteByUserID")
+	defer span.End()
 
-		if photoURL.Valid {
-			activity.PhotoURL = photoURL.String
-		} else {
-			activity.PhotoURL = ""
-		}
+	result, err := r.db.ExecContext(ctx, "DELETE FROM activities WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, err
+	}
 
-		if note.Valid {
-			activity.Note = note.String
-		} else {
-			activity.Note = ""
-		}
+	return result.RowsAffected()
+}
 
-		activities = append(activities, activity)
-	}
+// lockStartTime locks an activity row for the rest of tx and returns its start time.
+// It returns sql.ErrNoRows when the activity does not belong to the user.
+func lockStartTime(ctx context.Context, tx *sql.Tx, id, userID int64) (time.Time, error) {
+	var startTime time.Time
+	err := tx.QueryRowContext(ctx, "SELECT start_time FROM activities WHERE id = ? AND user_id = ? FOR UPDATE", id, userID).Scan(&startTime)
+	return startTime, err
+}
 
-	return activities, nil
+// recordActivityEvent writes an activity.* event to the outbox within tx
+func recordActivityEvent(ctx context.Context, tx *sql.Tx, eventType string, activity *Activity, previousStart *time.Time) error {
+	event, err := events.New(eventType, activity.UserID, events.ActivityPayload{
+		ActivityID:        activity.ID,
+		StartTime:         activity.StartTime,
+		PreviousStartTime: previousStart,
+	})
+	if err != nil {
+		return err
+	}
+	return events.Record(ctx, tx, event)
 }
@@ -0,0 +1,18 @@
+package models
+
+import "dailytrackr/shared/database"
+
+// Migrations lists the schema changes owned by the activity service, oldest first
+var Migrations = []database.Migration{
+	{
+		ID:  "activity-service/001_activities_cost_currency",
+		SQL: "ALTER TABLE activities ADD COLUMN cost_amount BIGINT NULL, ADD COLUMN cost_currency CHAR(3) NULL",
+	},
+	{
+		// Costs used to be whole rupiah in the cost column; the column is kept
+		// for rollback, and the backfill skips rows that already have an amount
+		ID: "activity-service/002_activities_cost_backfill",
+		SQL: `UPDATE activities SET cost_amount = cost * 100, cost_currency = 'IDR'
+			WHERE cost IS NOT NULL AND cost_amount IS NULL`,
+	},
+}
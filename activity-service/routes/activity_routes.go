@@ -3,15 +3,16 @@ package routes
 import (
 	"dailytrackr/activity-service/handlers"
 	"dailytrackr/activity-service/middleware"
+	"dailytrackr/shared/sessions"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // SetupActivityRoutes sets up all activity-related routes
-func SetupActivityRoutes(app *fiber.App, activityHandlers *handlers.ActivityHandlers) {
+func SetupActivityRoutes(app *fiber.App, activityHandlers *handlers.ActivityHandlers, checker *sessions.Checker) {
 	// API v1 routes with authentication
 	api := app.Group("/api/v1")
-	api.Use(middleware.AuthMiddleware())
+	api.Use(middleware.AuthMiddleware(checker))
 
 	// Activity routes
 	activities := api.Group("/activities")
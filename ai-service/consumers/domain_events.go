@@ -0,0 +1,73 @@
+package consumers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"dailytrackr/ai-service/models"
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/events"
+)
+
+// ConsumerName identifies the AI service's offset on the event stream
+const ConsumerName = "ai-service"
+
+// DomainEvents invalidates cached AI output when the data behind it changes
+type DomainEvents struct {
+	db     *database.DB
+	aiRepo *models.AIRepository
+}
+
+// NewDomainEvents creates a new domain events consumer
+func NewDomainEvents(db *database.DB) *DomainEvents {
+	return &DomainEvents{db: db, aiRepo: models.NewAIRepository(db)}
+}
+
+// Handler routes the events this service consumes
+func (h *DomainEvents) Handler() events.Handler {
+	return events.Route(map[string]events.Handler{
+		events.ActivityCreated: h.activityChanged,
+		events.ActivityUpdated: h.activityChanged,
+		events.ActivityDeleted: h.activityChanged,
+		events.UserDeleted:     h.userDeleted,
+	})
+}
+
+// activityChanged drops the daily summaries of the day the activity is on,
+// and of the day it moved from when an update changed its start time. Days are
+// taken in the user's time zone, as summaries are generated.
+func (h *DomainEvents) activityChanged(ctx context.Context, event events.Event) error {
+	var payload events.ActivityPayload
+	if err := event.Decode(&payload); err != nil {
+		slog.Warn("skipping malformed activity event", "event_id", event.ID, "error", err)
+		return nil
+	}
+
+	loc, err := h.aiRepo.GetUserLocation(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	dates := []time.Time{payload.StartTime}
+	if payload.PreviousStartTime != nil {
+		dates = append(dates, *payload.PreviousStartTime)
+	}
+
+	for _, date := range dates {
+		if err := h.aiRepo.DeleteDailySummary(ctx, event.UserID, date.In(loc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// userDeleted removes a deleted user's summaries, then confirms the purge
+func (h *DomainEvents) userDeleted(ctx context.Context, event events.Event) error {
+	deleted, err := h.aiRepo.DeleteUserSummaries(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+	return events.RecordPurged(ctx, h.db.DB, event.UserID, constants.AIService, deleted)
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"database/sql"
 	"strconv"
 	"time"
 
@@ -9,6 +8,9 @@ import (
 	"dailytrackr/ai-service/services"
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/middleware/ginmw"
 	"dailytrackr/shared/utils"
 
 	"github.com/gin-gonic/gin"
@@ -21,7 +23,7 @@ type AIHandlers struct {
 }
 
 // NewAIHandlers creates a new AI handlers instance
-func NewAIHandlers(db *sql.DB, cfg *config.Config) *AIHandlers {
+func NewAIHandlers(db *database.DB, cfg *config.Config) *AIHandlers {
 	return &AIHandlers{
 		aiRepo:    models.NewAIRepository(db),
 		geminiSvc: services.NewGeminiService(cfg),
@@ -37,30 +39,31 @@ func (h *AIHandlers) GenerateDailySummary(c *gin.Context) {
 		return
 	}
 
-	// Parse date (optional, defaults to today)
+	// Parse date (optional, defaults to today) as a day in the user's time zone
+	loc := ginmw.Location(c)
 	dateStr := c.Query("date")
 	var targetDate time.Time
 	var err error
 
 	if dateStr != "" {
-		targetDate, err = time.Parse(constants.DateFormat, dateStr)
+		targetDate, err = utils.ParseDate(dateStr, loc)
 		if err != nil {
 			utils.SendBadRequestResponse(c.Writer, "Invalid date format. Use: 2006-01-02", err)
 			return
 		}
 	} else {
-		targetDate = time.Now()
+		targetDate = utils.Today(loc)
 	}
 
 	// Check if summary already exists for this date
-	existingSummary, err := h.aiRepo.GetDailySummary(userID.(int64), targetDate)
+	existingSummary, err := h.aiRepo.GetDailySummary(c.Request.Context(), userID.(int64), targetDate)
 	if err == nil && existingSummary != nil {
-		utils.SendSuccessResponse(c.Writer, "Daily summary retrieved from cache", existingSummary)
+		utils.SendSuccessResponse(c.Writer, constants.MsgDailySummaryCached, existingSummary)
 		return
 	}
 
 	// Get user activities for the target date
-	activities, err := h.aiRepo.GetUserActivitiesForDate(userID.(int64), targetDate)
+	activities, err := h.aiRepo.GetUserActivitiesForDate(c.Request.Context(), userID.(int64), targetDate)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get user activities", err)
 		return
@@ -72,7 +75,7 @@ func (h *AIHandlers) GenerateDailySummary(c *gin.Context) {
 	}
 
 	// Generate AI summary
-	summary, err := h.geminiSvc.GenerateDailySummary(activities)
+	summary, err := h.geminiSvc.GenerateDailySummary(c.Request.Context(), activities)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate AI summary", err)
 		return
@@ -86,12 +89,29 @@ func (h *AIHandlers) GenerateDailySummary(c *gin.Context) {
 		AIGenerated: true,
 	}
 
-	if err := h.aiRepo.SaveDailySummary(summaryRecord); err != nil {
+	if err := h.aiRepo.SaveDailySummary(c.Request.Context(), summaryRecord); err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to save summary", err)
 		return
 	}
 
-	utils.SendSuccessResponse(c.Writer, "Daily summary generated successfully", summaryRecord)
+	utils.SendSuccessResponse(c.Writer, constants.MsgDailySummaryGenerated, summaryRecord)
+}
+
+// ListDailySummaries returns the user's saved daily summaries without generating new ones
+func (h *AIHandlers) ListDailySummaries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	summaries, err := h.aiRepo.ListDailySummaries(c.Request.Context(), userID.(int64))
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to list daily summaries", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgDailySummariesRetrieved, summaries)
 }
 
 // GenerateHabitRecommendation handles generating habit recommendations using AI
@@ -112,10 +132,11 @@ func (h *AIHandlers) GenerateHabitRecommendation(c *gin.Context) {
 	}
 
 	// Get user activities from last N days
-	endDate := time.Now()
+	loc := ginmw.Location(c)
+	endDate := time.Now().In(loc)
 	startDate := endDate.AddDate(0, 0, -days)
 
-	activities, err := h.aiRepo.GetUserActivitiesForPeriod(userID.(int64), startDate, endDate)
+	activities, err := h.aiRepo.GetUserActivitiesForPeriod(c.Request.Context(), userID.(int64), startDate, endDate)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get user activities", err)
 		return
@@ -127,29 +148,29 @@ func (h *AIHandlers) GenerateHabitRecommendation(c *gin.Context) {
 	}
 
 	// Get existing habits to avoid duplicates
-	existingHabits, err := h.aiRepo.GetUserHabits(userID.(int64))
+	existingHabits, err := h.aiRepo.GetUserHabits(c.Request.Context(), userID.(int64), loc)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get existing habits", err)
 		return
 	}
 
 	// Generate AI recommendation
-	recommendation, err := h.geminiSvc.GenerateHabitRecommendation(activities, existingHabits)
+	recommendation, err := h.geminiSvc.GenerateHabitRecommendation(c.Request.Context(), activities, existingHabits)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate habit recommendation", err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"recommendation":   recommendation,
-		"based_on_days":    days,
-		"total_activities": len(activities),
-		"existing_habits":  len(existingHabits),
-		"analysis_period":  startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
-		"generated_at":     time.Now(),
+	response := dto.HabitRecommendationResponse{
+		Recommendation:  recommendation,
+		BasedOnDays:     days,
+		TotalActivities: len(activities),
+		ExistingHabits:  len(existingHabits),
+		AnalysisPeriod:  startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
+		GeneratedAt:     time.Now(),
 	}
 
-	utils.SendSuccessResponse(c.Writer, "Habit recommendation generated successfully", response)
+	utils.SendSuccessResponse(c.Writer, constants.MsgRecommendationGenerated, response)
 }
 
 // GetInsights handles getting AI insights for user
@@ -161,7 +182,7 @@ func (h *AIHandlers) GetInsights(c *gin.Context) {
 	}
 
 	// Get user data for insights
-	insights, err := h.aiRepo.GetUserInsights(userID.(int64))
+	insights, err := h.aiRepo.GetUserInsights(c.Request.Context(), userID.(int64), ginmw.Location(c))
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get user insights", err)
 		return
@@ -169,13 +190,13 @@ func (h *AIHandlers) GetInsights(c *gin.Context) {
 
 	// Generate AI insights if user has enough data
 	if insights.TotalActivities > 5 {
-		aiInsights, err := h.geminiSvc.GenerateInsights(insights)
+		aiInsights, err := h.geminiSvc.GenerateInsights(c.Request.Context(), insights)
 		if err == nil {
 			insights.AIInsights = aiInsights
 		}
 	}
 
-	utils.SendSuccessResponse(c.Writer, "User insights retrieved successfully", insights)
+	utils.SendSuccessResponse(c.Writer, constants.MsgInsightsRetrieved, insights)
 }
 
 // AnalyzeActivities handles analyzing recent activities
@@ -196,10 +217,10 @@ func (h *AIHandlers) AnalyzeActivities(c *gin.Context) {
 	}
 
 	// Get recent activities
-	endDate := time.Now()
+	endDate := time.Now().In(ginmw.Location(c))
 	startDate := endDate.AddDate(0, 0, -days)
 
-	activities, err := h.aiRepo.GetUserActivitiesForPeriod(userID.(int64), startDate, endDate)
+	activities, err := h.aiRepo.GetUserActivitiesForPeriod(c.Request.Context(), userID.(int64), startDate, endDate)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get activities", err)
 		return
@@ -211,21 +232,21 @@ func (h *AIHandlers) AnalyzeActivities(c *gin.Context) {
 	}
 
 	// Generate analysis
-	analysis, err := h.geminiSvc.AnalyzeActivities(activities, days)
+	analysis, err := h.geminiSvc.AnalyzeActivities(c.Request.Context(), activities, days)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to analyze activities", err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"analysis":         analysis,
-		"period_days":      days,
-		"activities_count": len(activities),
-		"period":           startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
-		"generated_at":     time.Now(),
+	response := dto.ActivityAnalysisResponse{
+		Analysis:        analysis,
+		PeriodDays:      days,
+		ActivitiesCount: len(activities),
+		Period:          startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
+		GeneratedAt:     time.Now(),
 	}
 
-	utils.SendSuccessResponse(c.Writer, "Activity analysis completed successfully", response)
+	utils.SendSuccessResponse(c.Writer, constants.MsgAnalysisCompleted, response)
 }
 
 // GetProductivityTips handles getting AI-powered productivity tips
@@ -237,25 +258,25 @@ func (h *AIHandlers) GetProductivityTips(c *gin.Context) {
 	}
 
 	// Get user context for personalized tips
-	userContext, err := h.aiRepo.GetUserContext(userID.(int64))
+	userContext, err := h.aiRepo.GetUserContext(c.Request.Context(), userID.(int64), ginmw.Location(c))
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get user context", err)
 		return
 	}
 
 	// Generate personalized tips
-	tips, err := h.geminiSvc.GenerateProductivityTips(userContext)
+	tips, err := h.geminiSvc.GenerateProductivityTips(c.Request.Context(), userContext)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate productivity tips", err)
 		return
 	}
 
-	response := map[string]interface{}{
-		"tips":         tips,
-		"personalized": true,
-		"based_on":     "User activity patterns and habits",
-		"generated_at": time.Now(),
+	response := dto.ProductivityTipsResponse{
+		Tips:         tips,
+		Personalized: true,
+		BasedOn:      "User activity patterns and habits",
+		GeneratedAt:  time.Now(),
 	}
 
-	utils.SendSuccessResponse(c.Writer, "Productivity tips generated successfully", response)
+	utils.SendSuccessResponse(c.Writer, constants.MsgProductivityTipsGenerated, response)
 }
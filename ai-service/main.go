@@ -1,14 +1,26 @@
 package main
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"os"
 	"time"
 
+	"dailytrackr/ai-service/consumers"
 	"dailytrackr/ai-service/handlers"
 	"dailytrackr/ai-service/routes"
 	"dailytrackr/shared/config"
+	"dailytrackr/shared/constants"
 	"dailytrackr/shared/database"
+	"dailytrackr/shared/events"
+	"dailytrackr/shared/flags"
+	"dailytrackr/shared/health"
+	"dailytrackr/shared/lifecycle"
+	"dailytrackr/shared/logger"
+	"dailytrackr/shared/metrics"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/sessions"
+	"dailytrackr/shared/tracing"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -17,25 +29,69 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	log := logger.Init(cfg, "ai-service")
+
+	shutdownTracing, err := tracing.Init(cfg, "ai-service")
+	if err != nil {
+		log.Error("failed to initialise tracing", "error", err)
+		os.Exit(1)
+	}
+
+	runner := lifecycle.New(log, cfg.ShutdownTimeout)
+	runner.OnStop("tracing", shutdownTracing)
 
 	// Check Gemini API key
 	if cfg.GeminiAPIKey == "" {
-		log.Fatal("❌ GEMINI_API_KEY is required for AI service. Please set it in .env file")
+		log.Error("GEMINI_API_KEY is required for AI service, set it in .env")
+		os.Exit(1)
 	}
 
 	// Initialize database connection
-	db, err := database.GetMySQLConnection(cfg)
+	db, err := database.NewDB(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Error("failed to connect to database", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
+	runner.OnStop("database", lifecycle.Closer(db.Close))
+	metrics.RegisterDBStats(db.Stats)
+
+	// The outbox carries the confirmations of purged users' summaries
+	if err := database.Migrate(context.Background(), db.DB, cfg, events.Migrations); err != nil {
+		log.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
+	// Domain events: drop cached summaries when activities change
+	transport, err := events.NewTransport(cfg)
+	if err != nil {
+		log.Error("failed to connect event transport", "error", err)
+		os.Exit(1)
+	}
+	runner.OnStop("events", lifecycle.Closer(transport.Close))
+	relay := events.NewRelay(db.DB, constants.AIService, transport, log, cfg.EventRelayInterval, cfg.EventRelayBatchSize)
+	runner.Go("outbox-relay", relay.Run)
+	runner.Go("domain-events", events.Consume(transport, consumers.ConsumerName, consumers.NewDomainEvents(db).Handler(), log))
+
+	// Feature flags: AI features are rolled out per user
+	featureFlags, err := flags.New(context.Background(), cfg, db.Reader(), log)
+	if err != nil {
+		log.Error("failed to load feature flags", "error", err)
+		os.Exit(1)
+	}
+	runner.Go("feature-flags", featureFlags.Run(cfg.FlagsReloadInterval))
 
 	// Setup Gin router
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(ginmw.RequestID())
+	r.Use(ginmw.Tracing())
+	r.Use(ginmw.Language())
+	r.Use(ginmw.AccessLog(log))
+	r.Use(ginmw.Metrics())
 
 	// ================================================
 	// FIXED CORS MIDDLEWARE - No AllowCredentials with wildcard
@@ -64,25 +120,29 @@ func main() {
 		c.Next()
 	})
 
+	// Prometheus metrics endpoint
+	r.GET("/metrics", ginmw.MetricsHandler())
+
 	// Health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"service":    "ai-service",
-			"status":     "healthy",
-			"version":    "1.0.0",
-			"cors":       "enabled",
-			"ai_enabled": cfg.GeminiAPIKey != "",
-		})
-	})
+	checks := health.NewRegistry("ai-service", "1.0.0").
+		Critical("database", health.Ping(db.DB)).
+		Optional("database_replicas", health.Replicas(db.UnhealthyReplicas)).
+		Critical("gemini", health.Configured(cfg.GeminiAPIKey)).
+		Optional("event_outbox", relay.Lag())
+	r.GET("/health", ginmw.Health(checks))
 
 	// Initialize handlers
 	aiHandlers := handlers.NewAIHandlers(db, cfg)
 
 	// Setup routes
-	routes.SetupAIRoutes(r, aiHandlers)
+	routes.SetupAIRoutes(r, aiHandlers, featureFlags, sessions.NewChecker(db.DB))
 
 	// Start server
 	port := ":" + cfg.AIPort
-	log.Printf("🚀 AI Service starting on port %s", port)
-	log.Fatal(r.Run(port))
+	log.Info("ai service starting", "port", cfg.AIPort)
+	runner.AddServer("http", ginmw.Server(r, port))
+	if err := runner.Run(); err != nil {
+		log.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
 }
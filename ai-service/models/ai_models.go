@@ -1,39 +1,44 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/money"
+	"dailytrackr/shared/tracing"
+	"dailytrackr/shared/utils"
 )
 
-// AIRepository handles database operations for AI service
+// AIRepository handles database operations for AI service.
+// Context queries (activities, habits, insights) are routed to read replicas;
+// daily summaries stay on the primary so a freshly saved summary is visible immediately.
 type AIRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
 // NewAIRepository creates a new AI repository
-func NewAIRepository(db *sql.DB) *AIRepository {
+func NewAIRepository(db *database.DB) *AIRepository {
 	return &AIRepository{db: db}
 }
 
-// DailySummary represents daily summary model
-type DailySummary struct {
-	ID          int64     `json:"id"`
-	UserID      int64     `json:"user_id"`
-	Date        time.Time `json:"date"`
-	SummaryText string    `json:"summary_text"`
-	AIGenerated bool      `json:"ai_generated"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-}
+// Daily summaries and insights are returned as-is, so their shapes live in dto
+type (
+	DailySummary = dto.DailySummary
+	UserInsights = dto.UserInsights
+)
 
 // Activity represents activity model for AI analysis
 type Activity struct {
-	ID           int64     `json:"id"`
-	Title        string    `json:"title"`
-	StartTime    time.Time `json:"start_time"`
-	DurationMins int       `json:"duration_mins"`
-	Cost         *int      `json:"cost,omitempty"`
-	Note         string    `json:"note,omitempty"`
+	ID           int64        `json:"id"`
+	Title        string       `json:"title"`
+	StartTime    time.Time    `json:"start_time"`
+	DurationMins int          `json:"duration_mins"`
+	Cost         *money.Money `json:"cost,omitempty"`
+	Note         string       `json:"note,omitempty"`
 }
 
 // Habit represents habit model for AI analysis
@@ -44,21 +49,6 @@ type Habit struct {
 	Progress int    `json:"progress"`
 }
 
-// UserInsights represents comprehensive user insights
-type UserInsights struct {
-	UserID             int64     `json:"user_id"`
-	TotalActivities    int       `json:"total_activities"`
-	TotalHours         float64   `json:"total_hours"`
-	TotalExpenses      int       `json:"total_expenses"`
-	ActiveHabits       int       `json:"active_habits"`
-	AvgDailyHours      float64   `json:"avg_daily_hours"`
-	MostProductiveTime string    `json:"most_productive_time"`
-	TopActivityType    string    `json:"top_activity_type"`
-	SpendingPattern    string    `json:"spending_pattern"`
-	AIInsights         string    `json:"ai_insights,omitempty"`
-	LastUpdated        time.Time `json:"last_updated"`
-}
-
 // UserContext represents user context for personalized recommendations
 type UserContext struct {
 	UserID          int64   `json:"user_id"`
@@ -70,7 +60,10 @@ type UserContext struct {
 }
 
 // GetDailySummary retrieves daily summary for a user and date
-func (r *AIRepository) GetDailySummary(userID int64, date time.Time) (*DailySummary, error) {
+func (r *AIRepository) GetDailySummary(ctx context.Context, userID int64, date time.Time) (*DailySummary, error) {
+	ctx, span := tracing.Start(ctx, "AIRepository.GetDailySummary")
+	defer span.End()
+
 	summary := &DailySummary{}
 
 	query := `
@@ -79,7 +72,7 @@ func (r *AIRepository) GetDailySummary(userID int64, date time.Time) (*DailySumm
 		WHERE user_id = ? AND date = ?
 	`
 
-	err := r.db.QueryRow(query, userID, date.Format("2006-01-02")).Scan(
+	err := r.db.QueryRowContext(ctx, query, userID, date.Format("2006-01-02")).Scan(
 		&summary.ID,
 		&summary.UserID,
 		&summary.Date,
@@ -97,7 +90,10 @@ func (r *AIRepository) GetDailySummary(userID int64, date time.Time) (*DailySumm
 }
 
 // SaveDailySummary saves daily summary to database
-func (r *AIRepository) SaveDailySummary(summary *DailySummary) error {
+func (r *AIRepository) SaveDailySummary(ctx context.Context, summary *DailySummary) error {
+	ctx, span := tracing.Start(ctx, "AIRepository.SaveDailySummary")
+	defer span.End()
+
 	query := `
 		INSERT INTO daily_summary (user_id, date, summary_text, ai_generated) 
 		VALUES (?, ?, ?, ?)
@@ -107,7 +103,7 @@ func (r *AIRepository) SaveDailySummary(summary *DailySummary) error {
 		updated_at = CURRENT_TIMESTAMP
 	`
 
-	result, err := r.db.Exec(query,
+	result, err := r.db.ExecContext(ctx, query,
 		summary.UserID,
 		summary.Date.Format("2006-01-02"),
 		summary.SummaryText,
@@ -129,24 +125,105 @@ func (r *AIRepository) SaveDailySummary(summary *DailySummary) error {
 	return nil
 }
 
-// GetUserActivitiesForDate retrieves user activities for a specific date
-func (r *AIRepository) GetUserActivitiesForDate(userID int64, date time.Time) ([]Activity, error) {
-	startDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endDate := startDate.Add(24*time.Hour - time.Second)
+// ListDailySummaries returns every saved daily summary of a user, oldest first
+func (r *AIRepository) ListDailySummaries(ctx context.Context, userID int64) ([]DailySummary, error) {
+	ctx, span := tracing.Start(ctx, "AIRepository.ListDailySummaries")
+	defer span.End()
+
+	query := `
+		SELECT id, user_id, date, summary_text, ai_generated, created_at, updated_at
+		FROM daily_summary
+		WHERE user_id = ?
+		ORDER BY date
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	return r.GetUserActivitiesForPeriod(userID, startDate, endDate)
+	summaries := []DailySummary{}
+	for rows.Next() {
+		var summary DailySummary
+		err := rows.Scan(
+			&summary.ID,
+			&summary.UserID,
+			&summary.Date,
+			&summary.SummaryText,
+			&summary.AIGenerated,
+			&summary.CreatedAt,
+			&summary.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, rows.Err()
+}
+
+// DeleteDailySummary removes a cached daily summary so the next request regenerates it
+func (r *AIRepository) DeleteDailySummary(ctx context.Context, userID int64, date time.Time) error {
+	ctx, span := tracing.Start(ctx, "AIRepository.DeleteDailySummary")
+	defer span.End()
+
+	_, err := r.db.ExecContext(ctx, "DELETE FROM daily_summary WHERE user_id = ? AND date = ?", userID, date.Format("2006-01-02"))
+	return err
+}
+
+// GetUserLocation returns the time zone saved in a user's profile, or UTC when the
+// user no longer exists
+func (r *AIRepository) GetUserLocation(ctx context.Context, userID int64) (*time.Location, error) {
+	ctx, span := tracing.Start(ctx, "AIRepository.GetUserLocation")
+	defer span.End()
+
+	var timezone string
+	err := r.db.QueryRowContext(ctx, "SELECT timezone FROM users WHERE id = ?", userID).Scan(&timezone)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return utils.Location(timezone), nil
+}
+
+// DeleteUserSummaries removes every daily summary of a user and returns how many there were
+func (r *AIRepository) DeleteUserSummaries(ctx context.Context, userID int64) (int64, error) {
+	ctx, span := tracing.Start(ctx, "AIRepository.DeleteUserSummaries")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM daily_summary WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetUserActivitiesForDate retrieves user activities for a specific date, with the
+// day's boundaries taken in date's location
+func (r *AIRepository) GetUserActivitiesForDate(ctx context.Context, userID int64, date time.Time) ([]Activity, error) {
+	ctx, span := tracing.Start(ctx, "AIRepository.GetUserActivitiesForDate")
+	defer span.End()
+
+	startDate := utils.StartOfDay(date, date.Location())
+	endDate := startDate.AddDate(0, 0, 1).Add(-time.Second)
+
+	return r.GetUserActivitiesForPeriod(ctx, userID, startDate, endDate)
 }
 
 // GetUserActivitiesForPeriod retrieves user activities for a date range
-func (r *AIRepository) GetUserActivitiesForPeriod(userID int64, startDate, endDate time.Time) ([]Activity, error) {
+func (r *AIRepository) GetUserActivitiesForPeriod(ctx context.Context, userID int64, startDate, endDate time.Time) ([]Activity, error) {
+	ctx, span := tracing.Start(ctx, "AIRepository.GetUserActivitiesForPeriod")
+	defer span.End()
+
 	query := `
-		SELECT id, title, start_time, duration_mins, cost, note
+		SELECT id, title, start_time, duration_mins, cost_amount, cost_currency, note
 		FROM activities 
 		WHERE user_id = ? AND start_time BETWEEN ? AND ?
 		ORDER BY start_time ASC
 	`
 
-	rows, err := r.db.Query(query, userID, startDate, endDate)
+	rows, err := r.db.Reader().QueryContext(ctx, query, userID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -155,24 +232,25 @@ func (r *AIRepository) GetUserActivitiesForPeriod(userID int64, startDate, endDa
 	var activities []Activity
 	for rows.Next() {
 		var activity Activity
-		var cost sql.NullInt64
-		var note sql.NullString
+		var costAmount sql.NullInt64
+		var costCurrency, note sql.NullString
 
 		err := rows.Scan(
 			&activity.ID,
 			&activity.Title,
 			&activity.StartTime,
 			&activity.DurationMins,
-			&cost,
+			&costAmount,
+			&costCurrency,
 			&note,
 		)
 		if err != nil {
 			continue
 		}
 
-		if cost.Valid {
-			costInt := int(cost.Int64)
-			activity.Cost = &costInt
+		if costAmount.Valid {
+			cost := money.New(costAmount.Int64, costCurrency.String)
+			activity.Cost = &cost
 		}
 
 		if note.Valid {
@@ -185,17 +263,23 @@ func (r *AIRepository) GetUserActivitiesForPeriod(userID int64, startDate, endDa
 	return activities, nil
 }
 
-// GetUserHabits retrieves user habits for recommendations
-func (r *AIRepository) GetUserHabits(userID int64) ([]Habit, error) {
+// GetUserHabits retrieves user habits for recommendations, judging their status
+// against the current date in loc
+func (r *AIRepository) GetUserHabits(ctx context.Context, userID int64, loc *time.Location) ([]Habit, error) {
+	ctx, span := tracing.Start(ctx, "AIRepository.GetUserHabits")
+	defer span.End()
+
+	today := utils.LocalDate(time.Now(), loc)
+
 	query := `
 		SELECT h.id, h.title,
 		       CASE 
-		           WHEN h.start_date > CURDATE() THEN 'upcoming'
-		           WHEN h.end_date < CURDATE() THEN 'completed'
+		           WHEN h.start_date > ? THEN 'upcoming'
+		           WHEN h.end_date < ? THEN 'completed'
 		           ELSE 'active'
 		       END as status,
 		       COALESCE(
-		           (SELECT COUNT(*) * 100 / DATEDIFF(LEAST(h.end_date, CURDATE()), h.start_date)
+		           (SELECT COUNT(*) * 100 / DATEDIFF(LEAST(h.end_date, ?), h.start_date)
 		            FROM habit_logs hl 
 		            WHERE hl.habit_id = h.id AND hl.status = 'DONE'), 0
 		       ) as progress
@@ -204,7 +288,7 @@ func (r *AIRepository) GetUserHabits(userID int64) ([]Habit, error) {
 		ORDER BY h.created_at DESC
 	`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.Reader().QueryContext(ctx, query, today, today, today, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -230,54 +314,68 @@ func (r *AIRepository) GetUserHabits(userID int64) ([]Habit, error) {
 	return habits, nil
 }
 
-// GetUserInsights retrieves comprehensive user insights
-func (r *AIRepository) GetUserInsights(userID int64) (*UserInsights, error) {
+// GetUserInsights retrieves comprehensive user insights, with days taken in loc
+func (r *AIRepository) GetUserInsights(ctx context.Context, userID int64, loc *time.Location) (*UserInsights, error) {
+	ctx, span := tracing.Start(ctx, "AIRepository.GetUserInsights")
+	defer span.End()
+
+	now := time.Now()
+	today := utils.LocalDate(now, loc)
+
 	insights := &UserInsights{
 		UserID:      userID,
+		Currency:    constants.DefaultCurrency,
 		LastUpdated: time.Now(),
 	}
 
-	// Basic activity stats
-	err := r.db.QueryRow(`
+	err := r.db.Reader().QueryRowContext(ctx, "SELECT currency FROM users WHERE id = ?", userID).Scan(&insights.Currency)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Basic activity stats; expenses count costs in the user's currency only
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT COUNT(*), 
 		       COALESCE(SUM(duration_mins), 0) / 60.0,
-		       COALESCE(SUM(cost), 0)
+		       COALESCE(SUM(CASE WHEN cost_currency = ? THEN cost_amount END), 0)
 		FROM activities 
 		WHERE user_id = ?
-	`, userID).Scan(&insights.TotalActivities, &insights.TotalHours, &insights.TotalExpenses)
+	`, insights.Currency, userID).Scan(&insights.TotalActivities, &insights.TotalHours, &insights.TotalExpenses)
 
 	if err != nil {
 		return nil, err
 	}
 
 	// Active habits count
-	err = r.db.QueryRow(`
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT COUNT(*)
 		FROM habits 
-		WHERE user_id = ? AND start_date <= CURDATE() AND end_date >= CURDATE()
-	`, userID).Scan(&insights.ActiveHabits)
+		WHERE user_id = ? AND start_date <= ? AND end_date >= ?
+	`, userID, today, today).Scan(&insights.ActiveHabits)
 
 	if err != nil {
 		return nil, err
 	}
 
 	// Average daily hours (last 30 days)
-	err = r.db.QueryRow(`
+	since := utils.StartOfDay(now, loc).AddDate(0, 0, -30)
+	localTime, args := utils.SQLLocalTime("start_time", loc, since, now)
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT COALESCE(AVG(daily_hours), 0)
 		FROM (
-			SELECT SUM(duration_mins) / 60.0 as daily_hours
+			SELECT DATE(`+localTime+`) as activity_date, SUM(duration_mins) / 60.0 as daily_hours
 			FROM activities 
-			WHERE user_id = ? AND start_time >= DATE_SUB(CURDATE(), INTERVAL 30 DAY)
-			GROUP BY DATE(start_time)
+			WHERE user_id = ? AND start_time >= ?
+			GROUP BY activity_date
 		) daily_stats
-	`, userID).Scan(&insights.AvgDailyHours)
+	`, append(args, userID, since)...).Scan(&insights.AvgDailyHours)
 
 	if err != nil {
 		return nil, err
 	}
 
 	// Most productive time
-	err = r.db.QueryRow(`
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT HOUR(start_time) as hour, COUNT(*) as count
 		FROM activities 
 		WHERE user_id = ? 
@@ -298,14 +396,20 @@ func (r *AIRepository) GetUserInsights(userID int64) (*UserInsights, error) {
 	return insights, nil
 }
 
-// GetUserContext retrieves user context for personalized recommendations
-func (r *AIRepository) GetUserContext(userID int64) (*UserContext, error) {
+// GetUserContext retrieves user context for personalized recommendations, with
+// days taken in loc
+func (r *AIRepository) GetUserContext(ctx context.Context, userID int64, loc *time.Location) (*UserContext, error) {
+	ctx, span := tracing.Start(ctx, "AIRepository.GetUserContext")
+	defer span.End()
+
+	now := time.Now()
+
 	context := &UserContext{
 		UserID: userID,
 	}
 
 	// Get username
-	err := r.db.QueryRow(`
+	err := r.db.Reader().QueryRowContext(ctx, `
 		SELECT username FROM users WHERE id = ?
 	`, userID).Scan(&context.Username)
 
@@ -314,17 +418,19 @@ func (r *AIRepository) GetUserContext(userID int64) (*UserContext, error) {
 	}
 
 	// Get activity and habit counts
-	err = r.db.QueryRow(`
+	since := utils.StartOfDay(now, loc).AddDate(0, 0, -7)
+	localTime, zoneArgs := utils.SQLLocalTime("start_time", loc, since, now)
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT 
 			(SELECT COUNT(*) FROM activities WHERE user_id = ?) as total_activities,
 			(SELECT COUNT(*) FROM habits WHERE user_id = ?) as total_habits,
 			COALESCE((SELECT AVG(daily_hours) FROM (
-				SELECT SUM(duration_mins) / 60.0 as daily_hours
+				SELECT DATE(`+localTime+`) as activity_date, SUM(duration_mins) / 60.0 as daily_hours
 				FROM activities 
-				WHERE user_id = ? AND start_time >= DATE_SUB(CURDATE(), INTERVAL 7 DAY)
-				GROUP BY DATE(start_time)
+				WHERE user_id = ? AND start_time >= ?
+				GROUP BY activity_date
 			) recent_daily), 0) as avg_daily_hours
-	`, userID, userID, userID).Scan(
+	`, append(append([]interface{}{userID, userID}, zoneArgs...), userID, since)...).Scan(
 		&context.TotalActivities,
 		&context.TotalHabits,
 		&context.AvgDailyHours,
@@ -4,6 +4,9 @@ import (
 	"dailytrackr/ai-service/handlers"
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
+	"dailytrackr/shared/flags"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/sessions"
 	"dailytrackr/shared/utils"
 	"strings"
 
@@ -11,7 +14,7 @@ import (
 )
 
 // AuthMiddleware validates JWT tokens for Gin (AI service specific)
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(checker *sessions.Checker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get authorization header
 		authHeader := c.GetHeader(constants.AuthorizationHeader)
@@ -45,23 +48,50 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens signed out since they were issued, e.g. by a password reset
+		active, err := checker.Active(c.Request.Context(), claims)
+		if err != nil {
+			utils.SendInternalServerErrorResponse(c.Writer, "Failed to check session", err)
+			c.Abort()
+			return
+		}
+		if !active {
+			utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+			c.Abort()
+			return
+		}
+
+		// Unverified accounts may only do what UNVERIFIED_POLICY allows
+		if !claims.Allows(cfg.UnverifiedPolicy, c.Request.Method) {
+			utils.SendForbiddenResponse(c.Writer, constants.ErrEmailNotVerified)
+			c.Abort()
+			return
+		}
+
 		// Set user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
+		ginmw.SetLanguagePreference(c, claims.Language)
+		ginmw.SetTimezone(c, claims.Timezone)
+		ginmw.SetPermissions(c, claims.Permissions)
 
 		c.Next()
 	}
 }
 
 // SetupAIRoutes sets up all AI-related routes
-func SetupAIRoutes(r *gin.Engine, aiHandlers *handlers.AIHandlers) {
+func SetupAIRoutes(r *gin.Engine, aiHandlers *handlers.AIHandlers, featureFlags *flags.Store, checker *sessions.Checker) {
 	// API v1 routes with authentication
 	api := r.Group("/api/v1")
-	api.Use(AuthMiddleware())
+	api.Use(AuthMiddleware(checker))
+
+	// Saved summaries stay readable, e.g. for data exports, when AI is off for the user
+	api.GET("/ai/daily-summaries", aiHandlers.ListDailySummaries)
 
-	// AI routes
+	// AI routes, only for users the AI flag is rolled out to
 	ai := api.Group("/ai")
+	ai.Use(ginmw.RequireFlag(featureFlags, flags.AI))
 	{
 		// Daily summary generation
 		ai.POST("/daily-summary", aiHandlers.GenerateDailySummary)
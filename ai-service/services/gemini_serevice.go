@@ -2,14 +2,20 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"dailytrackr/ai-service/models"
 	"dailytrackr/shared/config"
+	"dailytrackr/shared/metrics"
+	"dailytrackr/shared/money"
+	"dailytrackr/shared/tracing"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type GeminiService struct {
@@ -55,20 +61,20 @@ func NewGeminiService(cfg *config.Config) *GeminiService {
 }
 
 // GenerateDailySummary generates AI-powered daily summary
-func (g *GeminiService) GenerateDailySummary(activities []models.Activity) (string, error) {
+func (g *GeminiService) GenerateDailySummary(ctx context.Context, activities []models.Activity) (string, error) {
 	// Build activity summary for prompt
 	var activityDetails strings.Builder
 	totalTime := 0
-	totalCost := 0
+	var costs []money.Money
 
 	activityDetails.WriteString("Aktivitas hari ini:\n")
 	for i, activity := range activities {
 		activityDetails.WriteString(fmt.Sprintf("%d. %s (%d menit)",
 			i+1, activity.Title, activity.DurationMins))
 
-		if activity.Cost != nil && *activity.Cost > 0 {
-			activityDetails.WriteString(fmt.Sprintf(" - Biaya: Rp%d", *activity.Cost))
-			totalCost += *activity.Cost
+		if activity.Cost != nil && activity.Cost.Amount > 0 {
+			activityDetails.WriteString(fmt.Sprintf(" - Biaya: %s", activity.Cost))
+			costs = append(costs, *activity.Cost)
 		}
 
 		if activity.Note != "" {
@@ -81,7 +87,7 @@ func (g *GeminiService) GenerateDailySummary(activities []models.Activity) (stri
 
 	activityDetails.WriteString(fmt.Sprintf("\nTotal waktu: %d menit (%.1f jam)",
 		totalTime, float64(totalTime)/60.0))
-	activityDetails.WriteString(fmt.Sprintf("\nTotal pengeluaran: Rp%d", totalCost))
+	activityDetails.WriteString(fmt.Sprintf("\nTotal pengeluaran: %s", money.Join(money.Sum(costs...))))
 
 	prompt := fmt.Sprintf(`
 Buatkan ringkasan harian yang menarik dan motivational berdasarkan aktivitas berikut:
@@ -98,11 +104,11 @@ Tugas:
 Format ringkasan dalam paragraf yang mengalir, bukan poin-poin.
 `, activityDetails.String())
 
-	return g.callGeminiAPI(prompt)
+	return g.callGeminiAPI(ctx, prompt)
 }
 
 // GenerateHabitRecommendation generates AI-powered habit recommendations
-func (g *GeminiService) GenerateHabitRecommendation(activities []models.Activity, existingHabits []models.Habit) (string, error) {
+func (g *GeminiService) GenerateHabitRecommendation(ctx context.Context, activities []models.Activity, existingHabits []models.Habit) (string, error) {
 	// Analyze activity patterns
 	activityTypes := make(map[string]int)
 	timePatterns := make(map[int]int) // hour -> count
@@ -165,18 +171,18 @@ Format:
 - Fokus pada habit yang sustainable dan achievable
 `, existingHabitsStr.String(), activityAnalysis.String())
 
-	return g.callGeminiAPI(prompt)
+	return g.callGeminiAPI(ctx, prompt)
 }
 
 // GenerateInsights generates AI-powered user insights
-func (g *GeminiService) GenerateInsights(insights *models.UserInsights) (string, error) {
+func (g *GeminiService) GenerateInsights(ctx context.Context, insights *models.UserInsights) (string, error) {
 	prompt := fmt.Sprintf(`
 Analisis data produktivitas user dan berikan insights yang valuable:
 
 Data User:
 - Total aktivitas: %d
 - Total waktu: %.1f jam
-- Total pengeluaran: Rp%d
+- Total pengeluaran: %s
 - Habit aktif: %d
 - Rata-rata jam harian: %.1f jam
 - Waktu paling produktif: %s
@@ -193,7 +199,7 @@ Format: Paragraf yang mengalir, bahasa Indonesia yang engaging dan insightful.
 `,
 		insights.TotalActivities,
 		insights.TotalHours,
-		insights.TotalExpenses,
+		money.New(int64(insights.TotalExpenses), insights.Currency),
 		insights.ActiveHabits,
 		insights.AvgDailyHours,
 		insights.MostProductiveTime,
@@ -201,21 +207,21 @@ Format: Paragraf yang mengalir, bahasa Indonesia yang engaging dan insightful.
 		insights.SpendingPattern,
 	)
 
-	return g.callGeminiAPI(prompt)
+	return g.callGeminiAPI(ctx, prompt)
 }
 
 // AnalyzeActivities generates activity analysis
-func (g *GeminiService) AnalyzeActivities(activities []models.Activity, days int) (string, error) {
+func (g *GeminiService) AnalyzeActivities(ctx context.Context, activities []models.Activity, days int) (string, error) {
 	// Calculate metrics
 	totalTime := 0
-	totalCost := 0
+	var costs []money.Money
 	activityTypes := make(map[string]int)
 	dailyDistribution := make(map[string]int)
 
 	for _, activity := range activities {
 		totalTime += activity.DurationMins
 		if activity.Cost != nil {
-			totalCost += *activity.Cost
+			costs = append(costs, *activity.Cost)
 		}
 
 		category := categorizeActivity(activity.Title)
@@ -234,7 +240,7 @@ Statistik:
 - Total aktivitas: %d
 - Total waktu: %d menit (%.1f jam)
 - Rata-rata harian: %.1f jam
-- Total pengeluaran: Rp%d
+- Total pengeluaran: %s
 - Rata-rata per aktivitas: %.1f menit
 
 Tugas:
@@ -251,15 +257,15 @@ Format: Analisis yang comprehensive tapi easy to digest, bahasa Indonesia yang p
 		totalTime,
 		float64(totalTime)/60.0,
 		avgDailyTime,
-		totalCost,
+		money.Join(money.Sum(costs...)),
 		float64(totalTime)/float64(len(activities)),
 	)
 
-	return g.callGeminiAPI(prompt)
+	return g.callGeminiAPI(ctx, prompt)
 }
 
 // GenerateProductivityTips generates personalized productivity tips
-func (g *GeminiService) GenerateProductivityTips(context *models.UserContext) (string, error) {
+func (g *GeminiService) GenerateProductivityTips(ctx context.Context, userContext *models.UserContext) (string, error) {
 	prompt := fmt.Sprintf(`
 Berikan tips produktivitas yang personal untuk user dengan profil:
 
@@ -281,18 +287,27 @@ Format:
 - Bahasa Indonesia yang motivational
 - Fokus pada practical implementation
 `,
-		context.Username,
-		context.TotalActivities,
-		context.TotalHabits,
-		context.AvgDailyHours,
-		context.RecentPatterns,
+		userContext.Username,
+		userContext.TotalActivities,
+		userContext.TotalHabits,
+		userContext.AvgDailyHours,
+		userContext.RecentPatterns,
 	)
 
-	return g.callGeminiAPI(prompt)
+	return g.callGeminiAPI(ctx, prompt)
 }
 
 // callGeminiAPI makes actual API call to Gemini
-func (g *GeminiService) callGeminiAPI(prompt string) (string, error) {
+func (g *GeminiService) callGeminiAPI(ctx context.Context, prompt string) (text string, err error) {
+	ctx, span := tracing.StartClient(ctx, "gemini.generateContent",
+		attribute.Int("gemini.prompt_length", len(prompt)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	start := time.Now()
+	reason := ""
+	defer func() { metrics.ObserveGemini(reason, time.Since(start)) }()
+
 	// Construct request
 	request := GeminiRequest{
 		Contents: []GeminiContent{
@@ -307,13 +322,15 @@ func (g *GeminiService) callGeminiAPI(prompt string) (string, error) {
 	// Marshal request
 	jsonData, err := json.Marshal(request)
 	if err != nil {
+		reason = "request"
 		return "", fmt.Errorf("failed to marshal request: %v", err)
 	}
 
 	// Create HTTP request
 	url := fmt.Sprintf("%s?key=%s", g.baseURL, g.apiKey)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
+		reason = "request"
 		return "", fmt.Errorf("failed to create request: %v", err)
 	}
 
@@ -322,6 +339,7 @@ func (g *GeminiService) callGeminiAPI(prompt string) (string, error) {
 	// Make request
 	resp, err := g.client.Do(req)
 	if err != nil {
+		reason = "transport"
 		return "", fmt.Errorf("failed to make request: %v", err)
 	}
 	defer resp.Body.Close()
@@ -329,20 +347,24 @@ func (g *GeminiService) callGeminiAPI(prompt string) (string, error) {
 	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
+		reason = "transport"
 		return "", fmt.Errorf("failed to read response: %v", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		reason = "http_status"
 		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Parse response
 	var geminiResp GeminiResponse
 	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		reason = "decode"
 		return "", fmt.Errorf("failed to parse response: %v", err)
 	}
 
 	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		reason = "empty_response"
 		return "", fmt.Errorf("no content in response")
 	}
 
@@ -2,6 +2,7 @@
 // Props: activity, onEdit, onDelete
 import React from 'react';
 import Button from '../UI/Button';
+import { formatMoney } from '../../utils/helpers';
 
 const ActivityCard = ({ activity, onEdit, onDelete }) => {
   const formatTime = (timeString) => {
@@ -13,15 +14,6 @@ const ActivityCard = ({ activity, onEdit, onDelete }) => {
     });
   };
 
-  const formatCurrency = (amount) => {
-    if (!amount) return null;
-    return new Intl.NumberFormat('id-ID', {
-      style: 'currency',
-      currency: 'IDR',
-      minimumFractionDigits: 0
-    }).format(amount);
-  };
-
   return (
       <div className="bg-white rounded-lg border border-gray-200 p-6 hover:shadow-md transition-shadow">
         <div className="flex justify-between items-start">
@@ -41,10 +33,10 @@ const ActivityCard = ({ activity, onEdit, onDelete }) => {
               <span>{activity.duration_mins} mins</span>
             </span>
 
-              {activity.cost > 0 && (
+              {activity.cost?.amount > 0 && (
                   <span className="flex items-center space-x-1 text-green-600">
                 <span>💰</span>
-                <span>{formatCurrency(activity.cost)}</span>
+                <span>{formatMoney(activity.cost)}</span>
               </span>
               )}
             </div>
@@ -3,8 +3,13 @@
 import React, { useState, useEffect } from 'react';
 import Button from '../UI/Button';
 import Input from '../UI/Input';
+import { useAuth } from '../../contexts/AuthContext';
+import { fromMinorUnits, toMinorUnits } from '../../utils/helpers';
 
 const ActivityForm = ({ activity = null, onSubmit, loading = false }) => {
+  const { user } = useAuth();
+  // Costs are entered in major units of the activity's currency, or the user's for new ones
+  const currency = activity?.cost?.currency || user?.currency || 'IDR';
   const [formData, setFormData] = useState({
     title: '',
     start_time: '',
@@ -24,7 +29,7 @@ const ActivityForm = ({ activity = null, onSubmit, loading = false }) => {
         title: activity.title || '',
         start_time: startTime,
         duration_mins: activity.duration_mins || '',
-        cost: activity.cost || '',
+        cost: fromMinorUnits(activity.cost),
         note: activity.note || ''
       });
     }
@@ -43,7 +48,8 @@ const ActivityForm = ({ activity = null, onSubmit, loading = false }) => {
     const submitData = {
       ...formData,
       duration_mins: parseInt(formData.duration_mins),
-      cost: formData.cost ? parseInt(formData.cost) : null
+      cost: toMinorUnits(formData.cost, currency),
+      currency
     };
 
     onSubmit(submitData);
@@ -81,13 +87,14 @@ const ActivityForm = ({ activity = null, onSubmit, loading = false }) => {
         />
 
         <Input
-            label="Cost (optional)"
+            label={`Cost in ${currency} (optional)`}
             type="number"
             name="cost"
             value={formData.cost}
             onChange={handleChange}
             placeholder="e.g., 50000"
             min="0"
+            step="any"
         />
 
         <div>
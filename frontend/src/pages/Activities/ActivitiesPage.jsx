@@ -1,6 +1,8 @@
 ﻿import React, { useState, useEffect } from 'react';
 import { activitiesService } from '../../services/activitiesService';
 import { useToast } from '../../contexts/ToastContext';
+import { useAuth } from '../../contexts/AuthContext';
+import { formatMoney, toMinorUnits } from '../../utils/helpers';
 import Button from '../../components/UI/Button';
 import Card from '../../components/UI/Card';
 import Modal from '../../components/UI/Modal';
@@ -18,6 +20,8 @@ const ActivitiesPage = () => {
     note: ''
   });
   const { showToast } = useToast();
+  const { user } = useAuth();
+  const currency = user?.currency || 'IDR';
 
   useEffect(() => {
     fetchActivities();
@@ -40,7 +44,8 @@ const ActivitiesPage = () => {
       await activitiesService.create({
         ...formData,
         duration_mins: parseInt(formData.duration_mins),
-        cost: formData.cost ? parseInt(formData.cost) : null
+        cost: toMinorUnits(formData.cost, currency),
+        currency
       });
       
       showToast('success', 'Activity created successfully!');
@@ -99,7 +104,7 @@ const ActivitiesPage = () => {
                 <div className="text-right">
                   {activity.cost && (
                     <div className="text-lg font-semibold text-green-600">
-                      Rp{activity.cost.toLocaleString()}
+                      {formatMoney(activity.cost)}
                     </div>
                   )}
                 </div>
@@ -142,11 +147,13 @@ const ActivitiesPage = () => {
           />
           
           <Input
-            label="Cost (optional)"
+            label={`Cost in ${currency} (optional)`}
             type="number"
             name="cost"
             value={formData.cost}
             onChange={handleChange}
+            min="0"
+            step="any"
           />
           
           <Input
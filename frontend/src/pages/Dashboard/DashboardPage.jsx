@@ -15,6 +15,7 @@ import {
   Wifi,
   WifiOff
 } from 'lucide-react';
+import { formatMoney } from '../../utils/helpers';
 
 const DashboardPage = () => {
   const [stats, setStats] = useState({
@@ -56,9 +57,9 @@ const DashboardPage = () => {
       });
 
       setRecentActivities([
-        { id: 1, title: 'Belajar React.js', duration: 120, cost: 0, date: '2025-07-16' },
-        { id: 2, title: 'Workout Session', duration: 60, cost: 15000, date: '2025-07-15' },
-        { id: 3, title: 'Reading Time', duration: 45, cost: 0, date: '2025-07-15' }
+        { id: 1, title: 'Belajar React.js', duration: 120, cost: null, date: '2025-07-16' },
+        { id: 2, title: 'Workout Session', duration: 60, cost: { amount: 1500000, currency: 'IDR' }, date: '2025-07-15' },
+        { id: 3, title: 'Reading Time', duration: 45, cost: null, date: '2025-07-15' }
       ]);
 
       setRecentHabits([
@@ -104,10 +105,10 @@ const DashboardPage = () => {
                 <Clock className="h-4 w-4" />
                 <span>{activity.duration}m</span>
               </div>
-              {activity.cost > 0 && (
+              {activity.cost?.amount > 0 && (
                   <div className="flex items-center space-x-1">
                     <DollarSign className="h-4 w-4" />
-                    <span>Rp{activity.cost.toLocaleString()}</span>
+                    <span>{formatMoney(activity.cost)}</span>
                   </div>
               )}
               <div className="flex items-center space-x-1">
@@ -63,7 +63,7 @@ export const apiRequest = async (endpoint, options = {}) => {
     // Handle non-JSON responses (like 404 HTML pages)
     let data;
     const contentType = response.headers.get('content-type');
-    if (contentType && contentType.includes('application/json')) {
+    if (contentType && (contentType.includes('application/json') || contentType.includes('application/problem+json'))) {
       data = await response.json();
     } else {
       // If not JSON, create error response
@@ -75,7 +75,8 @@ export const apiRequest = async (endpoint, options = {}) => {
     }
 
     if (!response.ok) {
-      throw new APIError(data.message || 'Request failed', response.status, data);
+      // Error responses are RFC 7807 problem documents (detail), older ones use message
+      throw new APIError(data.detail || data.message || 'Request failed', response.status, data);
     }
 
     return data;
@@ -25,6 +25,38 @@ export const formatCurrency = (amount, currency = 'IDR') => {
     }).format(amount);
 };
 
+// Costs travel as integer minor units (sen, cents) with an ISO 4217 code,
+// e.g. { amount: 1550, currency: 'USD' } is $15.50
+export const currencyDigits = (currency = 'IDR') => {
+    return new Intl.NumberFormat('id-ID', { style: 'currency', currency })
+        .resolvedOptions().maximumFractionDigits;
+};
+
+export const toMinorUnits = (value, currency = 'IDR') => {
+    if (value === '' || value === null || value === undefined) return null;
+
+    return Math.round(parseFloat(value) * 10 ** currencyDigits(currency));
+};
+
+export const fromMinorUnits = (money) => {
+    if (!money) return '';
+
+    return money.amount / 10 ** currencyDigits(money.currency);
+};
+
+export const formatMoney = (money) => {
+    if (!money) return '';
+
+    // Whole amounts drop the fraction, as formatCurrency does
+    const digits = money.amount % 10 ** currencyDigits(money.currency) === 0 ? 0 : currencyDigits(money.currency);
+    return new Intl.NumberFormat('id-ID', {
+        style: 'currency',
+        currency: money.currency,
+        minimumFractionDigits: digits,
+        maximumFractionDigits: digits
+    }).format(fromMinorUnits(money));
+};
+
 export const formatTime = (timeString) => {
     if (!timeString) return '';
 
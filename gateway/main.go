@@ -1,12 +1,20 @@
 package main
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"os"
 	"time"
 
 	"dailytrackr/gateway/proxy"
 	"dailytrackr/shared/config"
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/flags"
+	"dailytrackr/shared/health"
+	"dailytrackr/shared/lifecycle"
+	"dailytrackr/shared/logger"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/tracing"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -15,13 +23,29 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	log := logger.Init(cfg, "gateway")
+
+	shutdownTracing, err := tracing.Init(cfg, "gateway")
+	if err != nil {
+		log.Error("failed to initialise tracing", "error", err)
+		os.Exit(1)
+	}
+
+	runner := lifecycle.New(log, cfg.ShutdownTimeout)
+	runner.OnStop("tracing", shutdownTracing)
 
 	// Setup Gin router
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(ginmw.RequestID())
+	r.Use(ginmw.Tracing())
+	r.Use(ginmw.Language())
+	r.Use(ginmw.AccessLog(log))
+	r.Use(ginmw.Metrics())
 
 	// ================================================
 	// ENHANCED CORS MIDDLEWARE - Critical Fix
@@ -29,8 +53,8 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001", "http://127.0.0.1:3000", "*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "X-Requested-With", "X-Request-ID", "X-Device-Name"},
+		ExposeHeaders:    []string{"Content-Length", "Authorization", "X-Request-ID"},
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -55,91 +79,102 @@ func main() {
 		c.Next()
 	})
 
-	// Gateway health check
+	// Prometheus metrics endpoint
+	r.GET("/metrics", ginmw.MetricsHandler())
+
+	// Gateway health: an upstream that is down only degrades the gateway
+	upstreams := map[string]string{
+		constants.UserService:     "http://localhost:" + cfg.UserServicePort,
+		constants.ActivityService: "http://localhost:" + cfg.ActivityPort,
+		constants.HabitService:    "http://localhost:" + cfg.HabitPort,
+		constants.StatService:     "http://localhost:" + cfg.StatPort,
+		constants.AIService:       "http://localhost:" + cfg.AIPort,
+	}
+	checks := health.NewRegistry("dailytrackr-gateway", "1.0.0")
+	healthClient := &http.Client{Timeout: health.DefaultTimeout}
+	for name, url := range upstreams {
+		checks.Optional(name, health.Remote(healthClient, url+"/health"))
+	}
+	r.GET("/health", ginmw.Health(checks))
+
+	// Gateway overview
 	r.GET("/", func(c *gin.Context) {
-		services := map[string]interface{}{
-			"user-service": map[string]interface{}{
-				"url":    "http://localhost:" + cfg.UserServicePort,
-				"status": checkServiceHealth("http://localhost:" + cfg.UserServicePort + "/health"),
-			},
-			"activity-service": map[string]interface{}{
-				"url":    "http://localhost:" + cfg.ActivityPort,
-				"status": checkServiceHealth("http://localhost:" + cfg.ActivityPort + "/health"),
-			},
-			"habit-service": map[string]interface{}{
-				"url":    "http://localhost:" + cfg.HabitPort,
-				"status": checkServiceHealth("http://localhost:" + cfg.HabitPort + "/health"),
-			},
-			"stat-service": map[string]interface{}{
-				"url":    "http://localhost:" + cfg.StatPort,
-				"status": checkServiceHealth("http://localhost:" + cfg.StatPort + "/health"),
-			},
-			"ai-service": map[string]interface{}{
-				"url":    "http://localhost:" + cfg.AIPort,
-				"status": checkServiceHealth("http://localhost:" + cfg.AIPort + "/health"),
-			},
-		}
+		results := checks.Run(c.Request.Context())
 
-		// Count healthy services
+		services := map[string]interface{}{}
 		healthyCount := 0
-		totalCount := len(services)
-		for _, service := range services {
-			if serviceMap, ok := service.(map[string]interface{}); ok {
-				if status, exists := serviceMap["status"]; exists && status == "healthy" {
-					healthyCount++
-				}
+		for _, result := range results {
+			// Upstream failures degrade the gateway; report what the service itself said
+			status := result.Status
+			if status == health.StatusDegraded && result.Message != health.StatusDegraded {
+				status = health.StatusDown
+			}
+			if status == health.StatusHealthy {
+				healthyCount++
+			}
+			services[result.Name] = map[string]interface{}{
+				"url":    upstreams[result.Name],
+				"status": status,
 			}
 		}
 
 		c.JSON(http.StatusOK, gin.H{
 			"service":          "dailytrackr-gateway",
-			"status":           "healthy",
+			"status":           health.Worst(results),
 			"version":          "1.0.0",
 			"healthy_services": healthyCount,
-			"total_services":   totalCount,
+			"total_services":   len(results),
 			"services":         services,
 			"message":          "DailyTrackr Gateway is running successfully! 🚀",
 		})
 	})
 
+	// Feature flags; the gateway has no database, so it always reads the flags file
+	featureFlags := flags.NewStore(flags.FileSource(cfg.FlagsFile), cfg.Environment, log)
+	if err := featureFlags.Reload(context.Background()); err != nil {
+		log.Error("failed to load feature flags", "error", err)
+		os.Exit(1)
+	}
+	runner.Go("feature-flags", featureFlags.Run(cfg.FlagsReloadInterval))
+
 	// Setup service proxies with FIXED routing
-	setupRoutesFixed(r, cfg)
+	setupRoutesFixed(r, cfg, featureFlags)
 
 	// Start gateway
 	port := ":" + cfg.GatewayPort
-	log.Printf("🚀 DailyTrackr Gateway starting on port %s", port)
-	log.Printf("📋 Service endpoints:")
-	log.Printf("   - User Service:         http://localhost:%s", cfg.UserServicePort)
-	log.Printf("   - Activity Service:     http://localhost:%s", cfg.ActivityPort)
-	log.Printf("   - Habit Service:        http://localhost:%s", cfg.HabitPort)
-	log.Printf("   - Statistics Service:   http://localhost:%s", cfg.StatPort)
-	log.Printf("   - AI Service:           http://localhost:%s", cfg.AIPort)
-	log.Printf("🌐 Gateway URL: http://localhost:%s", cfg.GatewayPort)
-	log.Printf("🔗 API Routes:")
-	log.Printf("   - /api/users/*          -> User Service")
-	log.Printf("   - /api/activities/*     -> Activity Service")
-	log.Printf("   - /api/habits/*         -> Habit Service")
-	log.Printf("   - /api/stats/*          -> Statistics Service")
-	log.Printf("   - /api/ai/*             -> AI Service")
-
-	log.Fatal(r.Run(port))
+	log.Info("gateway starting",
+		"port", cfg.GatewayPort,
+		"user_service", "http://localhost:"+cfg.UserServicePort,
+		"activity_service", "http://localhost:"+cfg.ActivityPort,
+		"habit_service", "http://localhost:"+cfg.HabitPort,
+		"stat_service", "http://localhost:"+cfg.StatPort,
+		"ai_service", "http://localhost:"+cfg.AIPort,
+	)
+	runner.AddServer("http", ginmw.Server(r, port))
+	if err := runner.Run(); err != nil {
+		log.Error("gateway stopped", "error", err)
+		os.Exit(1)
+	}
 }
 
 // setupRoutesFixed configures all microservice routes with proper mapping
-func setupRoutesFixed(r *gin.Engine, cfg *config.Config) {
+func setupRoutesFixed(r *gin.Engine, cfg *config.Config, featureFlags *flags.Store) {
 	// User Service routes
-	userProxy := proxy.NewServiceProxy("http://localhost:" + cfg.UserServicePort)
+	userProxy := proxy.NewServiceProxy(constants.UserService, "http://localhost:"+cfg.UserServicePort)
 	userRoutes := r.Group("/api/users")
 	{
 		userRoutes.Any("/health", userProxy.ProxyRequest)
 		userRoutes.Any("/auth/*path", userProxy.ProxyRequest)
 		userRoutes.Any("/api/v1/users/*path", userProxy.ProxyRequest)
+		userRoutes.Any("/api/v1/admin/*path", userProxy.ProxyRequest)
 		// FIXED: Direct auth routes without /api/users prefix
 		r.Any("/auth/*path", userProxy.ProxyRequest)
+		// Data export download links point here
+		r.GET("/exports/*path", userProxy.ProxyRequest)
 	}
 
 	// Activity Service routes
-	activityProxy := proxy.NewServiceProxy("http://localhost:" + cfg.ActivityPort)
+	activityProxy := proxy.NewServiceProxy(constants.ActivityService, "http://localhost:"+cfg.ActivityPort)
 	activityRoutes := r.Group("/api/activities")
 	{
 		activityRoutes.Any("/health", activityProxy.ProxyRequest)
@@ -148,7 +183,7 @@ func setupRoutesFixed(r *gin.Engine, cfg *config.Config) {
 	}
 
 	// Habit Service routes - ENHANCED ROUTING
-	habitProxy := proxy.NewServiceProxy("http://localhost:" + cfg.HabitPort)
+	habitProxy := proxy.NewServiceProxy(constants.HabitService, "http://localhost:"+cfg.HabitPort)
 	habitRoutes := r.Group("/api/habits")
 	{
 		habitRoutes.Any("/health", habitProxy.ProxyRequest)
@@ -158,7 +193,7 @@ func setupRoutesFixed(r *gin.Engine, cfg *config.Config) {
 	}
 
 	// Statistics Service routes
-	statProxy := proxy.NewServiceProxy("http://localhost:" + cfg.StatPort)
+	statProxy := proxy.NewServiceProxy(constants.StatService, "http://localhost:"+cfg.StatPort)
 	statRoutes := r.Group("/api/stats")
 	{
 		statRoutes.Any("/health", statProxy.ProxyRequest)
@@ -167,7 +202,7 @@ func setupRoutesFixed(r *gin.Engine, cfg *config.Config) {
 	}
 
 	// AI Service routes
-	aiProxy := proxy.NewServiceProxy("http://localhost:" + cfg.AIPort)
+	aiProxy := proxy.NewServiceProxy(constants.AIService, "http://localhost:"+cfg.AIPort)
 	aiRoutes := r.Group("/api/ai")
 	{
 		aiRoutes.Any("/health", aiProxy.ProxyRequest)
@@ -175,9 +210,10 @@ func setupRoutesFixed(r *gin.Engine, cfg *config.Config) {
 		aiRoutes.Any("/api/v1/ai/*path", aiProxy.ProxyRequest) // With path
 	}
 
-	// Notification Service routes (for future implementation)
-	notificationProxy := proxy.NewServiceProxy("http://localhost:" + cfg.NotificationPort)
+	// Notification Service routes (for future implementation), hidden until the notifications flag is on
+	notificationProxy := proxy.NewServiceProxy(constants.NotificationService, "http://localhost:"+cfg.NotificationPort)
 	notificationRoutes := r.Group("/api/notifications")
+	notificationRoutes.Use(ginmw.RequireFlag(featureFlags, flags.Notifications))
 	{
 		notificationRoutes.Any("/health", notificationProxy.ProxyRequest)
 		notificationRoutes.Any("/api/v1/notifications", notificationProxy.ProxyRequest)       // Exact match
@@ -201,22 +237,3 @@ func setupRoutesFixed(r *gin.Engine, cfg *config.Config) {
 		})
 	})
 }
-
-// checkServiceHealth checks if a service is healthy
-func checkServiceHealth(url string) string {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-
-	resp, err := client.Get(url)
-	if err != nil {
-		return "down"
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusOK {
-		return "healthy"
-	}
-
-	return "unhealthy"
-}
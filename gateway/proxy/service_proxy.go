@@ -2,27 +2,42 @@ package proxy
 
 import (
 	"bytes"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/metrics"
+	"dailytrackr/shared/tracing"
+	"dailytrackr/shared/utils"
+
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // ServiceProxy handles proxying requests to microservices
 type ServiceProxy struct {
+	name      string
 	targetURL string
 	client    *http.Client
 }
 
-// NewServiceProxy creates a new service proxy instance
-func NewServiceProxy(targetURL string) *ServiceProxy {
+// NewServiceProxy creates a new service proxy instance; name labels upstream metrics
+func NewServiceProxy(name, targetURL string) *ServiceProxy {
 	return &ServiceProxy{
+		name:      name,
 		targetURL: targetURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
+			// Hand redirects (e.g. to an OIDC provider) back to the client instead of following them
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
 		},
 	}
 }
@@ -38,7 +53,7 @@ func (sp *ServiceProxy) ProxyRequest(c *gin.Context) {
 		targetURL += "?" + c.Request.URL.RawQuery
 	}
 
-	log.Printf("🔄 Proxying: %s %s -> %s", c.Request.Method, c.Request.URL.Path, targetURL)
+	slog.Debug("proxying request", "method", c.Request.Method, "path", c.Request.URL.Path, "target", targetURL)
 
 	// Read request body
 	var bodyBytes []byte
@@ -47,15 +62,18 @@ func (sp *ServiceProxy) ProxyRequest(c *gin.Context) {
 		c.Request.Body.Close()
 	}
 
+	// Client span for the upstream call; its context travels in the traceparent header
+	ctx, span := tracing.StartClient(c.Request.Context(), "proxy "+sp.name,
+		attribute.String("peer.service", sp.name),
+		attribute.String("http.request.method", c.Request.Method),
+		attribute.String("url.full", sp.targetURL+targetPath),
+	)
+	defer span.End()
+
 	// Create new request
-	req, err := http.NewRequest(c.Request.Method, targetURL, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, c.Request.Method, targetURL, bytes.NewReader(bodyBytes))
 	if err != nil {
-		log.Printf("❌ Failed to create request: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to create proxy request",
-			"error":   err.Error(),
-		})
+		utils.SendError(c.Writer, c.Request, apperrors.Internal("Failed to create proxy request", err))
 		return
 	}
 
@@ -66,17 +84,16 @@ func (sp *ServiceProxy) ProxyRequest(c *gin.Context) {
 	req.Header.Set("X-Forwarded-For", c.ClientIP())
 	req.Header.Set("X-Forwarded-Proto", "http")
 	req.Header.Set("X-Real-IP", c.ClientIP())
+	tracing.Inject(ctx, req.Header)
 
 	// Execute request
+	start := time.Now()
 	resp, err := sp.client.Do(req)
 	if err != nil {
-		log.Printf("❌ Proxy request failed: %v", err)
-		c.JSON(http.StatusBadGateway, gin.H{
-			"success": false,
-			"message": "Service temporarily unavailable",
-			"error":   "Failed to connect to backend service",
-			"service": sp.targetURL,
-		})
+		metrics.ObserveUpstream(sp.name, 0, time.Since(start))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		utils.SendError(c.Writer, c.Request, apperrors.UpstreamUnavailable("Service temporarily unavailable", fmt.Errorf("%s: %v", sp.targetURL, err)))
 		return
 	}
 	defer resp.Body.Close()
@@ -86,13 +103,13 @@ func (sp *ServiceProxy) ProxyRequest(c *gin.Context) {
 
 	// Copy response body
 	respBody, err := io.ReadAll(resp.Body)
+	metrics.ObserveUpstream(sp.name, resp.StatusCode, time.Since(start))
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
 	if err != nil {
-		log.Printf("❌ Failed to read response body: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to read service response",
-			"error":   err.Error(),
-		})
+		utils.SendError(c.Writer, c.Request, apperrors.UpstreamUnavailable("Failed to read service response", err))
 		return
 	}
 
@@ -100,8 +117,11 @@ func (sp *ServiceProxy) ProxyRequest(c *gin.Context) {
 	c.Status(resp.StatusCode)
 	c.Writer.Write(respBody)
 
-	log.Printf("✅ Proxy success: %s %s -> %d (%d bytes)",
-		c.Request.Method, c.Request.URL.Path, resp.StatusCode, len(respBody))
+	slog.Debug("proxy response",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"status", resp.StatusCode,
+		"bytes", len(respBody))
 }
 
 // buildTargetPathEnhanced builds the target path with enhanced mapping logic
@@ -120,8 +140,8 @@ func (sp *ServiceProxy) buildTargetPathEnhanced(originalPath string) string {
 	case strings.HasPrefix(targetPath, "/api/users/"):
 		// /api/users/health -> /health
 		targetPath = strings.Replace(targetPath, "/api/users", "", 1)
-	case strings.HasPrefix(targetPath, "/auth/"):
-		// Direct auth routes stay as is
+	case strings.HasPrefix(targetPath, "/auth/"), strings.HasPrefix(targetPath, "/exports/"):
+		// Direct auth and export download routes stay as is
 		// /auth/login -> /auth/login
 
 	// Activity service paths
@@ -209,6 +229,8 @@ func (sp *ServiceProxy) copyResponseHeaders(src http.Header, dst http.Header) {
 		"Transfer-Encoding": true,
 		"Upgrade":           true,
 		"Server":            true, // Let Gin set the server header
+		"X-Request-Id":      true, // Already set by the gateway request ID middleware
+		"Content-Language":  true, // Replaced below, not appended to the gateway's own
 	}
 
 	for key, values := range src {
@@ -220,6 +242,11 @@ func (sp *ServiceProxy) copyResponseHeaders(src http.Header, dst http.Header) {
 		}
 	}
 
+	// Upstream services know the user's saved language, so their choice wins
+	if lang := src.Get(constants.ContentLanguageHeader); lang != "" {
+		dst.Set(constants.ContentLanguageHeader, lang)
+	}
+
 	// Ensure CORS headers are preserved
 	dst.Set("Access-Control-Allow-Origin", "*")
 	dst.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
@@ -0,0 +1,44 @@
+package consumers
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"dailytrackr/habit-service/models"
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/events"
+)
+
+// ConsumerName identifies the habit service's offset on the event stream
+const ConsumerName = "habit-service"
+
+// UserEvents keeps habits in step with changes made by the user service
+type UserEvents struct {
+	db        *sql.DB
+	habitRepo *models.HabitRepository
+}
+
+// NewUserEvents creates a new user events consumer
+func NewUserEvents(db *sql.DB) *UserEvents {
+	return &UserEvents{db: db, habitRepo: models.NewHabitRepository(db)}
+}
+
+// Handler routes the events this service consumes
+func (h *UserEvents) Handler() events.Handler {
+	return events.Route(map[string]events.Handler{
+		events.UserDeleted: h.userDeleted,
+	})
+}
+
+// userDeleted removes a deleted user's habits and logs, then confirms the
+// purge; redelivery finds nothing left and confirms again
+func (h *UserEvents) userDeleted(ctx context.Context, event events.Event) error {
+	deleted, err := h.habitRepo.DeleteByUserID(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("habits removed for deleted user", "user_id", event.UserID, "rows", deleted)
+	return events.RecordPurged(ctx, h.db, event.UserID, constants.HabitService, deleted)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -10,6 +11,10 @@ import (
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
 	"dailytrackr/shared/dto"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/i18n"
+	"dailytrackr/shared/middleware/echomw"
+	"dailytrackr/shared/utils"
 
 	"github.com/labstack/echo/v4"
 )
@@ -33,46 +38,27 @@ func NewHabitHandlers(db *sql.DB, cfg *config.Config) *HabitHandlers {
 func (h *HabitHandlers) CreateHabit(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	var req dto.CreateHabitRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid request body"))
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return sendError(c, utils.ValidationError(err))
 	}
 
 	// Parse dates
 	startDate, err := time.Parse(constants.DateFormat, req.StartDate)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid start_date format. Use: 2006-01-02",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid start_date format. Use: 2006-01-02"))
 	}
 
 	endDate, err := time.Parse(constants.DateFormat, req.EndDate)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid end_date format. Use: 2006-01-02",
-			"error":   err.Error(),
-		})
-	}
-
-	// Validate date range
-	if endDate.Before(startDate) {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "End date must be after start date",
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid end_date format. Use: 2006-01-02"))
 	}
 
 	// Create habit
@@ -84,19 +70,15 @@ func (h *HabitHandlers) CreateHabit(c echo.Context) error {
 		ReminderTime: req.ReminderTime,
 	}
 
-	if err := h.habitRepo.Create(habit); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to create habit",
-			"error":   err.Error(),
-		})
+	if err := h.habitRepo.Create(c.Request().Context(), habit); err != nil {
+		return sendError(c, apperrors.Internal("Failed to create habit", err))
 	}
 
 	response := convertToHabitResponse(habit)
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"success": true,
-		"message": constants.MsgHabitCreated,
+		"message": i18n.T(echomw.Lang(c), constants.MsgHabitCreated),
 		"data":    response,
 	})
 }
@@ -105,10 +87,7 @@ func (h *HabitHandlers) CreateHabit(c echo.Context) error {
 func (h *HabitHandlers) GetHabits(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	// Check if only active habits are requested
@@ -118,17 +97,13 @@ func (h *HabitHandlers) GetHabits(c echo.Context) error {
 	var err error
 
 	if activeOnly {
-		habits, err = h.habitRepo.GetActiveHabits(userID.(int64))
+		habits, err = h.habitRepo.GetActiveHabits(c.Request().Context(), userID.(int64), echomw.Location(c))
 	} else {
-		habits, err = h.habitRepo.GetByUserID(userID.(int64))
+		habits, err = h.habitRepo.GetByUserID(c.Request().Context(), userID.(int64))
 	}
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get habits",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get habits", err))
 	}
 
 	var responses []dto.HabitResponse
@@ -138,7 +113,7 @@ func (h *HabitHandlers) GetHabits(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": "Habits retrieved successfully",
+		"message": i18n.T(echomw.Lang(c), constants.MsgHabitsRetrieved),
 		"data":    responses,
 		"total":   len(responses),
 	})
@@ -148,41 +123,28 @@ func (h *HabitHandlers) GetHabits(c echo.Context) error {
 func (h *HabitHandlers) GetHabit(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	habitID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid habit ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid habit ID"))
 	}
 
 	var habit models.Habit
-	err = h.habitRepo.GetByID(habitID, userID.(int64), &habit)
+	err = h.habitRepo.GetByID(c.Request().Context(), habitID, userID.(int64), &habit)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"success": false,
-				"message": constants.ErrHabitNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrHabitNotFound))
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get habit",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get habit", err))
 	}
 
 	response := convertToHabitResponse(&habit)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": "Habit retrieved successfully",
+		"message": i18n.T(echomw.Lang(c), constants.MsgHabitRetrieved),
 		"data":    response,
 	})
 }
@@ -191,44 +153,31 @@ func (h *HabitHandlers) GetHabit(c echo.Context) error {
 func (h *HabitHandlers) UpdateHabit(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	habitID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid habit ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid habit ID"))
 	}
 
 	var req dto.UpdateHabitRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid request body"))
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return sendError(c, utils.ValidationError(err))
 	}
 
 	// Get existing habit
 	var habit models.Habit
-	err = h.habitRepo.GetByID(habitID, userID.(int64), &habit)
+	err = h.habitRepo.GetByID(c.Request().Context(), habitID, userID.(int64), &habit)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"success": false,
-				"message": constants.ErrHabitNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrHabitNotFound))
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get habit",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get habit", err))
 	}
 
 	// Update fields if provided
@@ -239,29 +188,21 @@ func (h *HabitHandlers) UpdateHabit(c echo.Context) error {
 		habit.ReminderTime = req.ReminderTime
 	}
 
-	if err := h.habitRepo.Update(&habit); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to update habit",
-			"error":   err.Error(),
-		})
+	if err := h.habitRepo.Update(c.Request().Context(), &habit); err != nil {
+		return sendError(c, apperrors.Internal("Failed to update habit", err))
 	}
 
 	// Get updated habit
-	err = h.habitRepo.GetByID(habitID, userID.(int64), &habit)
+	err = h.habitRepo.GetByID(c.Request().Context(), habitID, userID.(int64), &habit)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get updated habit",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get updated habit", err))
 	}
 
 	response := convertToHabitResponse(&habit)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": constants.MsgHabitUpdated,
+		"message": i18n.T(echomw.Lang(c), constants.MsgHabitUpdated),
 		"data":    response,
 	})
 }
@@ -270,38 +211,25 @@ func (h *HabitHandlers) UpdateHabit(c echo.Context) error {
 func (h *HabitHandlers) DeleteHabit(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	habitID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid habit ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid habit ID"))
 	}
 
-	err = h.habitRepo.Delete(habitID, userID.(int64))
+	err = h.habitRepo.Delete(c.Request().Context(), habitID, userID.(int64))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"success": false,
-				"message": constants.ErrHabitNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrHabitNotFound))
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to delete habit",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to delete habit", err))
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": constants.MsgHabitDeleted,
+		"message": i18n.T(echomw.Lang(c), constants.MsgHabitDeleted),
 	})
 }
 
@@ -309,54 +237,45 @@ func (h *HabitHandlers) DeleteHabit(c echo.Context) error {
 func (h *HabitHandlers) CreateHabitLog(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	habitID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid habit ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid habit ID"))
 	}
 
 	var req dto.CreateHabitLogRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid request body"))
+	}
+
+	// The habit comes from the path, not the body
+	req.HabitID = habitID
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return sendError(c, utils.ValidationError(err))
 	}
 
 	// Verify habit belongs to user
 	var habit models.Habit
-	err = h.habitRepo.GetByID(habitID, userID.(int64), &habit)
+	err = h.habitRepo.GetByID(c.Request().Context(), habitID, userID.(int64), &habit)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"success": false,
-				"message": constants.ErrHabitNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrHabitNotFound))
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to verify habit",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to verify habit", err))
 	}
 
-	// Parse date
+	// Parse date; log dates are calendar days already in the user's time zone
 	date, err := time.Parse(constants.DateFormat, req.Date)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid date format. Use: 2006-01-02",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid date format. Use: 2006-01-02"))
+	}
+
+	// The log must fall within the habit's date range
+	if err := utils.ValidateDateWithinRange("date", date, habit.StartDate, habit.EndDate); err != nil {
+		return sendError(c, err)
 	}
 
 	// Create habit log
@@ -367,77 +286,75 @@ func (h *HabitHandlers) CreateHabitLog(c echo.Context) error {
 		Note:    req.Note,
 	}
 
-	if err := h.habitLogRepo.Create(log); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to create habit log",
-			"error":   err.Error(),
-		})
+	if err := h.habitLogRepo.Create(c.Request().Context(), log); err != nil {
+		return sendError(c, apperrors.Internal("Failed to create habit log", err))
 	}
 
 	response := convertToHabitLogResponse(log)
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"success": true,
-		"message": constants.MsgHabitLogCreated,
+		"message": i18n.T(echomw.Lang(c), constants.MsgHabitLogCreated),
 		"data":    response,
 	})
 }
 
-// GetHabitLogs handles getting habit logs
+// GetHabitLogs handles getting habit logs with cursor or page pagination
 func (h *HabitHandlers) GetHabitLogs(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	habitID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid habit ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid habit ID"))
+	}
+
+	params, err := utils.ParsePageParams(c.QueryParam("cursor"), c.QueryParam("page"), c.QueryParam("limit"))
+	if err != nil {
+		return sendError(c, apperrors.Validation(constants.ErrInvalidCursor))
 	}
 
 	// Verify habit belongs to user
 	var habit models.Habit
-	err = h.habitRepo.GetByID(habitID, userID.(int64), &habit)
+	err = h.habitRepo.GetByID(c.Request().Context(), habitID, userID.(int64), &habit)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"success": false,
-				"message": constants.ErrHabitNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrHabitNotFound))
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to verify habit",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to verify habit", err))
 	}
 
-	logs, err := h.habitLogRepo.GetByHabitID(habitID)
+	logs, more, err := h.habitLogRepo.ListByHabitID(c.Request().Context(), habitID, params.Cursor, params.Offset(), params.Limit)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get habit logs",
-			"error":   err.Error(),
-		})
+		if errors.Is(err, utils.ErrInvalidCursor) {
+			return sendError(c, apperrors.Validation(constants.ErrInvalidCursor))
+		}
+		return sendError(c, apperrors.Internal("Failed to get habit logs", err))
+	}
+
+	total, err := h.habitLogRepo.CountByHabitID(c.Request().Context(), habitID)
+	if err != nil {
+		return sendError(c, apperrors.Internal("Failed to count habit logs", err))
 	}
 
-	var responses []dto.HabitLogResponse
+	responses := []dto.HabitLogResponse{}
 	for _, log := range logs {
 		responses = append(responses, convertToHabitLogResponse(&log))
 	}
 
+	var first, last *utils.Cursor
+	if len(logs) > 0 {
+		first, last = logs[0].Cursor(), logs[len(logs)-1].Cursor()
+	}
+
 	return c.JSON(http.StatusOK, map[string]interface{}{
-		"success": true,
-		"message": "Habit logs retrieved successfully",
-		"data":    responses,
-		"total":   len(responses),
+		"success":    true,
+		"message":    i18n.T(echomw.Lang(c), constants.MsgHabitLogsRetrieved),
+		"data":       responses,
+		"total":      total,
+		"pagination": utils.NewPaginationResponse(params, total, more, first, last),
 	})
 }
 
@@ -445,43 +362,30 @@ func (h *HabitHandlers) GetHabitLogs(c echo.Context) error {
 func (h *HabitHandlers) UpdateHabitLog(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	logID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid log ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid log ID"))
 	}
 
 	var req dto.UpdateHabitLogRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid request body",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Wrap(err, apperrors.CodeValidation, "Invalid request body"))
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return sendError(c, utils.ValidationError(err))
 	}
 
 	// Get existing log and verify ownership
-	log, err := h.habitLogRepo.GetLogByIDWithOwnership(logID, userID.(int64))
+	log, err := h.habitLogRepo.GetLogByIDWithOwnership(c.Request().Context(), logID, userID.(int64))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"success": false,
-				"message": constants.ErrHabitLogNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrHabitLogNotFound))
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get habit log",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get habit log", err))
 	}
 
 	// Update fields if provided
@@ -492,19 +396,15 @@ func (h *HabitHandlers) UpdateHabitLog(c echo.Context) error {
 		log.Note = req.Note
 	}
 
-	if err := h.habitLogRepo.Update(log); err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to update habit log",
-			"error":   err.Error(),
-		})
+	if err := h.habitLogRepo.Update(c.Request().Context(), log); err != nil {
+		return sendError(c, apperrors.Internal("Failed to update habit log", err))
 	}
 
 	response := convertToHabitLogResponse(log)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": constants.MsgHabitLogUpdated,
+		"message": i18n.T(echomw.Lang(c), constants.MsgHabitLogUpdated),
 		"data":    response,
 	})
 }
@@ -513,49 +413,32 @@ func (h *HabitHandlers) UpdateHabitLog(c echo.Context) error {
 func (h *HabitHandlers) GetHabitStats(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	habitID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid habit ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid habit ID"))
 	}
 
 	// Verify habit belongs to user
 	var habit models.Habit
-	err = h.habitRepo.GetByID(habitID, userID.(int64), &habit)
+	err = h.habitRepo.GetByID(c.Request().Context(), habitID, userID.(int64), &habit)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"success": false,
-				"message": constants.ErrHabitNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrHabitNotFound))
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to verify habit",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to verify habit", err))
 	}
 
-	stats, err := h.habitLogRepo.GetStats(habitID)
+	stats, err := h.habitLogRepo.GetStats(c.Request().Context(), habitID, echomw.Location(c))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get habit statistics",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get habit statistics", err))
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": "Habit statistics retrieved successfully",
+		"message": i18n.T(echomw.Lang(c), constants.MsgHabitStatsRetrieved),
 		"data":    stats,
 	})
 }
@@ -564,55 +447,34 @@ func (h *HabitHandlers) GetHabitStats(c echo.Context) error {
 func (h *HabitHandlers) GetHabitWithLogs(c echo.Context) error {
 	userID := c.Get("user_id")
 	if userID == nil {
-		return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-			"success": false,
-			"message": constants.ErrInvalidToken,
-		})
+		return sendError(c, apperrors.Unauthorized(constants.ErrInvalidToken))
 	}
 
 	habitID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]interface{}{
-			"success": false,
-			"message": "Invalid habit ID",
-		})
+		return sendError(c, apperrors.Validation("Invalid habit ID"))
 	}
 
 	// Get habit
 	var habit models.Habit
-	err = h.habitRepo.GetByID(habitID, userID.(int64), &habit)
+	err = h.habitRepo.GetByID(c.Request().Context(), habitID, userID.(int64), &habit)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return c.JSON(http.StatusNotFound, map[string]interface{}{
-				"success": false,
-				"message": constants.ErrHabitNotFound,
-			})
+			return sendError(c, apperrors.NotFound(constants.ErrHabitNotFound))
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get habit",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get habit", err))
 	}
 
 	// Get logs
-	logs, err := h.habitLogRepo.GetByHabitID(habitID)
+	logs, err := h.habitLogRepo.GetByHabitID(c.Request().Context(), habitID)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get habit logs",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get habit logs", err))
 	}
 
 	// Get stats
-	stats, err := h.habitLogRepo.GetStats(habitID)
+	stats, err := h.habitLogRepo.GetStats(c.Request().Context(), habitID, echomw.Location(c))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"success": false,
-			"message": "Failed to get habit statistics",
-			"error":   err.Error(),
-		})
+		return sendError(c, apperrors.Internal("Failed to get habit statistics", err))
 	}
 
 	// Convert to response DTOs
@@ -638,7 +500,7 @@ func (h *HabitHandlers) GetHabitWithLogs(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": "Habit with logs and stats retrieved successfully",
+		"message": i18n.T(echomw.Lang(c), constants.MsgHabitDetailsRetrieved),
 		"data":    response,
 	})
 }
@@ -669,3 +531,9 @@ func convertToHabitLogResponse(log *models.HabitLog) dto.HabitLogResponse {
 		UpdatedAt: log.UpdatedAt,
 	}
 }
+
+// sendError writes err as an RFC 7807 problem response
+func sendError(c echo.Context, err error) error {
+	apperrors.WriteProblem(c.Response(), c.Request().URL.Path, err)
+	return nil
+}
@@ -1,14 +1,25 @@
 package main
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"os"
 	"time"
 
+	"dailytrackr/habit-service/consumers"
 	"dailytrackr/habit-service/handlers"
 	"dailytrackr/habit-service/routes"
 	"dailytrackr/shared/config"
+	"dailytrackr/shared/constants"
 	"dailytrackr/shared/database"
+	"dailytrackr/shared/events"
+	"dailytrackr/shared/health"
+	"dailytrackr/shared/lifecycle"
+	"dailytrackr/shared/logger"
+	"dailytrackr/shared/metrics"
+	"dailytrackr/shared/middleware/echomw"
+	"dailytrackr/shared/sessions"
+	"dailytrackr/shared/tracing"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -17,19 +28,53 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	log := logger.Init(cfg, "habit-service")
+
+	shutdownTracing, err := tracing.Init(cfg, "habit-service")
+	if err != nil {
+		log.Error("failed to initialise tracing", "error", err)
+		os.Exit(1)
+	}
+
+	runner := lifecycle.New(log, cfg.ShutdownTimeout)
+	runner.OnStop("tracing", shutdownTracing)
 
 	// Initialize database connection
 	db, err := database.GetMySQLConnection(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	runner.OnStop("database", lifecycle.Closer(db.Close))
+	metrics.RegisterDBStats(metrics.PoolStats(db))
+
+	// Domain events: relay the outbox and follow other services' changes
+	if err := database.Migrate(context.Background(), db, cfg, events.Migrations); err != nil {
+		log.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
+	transport, err := events.NewTransport(cfg)
+	if err != nil {
+		log.Error("failed to connect event transport", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
+	runner.OnStop("events", lifecycle.Closer(transport.Close))
+	relay := events.NewRelay(db, constants.HabitService, transport, log, cfg.EventRelayInterval, cfg.EventRelayBatchSize)
+	runner.Go("outbox-relay", relay.Run)
+	runner.Go("user-events", events.Consume(transport, consumers.ConsumerName, consumers.NewUserEvents(db).Handler(), log))
 
 	// Initialize Echo
 	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
 
 	// Middleware
-	e.Use(middleware.Logger())
+	e.Use(echomw.RequestID())
+	e.Use(echomw.Tracing())
+	e.Use(echomw.Language())
+	e.Use(echomw.AccessLog(log))
+	e.Use(echomw.Metrics())
 	e.Use(middleware.Recover())
 
 	// ================================================
@@ -62,36 +107,31 @@ func main() {
 		}
 	})
 
+	// Prometheus metrics endpoint
+	e.GET("/metrics", echomw.MetricsHandler())
+
 	// Health check endpoint
-	e.GET("/health", func(c echo.Context) error {
-		return c.JSON(200, map[string]interface{}{
-			"service": "habit-service",
-			"status":  "healthy",
-			"version": "1.0.0",
-			"cors":    "enabled",
-		})
-	})
+	checks := health.NewRegistry("habit-service", "1.0.0").
+		Critical("database", health.Ping(db)).
+		Optional("event_outbox", relay.Lag())
+	e.GET("/health", echomw.Health(checks))
 
 	// Initialize handlers
 	habitHandlers := handlers.NewHabitHandlers(db, cfg)
 
 	// Setup routes
-	routes.SetupHabitRoutes(e, habitHandlers)
+	routes.SetupHabitRoutes(e, habitHandlers, sessions.NewChecker(db))
 
 	// Start server
 	port := ":" + cfg.HabitPort
-	log.Printf("🚀 Habit Service starting on port %s", port)
-	log.Printf("📋 Available endpoints:")
-	log.Printf("   - GET  /health")
-	log.Printf("   - POST /api/v1/habits")
-	log.Printf("   - GET  /api/v1/habits")
-	log.Printf("   - GET  /api/v1/habits/:id")
-	log.Printf("   - PUT  /api/v1/habits/:id")
-	log.Printf("   - DELETE /api/v1/habits/:id")
-	log.Printf("   - POST /api/v1/habits/:id/logs")
-	log.Printf("   - GET  /api/v1/habits/:id/logs")
-	log.Printf("   - PUT  /api/v1/habit-logs/:id")
-	log.Printf("   - GET  /api/v1/habits/:id/stats")
-
-	log.Fatal(e.Start(port))
+	for _, route := range e.Routes() {
+		log.Debug("route registered", "method", route.Method, "path", route.Path)
+	}
+
+	log.Info("habit service starting", "port", cfg.HabitPort)
+	runner.AddServer("http", echomw.Server(e, port))
+	if err := runner.Run(); err != nil {
+		log.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
 }
@@ -1,62 +1,82 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/middleware/echomw"
+	"dailytrackr/shared/sessions"
 	"dailytrackr/shared/utils"
 
 	"github.com/labstack/echo/v4"
 )
 
 // AuthMiddleware validates JWT tokens for Echo
-func AuthMiddleware() echo.MiddlewareFunc {
+func AuthMiddleware(checker *sessions.Checker) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			// Get authorization header
 			authHeader := c.Request().Header.Get(constants.AuthorizationHeader)
 			if authHeader == "" {
-				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-					"success": false,
-					"message": constants.ErrMissingToken,
-				})
+				return unauthorized(c, constants.ErrMissingToken)
 			}
 
 			// Check if header has Bearer prefix
 			if !strings.HasPrefix(authHeader, constants.BearerPrefix) {
-				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-					"success": false,
-					"message": constants.ErrInvalidToken,
-				})
+				return unauthorized(c, constants.ErrInvalidToken)
 			}
 
 			// Extract token
 			token := strings.TrimPrefix(authHeader, constants.BearerPrefix)
 			if token == "" {
-				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-					"success": false,
-					"message": constants.ErrInvalidToken,
-				})
+				return unauthorized(c, constants.ErrInvalidToken)
 			}
 
 			// Load config and validate token
 			cfg := config.LoadConfig()
 			claims, err := utils.ValidateJWT(token, cfg.JWTSecret)
 			if err != nil {
-				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
-					"success": false,
-					"message": constants.ErrInvalidToken,
-				})
+				return unauthorized(c, constants.ErrInvalidToken)
+			}
+
+			// Reject tokens signed out since they were issued, e.g. by a password reset
+			active, err := checker.Active(c.Request().Context(), claims)
+			if err != nil {
+				apperrors.WriteProblem(c.Response(), c.Request().URL.Path, apperrors.Internal("failed to check session", err))
+				return nil
+			}
+			if !active {
+				return unauthorized(c, constants.ErrInvalidToken)
+			}
+
+			// Unverified accounts may only do what UNVERIFIED_POLICY allows
+			if !claims.Allows(cfg.UnverifiedPolicy, c.Request().Method) {
+				return forbidden(c, constants.ErrEmailNotVerified)
 			}
 
 			// Set user information in context
 			c.Set("user_id", claims.UserID)
 			c.Set("username", claims.Username)
 			c.Set("email", claims.Email)
+			echomw.SetLanguagePreference(c, claims.Language)
+			echomw.SetTimezone(c, claims.Timezone)
+			echomw.SetPermissions(c, claims.Permissions)
 
 			return next(c)
 		}
 	}
 }
+
+// unauthorized writes a 401 problem response
+func unauthorized(c echo.Context, message string) error {
+	apperrors.WriteProblem(c.Response(), c.Request().URL.Path, apperrors.Unauthorized(message))
+	return nil
+}
+
+// forbidden writes a 403 problem response
+func forbidden(c echo.Context, message string) error {
+	apperrors.WriteProblem(c.Response(), c.Request().URL.Path, apperrors.Forbidden(message))
+	return nil
+}
@@ -1,8 +1,15 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/events"
+	"dailytrackr/shared/tracing"
+	"dailytrackr/shared/utils"
 )
 
 // Habit represents the habit model
@@ -40,34 +47,47 @@ func NewHabitRepository(db *sql.DB) *HabitRepository {
 }
 
 // Create creates a new habit in the database
-func (r *HabitRepository) Create(habit *Habit) error {
+func (r *HabitRepository) Create(ctx context.Context, habit *Habit) error {
+	ctx, span := tracing.Start(ctx, "HabitRepository.Create")
+	defer span.End()
+
 	query := `
 		INSERT INTO habits (user_id, title, start_date, end_date, reminder_time) 
 		VALUES (?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query,
-		habit.UserID,
-		habit.Title,
-		habit.StartDate,
-		habit.EndDate,
-		habit.ReminderTime,
-	)
-	if err != nil {
-		return err
-	}
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query,
+			habit.UserID,
+			habit.Title,
+			habit.StartDate,
+			habit.EndDate,
+			habit.ReminderTime,
+		)
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
 
-	id, err := result.LastInsertId()
+		habit.ID = id
+		return recordHabitEvent(ctx, tx, events.HabitCreated, habit.ID, habit.UserID)
+	})
 	if err != nil {
 		return err
 	}
 
-	habit.ID = id
-	return r.GetByID(habit.ID, habit.UserID, habit)
+	return r.GetByID(ctx, habit.ID, habit.UserID, habit)
 }
 
 // GetByID retrieves a habit by ID for a specific user
-func (r *HabitRepository) GetByID(id, userID int64, habit *Habit) error {
+func (r *HabitRepository) GetByID(ctx context.Context, id, userID int64, habit *Habit) error {
+	ctx, span := tracing.Start(ctx, "HabitRepository.GetByID")
+	defer span.End()
+
 	query := `
 		SELECT id, user_id, title, start_date, end_date, reminder_time, created_at, updated_at
 		FROM habits 
@@ -75,7 +95,7 @@ func (r *HabitRepository) GetByID(id, userID int64, habit *Habit) error {
 	`
 
 	var reminderTime sql.NullString
-	err := r.db.QueryRow(query, id, userID).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(
 		&habit.ID,
 		&habit.UserID,
 		&habit.Title,
@@ -94,7 +114,10 @@ func (r *HabitRepository) GetByID(id, userID int64, habit *Habit) error {
 }
 
 // GetByUserID retrieves all habits for a user
-func (r *HabitRepository) GetByUserID(userID int64) ([]Habit, error) {
+func (r *HabitRepository) GetByUserID(ctx context.Context, userID int64) ([]Habit, error) {
+	ctx, span := tracing.Start(ctx, "HabitRepository.GetByUserID")
+	defer span.End()
+
 	query := `
 		SELECT id, user_id, title, start_date, end_date, reminder_time, created_at, updated_at
 		FROM habits 
@@ -102,7 +125,7 @@ func (r *HabitRepository) GetByUserID(userID int64) ([]Habit, error) {
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query, userID)
+	rows, err := r.db.QueryContext(ctx, query, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -138,66 +161,99 @@ func (r *HabitRepository) GetByUserID(userID int64) ([]Habit, error) {
 }
 
 // Update updates a habit
-func (r *HabitRepository) Update(habit *Habit) error {
+func (r *HabitRepository) Update(ctx context.Context, habit *Habit) error {
+	ctx, span := tracing.Start(ctx, "HabitRepository.Update")
+	defer span.End()
+
 	query := `
 		UPDATE habits 
 		SET title = ?, reminder_time = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ? AND user_id = ?
 	`
 
-	result, err := r.db.Exec(query,
-		habit.Title,
-		habit.ReminderTime,
-		habit.ID,
-		habit.UserID,
-	)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query,
+			habit.Title,
+			habit.ReminderTime,
+			habit.ID,
+			habit.UserID,
+		)
+		if err != nil {
+			return err
+		}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
+		if err := requireRow(result); err != nil {
+			return err
+		}
 
-	return nil
+		return recordHabitEvent(ctx, tx, events.HabitUpdated, habit.ID, habit.UserID)
+	})
 }
 
 // Delete deletes a habit
-func (r *HabitRepository) Delete(id, userID int64) error {
+func (r *HabitRepository) Delete(ctx context.Context, id, userID int64) error {
+	ctx, span := tracing.Start(ctx, "HabitRepository.Delete")
+	defer span.End()
+
 	query := "DELETE FROM habits WHERE id = ? AND user_id = ?"
 
-	result, err := r.db.Exec(query, id, userID)
-	if err != nil {
-		return err
-	}
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, id, userID)
+		if err != nil {
+			return err
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+		if err := requireRow(result); err != nil {
+			return err
+		}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
+		return recordHabitEvent(ctx, tx, events.HabitDeleted, id, userID)
+	})
+}
 
-	return nil
+// DeleteByUserID deletes every habit of a user together with its logs and
+// returns how many rows went
+func (r *HabitRepository) DeleteByUserID(ctx context.Context, userID int64) (int64, error) {
+	ctx, span := tracing.Start(ctx, "HabitRepository.DeleteByUserID")
+	defer span.End()
+
+	var deleted int64
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, query := range []string{
+			"DELETE FROM habit_logs WHERE habit_id IN (SELECT id FROM habits WHERE user_id = ?)",
+			"DELETE FROM habits WHERE user_id = ?",
+		} {
+			result, err := tx.ExecContext(ctx, query, userID)
+			if err != nil {
+				return err
+			}
+
+			n, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			deleted += n
+		}
+		return nil
+	})
+	return deleted, err
 }
 
-// GetActiveHabits retrieves active habits for a user (habits that are currently running)
-func (r *HabitRepository) GetActiveHabits(userID int64) ([]Habit, error) {
+// GetActiveHabits retrieves active habits for a user (habits that are currently
+// running on the current date in loc)
+func (r *HabitRepository) GetActiveHabits(ctx context.Context, userID int64, loc *time.Location) ([]Habit, error) {
+	ctx, span := tracing.Start(ctx, "HabitRepository.GetActiveHabits")
+	defer span.End()
+
 	query := `
 		SELECT id, user_id, title, start_date, end_date, reminder_time, created_at, updated_at
 		FROM habits 
-		WHERE user_id = ? AND start_date <= CURDATE() AND end_date >= CURDATE()
+		WHERE user_id = ? AND start_date <= ? AND end_date >= ?
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query, userID)
+	today := utils.LocalDate(time.Now(), loc)
+	rows, err := r.db.QueryContext(ctx, query, userID, today, today)
 	if err != nil {
 		return nil, err
 	}
@@ -243,37 +299,48 @@ func NewHabitLogRepository(db *sql.DB) *HabitLogRepository {
 }
 
 // Create creates a new habit log
-func (r *HabitLogRepository) Create(log *HabitLog) error {
+func (r *HabitLogRepository) Create(ctx context.Context, log *HabitLog) error {
+	ctx, span := tracing.Start(ctx, "HabitLogRepository.Create")
+	defer span.End()
+
 	query := `
 		INSERT INTO habit_logs (habit_id, date, status, note) 
 		VALUES (?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE status = VALUES(status), note = VALUES(note), updated_at = CURRENT_TIMESTAMP
 	`
 
-	result, err := r.DB.Exec(query,
-		log.HabitID,
-		log.Date,
-		log.Status,
-		log.Note,
-	)
-	if err != nil {
-		return err
-	}
+	err := database.WithTx(ctx, r.DB, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, query,
+			log.HabitID,
+			log.Date,
+			log.Status,
+			log.Note,
+		)
+		if err != nil {
+			return err
+		}
+
+		// The upsert may have updated an existing row, so look the ID up rather than trusting LastInsertId
+		err = tx.QueryRowContext(ctx, "SELECT id FROM habit_logs WHERE habit_id = ? AND date = ?",
+			log.HabitID, log.Date.Format(constants.DateFormat)).Scan(&log.ID)
+		if err != nil {
+			return err
+		}
 
-	id, err := result.LastInsertId()
+		return recordHabitLogEvent(ctx, tx, events.HabitLogged, log)
+	})
 	if err != nil {
 		return err
 	}
 
-	if id > 0 {
-		log.ID = id
-	}
-
-	return r.GetByHabitAndDate(log.HabitID, log.Date, log)
+	return r.GetByHabitAndDate(ctx, log.HabitID, log.Date, log)
 }
 
 // GetByHabitAndDate retrieves a habit log by habit ID and date
-func (r *HabitLogRepository) GetByHabitAndDate(habitID int64, date time.Time, log *HabitLog) error {
+func (r *HabitLogRepository) GetByHabitAndDate(ctx context.Context, habitID int64, date time.Time, log *HabitLog) error {
+	ctx, span := tracing.Start(ctx, "HabitLogRepository.GetByHabitAndDate")
+	defer span.End()
+
 	query := `
 		SELECT id, habit_id, date, status, photo_url, note, created_at, updated_at
 		FROM habit_logs 
@@ -281,7 +348,7 @@ func (r *HabitLogRepository) GetByHabitAndDate(habitID int64, date time.Time, lo
 	`
 
 	var photoURL, note sql.NullString
-	err := r.DB.QueryRow(query, habitID, date.Format("2006-01-02")).Scan(
+	err := r.DB.QueryRowContext(ctx, query, habitID, date.Format("2006-01-02")).Scan(
 		&log.ID,
 		&log.HabitID,
 		&log.Date,
@@ -303,7 +370,10 @@ func (r *HabitLogRepository) GetByHabitAndDate(habitID int64, date time.Time, lo
 }
 
 // GetByHabitID retrieves all logs for a habit
-func (r *HabitLogRepository) GetByHabitID(habitID int64) ([]HabitLog, error) {
+func (r *HabitLogRepository) GetByHabitID(ctx context.Context, habitID int64) ([]HabitLog, error) {
+	ctx, span := tracing.Start(ctx, "HabitLogRepository.GetByHabitID")
+	defer span.End()
+
 	query := `
 		SELECT id, habit_id, date, status, photo_url, note, created_at, updated_at
 		FROM habit_logs 
@@ -311,7 +381,7 @@ func (r *HabitLogRepository) GetByHabitID(habitID int64) ([]HabitLog, error) {
 		ORDER BY date DESC
 	`
 
-	rows, err := r.DB.Query(query, habitID)
+	rows, err := r.DB.QueryContext(ctx, query, habitID)
 	if err != nil {
 		return nil, err
 	}
@@ -349,33 +419,135 @@ func (r *HabitLogRepository) GetByHabitID(habitID int64) ([]HabitLog, error) {
 	return logs, nil
 }
 
-// Update updates a habit log
-func (r *HabitLogRepository) Update(log *HabitLog) error {
+// ListByHabitID retrieves one page of a habit's logs ordered by (date, id) descending.
+// The boolean reports whether more rows exist beyond the page in the direction it was read.
+func (r *HabitLogRepository) ListByHabitID(ctx context.Context, habitID int64, cursor *utils.Cursor, offset, limit int) ([]HabitLog, bool, error) {
+	ctx, span := tracing.Start(ctx, "HabitLogRepository.ListByHabitID")
+	defer span.End()
+
+	where := "habit_id = ?"
+	args := []interface{}{habitID}
+	order := "date DESC, id DESC"
+
+	if cursor != nil {
+		if _, err := time.Parse(constants.DateFormat, cursor.Key); err != nil {
+			return nil, false, utils.ErrInvalidCursor
+		}
+
+		if cursor.Backward {
+			where += " AND (date > ? OR (date = ? AND id > ?))"
+			order = "date ASC, id ASC"
+		} else {
+			where += " AND (date < ? OR (date = ? AND id < ?))"
+		}
+		args = append(args, cursor.Key, cursor.Key, cursor.ID)
+		offset = 0
+	}
+
+	// Fetch one extra row to learn whether another page follows
 	query := `
-		UPDATE habit_logs 
-		SET status = ?, note = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
+		SELECT id, habit_id, date, status, photo_url, note, created_at, updated_at
+		FROM habit_logs 
+		WHERE ` + where + `
+		ORDER BY ` + order + `
+		LIMIT ? OFFSET ?
 	`
+	args = append(args, limit+1, offset)
 
-	result, err := r.DB.Exec(query, log.Status, log.Note, log.ID)
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
+	var logs []HabitLog
+	for rows.Next() {
+		var log HabitLog
+		var photoURL, note sql.NullString
+
+		err := rows.Scan(
+			&log.ID,
+			&log.HabitID,
+			&log.Date,
+			&log.Status,
+			&photoURL,
+			&note,
+			&log.CreatedAt,
+			&log.UpdatedAt,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+
+		log.PhotoURL = photoURL.String
+		log.Note = note.String
+
+		logs = append(logs, log)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
 	}
 
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
+	more := len(logs) > limit
+	if more {
+		logs = logs[:limit]
 	}
 
-	return nil
+	// Backward pages are read oldest first; restore newest-first order
+	if cursor != nil && cursor.Backward {
+		for i, j := 0, len(logs)-1; i < j; i, j = i+1, j-1 {
+			logs[i], logs[j] = logs[j], logs[i]
+		}
+	}
+
+	return logs, more, nil
+}
+
+// CountByHabitID returns the number of logs recorded for a habit
+func (r *HabitLogRepository) CountByHabitID(ctx context.Context, habitID int64) (int, error) {
+	ctx, span := tracing.Start(ctx, "HabitLogRepository.CountByHabitID")
+	defer span.End()
+
+	var total int
+	err := r.DB.QueryRowContext(ctx, "SELECT COUNT(*) FROM habit_logs WHERE habit_id = ?", habitID).Scan(&total)
+	return total, err
+}
+
+// Cursor returns the keyset position of a habit log
+func (l *HabitLog) Cursor() *utils.Cursor {
+	return &utils.Cursor{Key: l.Date.Format(constants.DateFormat), ID: l.ID}
+}
+
+// Update updates a habit log
+func (r *HabitLogRepository) Update(ctx context.Context, log *HabitLog) error {
+	ctx, span := tracing.Start(ctx, "HabitLogRepository.Update")
+	defer span.End()
+
+	query := `
+		UPDATE habit_logs 
+		SET status = ?, note = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	return database.WithTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, log.Status, log.Note, log.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := requireRow(result); err != nil {
+			return err
+		}
+
+		return recordHabitLogEvent(ctx, tx, events.HabitLogUpdated, log)
+	})
 }
 
 // GetLogByIDWithOwnership retrieves a habit log by ID and verifies user ownership
-func (r *HabitLogRepository) GetLogByIDWithOwnership(logID, userID int64) (*HabitLog, error) {
+func (r *HabitLogRepository) GetLogByIDWithOwnership(ctx context.Context, logID, userID int64) (*HabitLog, error) {
+	ctx, span := tracing.Start(ctx, "HabitLogRepository.GetLogByIDWithOwnership")
+	defer span.End()
+
 	query := `
 		SELECT hl.id, hl.habit_id, hl.date, hl.status, hl.photo_url, hl.note, hl.created_at, hl.updated_at
 		FROM habit_logs hl
@@ -385,7 +557,7 @@ func (r *HabitLogRepository) GetLogByIDWithOwnership(logID, userID int64) (*Habi
 
 	var log HabitLog
 	var photoURL, note sql.NullString
-	err := r.DB.QueryRow(query, logID, userID).Scan(
+	err := r.DB.QueryRowContext(ctx, query, logID, userID).Scan(
 		&log.ID, &log.HabitID, &log.Date, &log.Status, &photoURL, &note, &log.CreatedAt, &log.UpdatedAt,
 	)
 	if err != nil {
@@ -402,8 +574,12 @@ func (r *HabitLogRepository) GetLogByIDWithOwnership(logID, userID int64) (*Habi
 	return &log, nil
 }
 
-// GetStats calculates habit statistics
-func (r *HabitLogRepository) GetStats(habitID int64) (map[string]interface{}, error) {
+// GetStats calculates habit statistics; the current streak is measured up to
+// the current date in loc
+func (r *HabitLogRepository) GetStats(ctx context.Context, habitID int64, loc *time.Location) (map[string]interface{}, error) {
+	ctx, span := tracing.Start(ctx, "HabitLogRepository.GetStats")
+	defer span.End()
+
 	query := `
 		SELECT 
 			COUNT(*) as total_days,
@@ -415,7 +591,7 @@ func (r *HabitLogRepository) GetStats(habitID int64) (map[string]interface{}, er
 	`
 
 	var totalDays, completedDays, skippedDays, failedDays int
-	err := r.DB.QueryRow(query, habitID).Scan(&totalDays, &completedDays, &skippedDays, &failedDays)
+	err := r.DB.QueryRowContext(ctx, query, habitID).Scan(&totalDays, &completedDays, &skippedDays, &failedDays)
 	if err != nil {
 		return nil, err
 	}
@@ -426,8 +602,8 @@ func (r *HabitLogRepository) GetStats(habitID int64) (map[string]interface{}, er
 	}
 
 	// Calculate current streak
-	currentStreak := r.calculateCurrentStreak(habitID)
-	longestStreak := r.calculateLongestStreak(habitID)
+	currentStreak := r.calculateCurrentStreak(ctx, habitID, utils.Today(loc))
+	longestStreak := r.calculateLongestStreak(ctx, habitID)
 
 	return map[string]interface{}{
 		"total_days":     totalDays,
@@ -440,47 +616,55 @@ func (r *HabitLogRepository) GetStats(habitID int64) (map[string]interface{}, er
 	}, nil
 }
 
-// calculateCurrentStreak calculates the current streak of completed days
-func (r *HabitLogRepository) calculateCurrentStreak(habitID int64) int {
+// calculateCurrentStreak calculates the current streak of completed days: consecutive
+// DONE days ending today, or yesterday while today has not been logged yet
+func (r *HabitLogRepository) calculateCurrentStreak(ctx context.Context, habitID int64, today time.Time) int {
 	query := `
-		SELECT status FROM habit_logs 
+		SELECT date, status FROM habit_logs 
 		WHERE habit_id = ? 
 		ORDER BY date DESC 
 		LIMIT 30
 	`
 
-	rows, err := r.DB.Query(query, habitID)
+	rows, err := r.DB.QueryContext(ctx, query, habitID)
 	if err != nil {
 		return 0
 	}
 	defer rows.Close()
 
 	streak := 0
+	expected := today
 	for rows.Next() {
+		var date time.Time
 		var status string
-		if err := rows.Scan(&status); err != nil {
+		if err := rows.Scan(&date, &status); err != nil {
 			break
 		}
 
-		if status == "DONE" {
-			streak++
-		} else {
+		day := date.Format(constants.DateFormat)
+		if streak == 0 && day != expected.Format(constants.DateFormat) {
+			expected = expected.AddDate(0, 0, -1)
+		}
+		if status != "DONE" || day != expected.Format(constants.DateFormat) {
 			break
 		}
+
+		streak++
+		expected = expected.AddDate(0, 0, -1)
 	}
 
 	return streak
 }
 
 // calculateLongestStreak calculates the longest streak of completed days
-func (r *HabitLogRepository) calculateLongestStreak(habitID int64) int {
+func (r *HabitLogRepository) calculateLongestStreak(ctx context.Context, habitID int64) int {
 	query := `
 		SELECT status FROM habit_logs 
 		WHERE habit_id = ? 
 		ORDER BY date ASC
 	`
 
-	rows, err := r.DB.Query(query, habitID)
+	rows, err := r.DB.QueryContext(ctx, query, habitID)
 	if err != nil {
 		return 0
 	}
@@ -507,3 +691,46 @@ func (r *HabitLogRepository) calculateLongestStreak(habitID int64) int {
 
 	return longestStreak
 }
+
+// requireRow returns sql.ErrNoRows when a statement matched nothing
+func requireRow(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// recordHabitEvent writes a habit.* event to the outbox within tx
+func recordHabitEvent(ctx context.Context, tx *sql.Tx, eventType string, habitID, userID int64) error {
+	event, err := events.New(eventType, userID, events.HabitPayload{HabitID: habitID})
+	if err != nil {
+		return err
+	}
+	return events.Record(ctx, tx, event)
+}
+
+// recordHabitLogEvent writes a habit log event to the outbox within tx,
+// attributing it to the owner of the log's habit
+func recordHabitLogEvent(ctx context.Context, tx *sql.Tx, eventType string, log *HabitLog) error {
+	var userID int64
+	if err := tx.QueryRowContext(ctx, "SELECT user_id FROM habits WHERE id = ?", log.HabitID).Scan(&userID); err != nil {
+		return err
+	}
+
+	event, err := events.New(eventType, userID, events.HabitLogPayload{
+		HabitID: log.HabitID,
+		LogID:   log.ID,
+		Date:    log.Date.Format(constants.DateFormat),
+		Status:  log.Status,
+	})
+	if err != nil {
+		return err
+	}
+	return events.Record(ctx, tx, event)
+}
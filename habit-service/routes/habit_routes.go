@@ -3,15 +3,16 @@ package routes
 import (
 	"dailytrackr/habit-service/handlers"
 	"dailytrackr/habit-service/middleware"
+	"dailytrackr/shared/sessions"
 
 	"github.com/labstack/echo/v4"
 )
 
 // SetupHabitRoutes sets up all habit-related routes
-func SetupHabitRoutes(e *echo.Echo, habitHandlers *handlers.HabitHandlers) {
+func SetupHabitRoutes(e *echo.Echo, habitHandlers *handlers.HabitHandlers, checker *sessions.Checker) {
 	// API v1 routes with authentication
 	api := e.Group("/api/v1")
-	api.Use(middleware.AuthMiddleware())
+	api.Use(middleware.AuthMiddleware(checker))
 
 	// Habit routes - FIXED: Remove trailing slash from POST route
 	habits := api.Group("/habits")
@@ -0,0 +1,75 @@
+// Package authz defines the roles users can hold and the permissions they
+// grant. Both travel in the JWT, so every service can authorize a request
+// without asking the user service.
+package authz
+
+import (
+	"slices"
+	"sort"
+)
+
+// Roles. Every user is implicitly RoleUser, which grants nothing beyond
+// their own resources.
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+// Permissions, as resource:action
+const (
+	PermUsersRead   = "users:read"   // view any user's account
+	PermUsersManage = "users:manage" // disable, unlock and reset accounts
+	PermUsageRead   = "usage:read"   // view any user's usage statistics
+	PermRolesManage = "roles:manage" // grant and revoke roles
+)
+
+// rolePermissions lists what each role grants
+var rolePermissions = map[string][]string{
+	RoleUser:    nil,
+	RoleSupport: {PermUsersRead, PermUsageRead},
+	RoleAdmin:   {PermUsersRead, PermUsersManage, PermUsageRead, PermRolesManage},
+}
+
+// ValidRole reports whether role exists
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Roles returns every role, sorted
+func Roles() []string {
+	roles := make([]string, 0, len(rolePermissions))
+	for role := range rolePermissions {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// Permissions returns the sorted permissions granted by roles together;
+// unknown roles grant nothing
+func Permissions(roles []string) []string {
+	var perms []string
+	for _, role := range roles {
+		for _, perm := range rolePermissions[role] {
+			if !slices.Contains(perms, perm) {
+				perms = append(perms, perm)
+			}
+		}
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// Has reports whether perms includes perm
+func Has(perms []string, perm string) bool {
+	return slices.Contains(perms, perm)
+}
+
+// CanAccessUser reports whether the caller, with callerID and perms, may act
+// on userID's resources with perm: always for their own, otherwise only
+// with the permission
+func CanAccessUser(callerID int64, perms []string, userID int64, perm string) bool {
+	return callerID == userID || Has(perms, perm)
+}
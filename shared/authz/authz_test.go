@@ -0,0 +1,31 @@
+package authz
+
+import "testing"
+
+func TestCanAccessUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		callerID int64
+		perms    []string
+		userID   int64
+		perm     string
+		want     bool
+	}{
+		{"own resources without permissions", 1, nil, 1, PermUsersRead, true},
+		{"other user without permissions", 1, nil, 2, PermUsersRead, false},
+		{"other user with the permission", 1, []string{PermUsersRead}, 2, PermUsersRead, true},
+		{"other user with another permission", 1, []string{PermUsageRead}, 2, PermUsersRead, false},
+		{"support reading usage", 1, Permissions([]string{RoleUser, RoleSupport}), 2, PermUsageRead, true},
+		{"support managing users", 1, Permissions([]string{RoleUser, RoleSupport}), 2, PermUsersManage, false},
+		{"admin managing users", 1, Permissions([]string{RoleUser, RoleAdmin}), 2, PermUsersManage, true},
+		{"unknown role", 1, Permissions([]string{"root"}), 2, PermUsersRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanAccessUser(tt.callerID, tt.perms, tt.userID, tt.perm); got != tt.want {
+				t.Errorf("CanAccessUser(%d, %v, %d, %q) = %v, want %v", tt.callerID, tt.perms, tt.userID, tt.perm, got, tt.want)
+			}
+		})
+	}
+}
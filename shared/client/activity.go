@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+)
+
+// PageOptions selects a page of a listing. Cursor takes precedence over Page.
+type PageOptions struct {
+	Cursor string
+	Page   int
+	Limit  int
+}
+
+// values encodes the options as query parameters
+func (o PageOptions) values() url.Values {
+	query := url.Values{}
+	if o.Cursor != "" {
+		query.Set("cursor", o.Cursor)
+	}
+	if o.Page > 0 {
+		query.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.Limit > 0 {
+		query.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return query
+}
+
+// ActivityListOptions filters an activity listing; dates are YYYY-MM-DD
+type ActivityListOptions struct {
+	PageOptions
+	StartDate string
+	EndDate   string
+}
+
+// ActivityClient calls the activity service
+type ActivityClient interface {
+	Create(ctx context.Context, req dto.CreateActivityRequest) (*dto.ActivityResponse, error)
+	List(ctx context.Context, opts ActivityListOptions) (*dto.ActivityListResponse, error)
+	Get(ctx context.Context, id int64) (*dto.ActivityResponse, error)
+	Update(ctx context.Context, id int64, req dto.UpdateActivityRequest) (*dto.ActivityResponse, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type activityClient struct {
+	base
+}
+
+// NewActivityClient creates an activity service client
+func NewActivityClient(opts Options) ActivityClient {
+	return &activityClient{base: newBase(constants.ActivityService, opts)}
+}
+
+func activityPath(id int64) string {
+	return "/api/v1/activities/" + strconv.FormatInt(id, 10)
+}
+
+// Create records an activity for the calling user
+func (c *activityClient) Create(ctx context.Context, req dto.CreateActivityRequest) (*dto.ActivityResponse, error) {
+	var out dto.ActivityResponse
+	if err := c.call(ctx, http.MethodPost, "/api/v1/activities/", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns a page of the calling user's activities, newest first
+func (c *activityClient) List(ctx context.Context, opts ActivityListOptions) (*dto.ActivityListResponse, error) {
+	query := opts.values()
+	if opts.StartDate != "" {
+		query.Set("start_date", opts.StartDate)
+	}
+	if opts.EndDate != "" {
+		query.Set("end_date", opts.EndDate)
+	}
+
+	var out dto.ActivityListResponse
+	if err := c.call(ctx, http.MethodGet, "/api/v1/activities/", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Get returns one of the calling user's activities
+func (c *activityClient) Get(ctx context.Context, id int64) (*dto.ActivityResponse, error) {
+	var out dto.ActivityResponse
+	if err := c.call(ctx, http.MethodGet, activityPath(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Update changes one of the calling user's activities
+func (c *activityClient) Update(ctx context.Context, id int64, req dto.UpdateActivityRequest) (*dto.ActivityResponse, error) {
+	var out dto.ActivityResponse
+	if err := c.call(ctx, http.MethodPut, activityPath(id), nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Delete removes one of the calling user's activities
+func (c *activityClient) Delete(ctx context.Context, id int64) error {
+	return c.call(ctx, http.MethodDelete, activityPath(id), nil, nil, nil)
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+)
+
+// AIClient calls the AI service. Zero days use the service default.
+type AIClient interface {
+	DailySummary(ctx context.Context, date string) (*dto.DailySummary, error)
+	DailySummaries(ctx context.Context) ([]dto.DailySummary, error)
+	HabitRecommendation(ctx context.Context, days int) (*dto.HabitRecommendationResponse, error)
+	Insights(ctx context.Context) (*dto.UserInsights, error)
+	AnalyzeActivities(ctx context.Context, days int) (*dto.ActivityAnalysisResponse, error)
+	ProductivityTips(ctx context.Context) (*dto.ProductivityTipsResponse, error)
+}
+
+type aiClient struct {
+	base
+}
+
+// NewAIClient creates an AI service client
+func NewAIClient(opts Options) AIClient {
+	return &aiClient{base: newBase(constants.AIService, opts)}
+}
+
+// daysQuery encodes an optional analysis window
+func daysQuery(days int) url.Values {
+	if days <= 0 {
+		return nil
+	}
+	return url.Values{"days": {strconv.Itoa(days)}}
+}
+
+// DailySummary returns the summary for date (YYYY-MM-DD, empty for today),
+// generating it if none is cached. Uses GET so that it can be retried.
+func (c *aiClient) DailySummary(ctx context.Context, date string) (*dto.DailySummary, error) {
+	var query url.Values
+	if date != "" {
+		query = url.Values{"date": {date}}
+	}
+
+	var out dto.DailySummary
+	if err := c.call(ctx, http.MethodGet, "/api/v1/ai/daily-summary", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DailySummaries returns every saved daily summary, generating none
+func (c *aiClient) DailySummaries(ctx context.Context) ([]dto.DailySummary, error) {
+	var out []dto.DailySummary
+	if err := c.call(ctx, http.MethodGet, "/api/v1/ai/daily-summaries", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HabitRecommendation suggests a habit based on recent activities
+func (c *aiClient) HabitRecommendation(ctx context.Context, days int) (*dto.HabitRecommendationResponse, error) {
+	var out dto.HabitRecommendationResponse
+	if err := c.call(ctx, http.MethodGet, "/api/v1/ai/habit-recommendation", daysQuery(days), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Insights returns the calling user's insights
+func (c *aiClient) Insights(ctx context.Context) (*dto.UserInsights, error) {
+	var out dto.UserInsights
+	if err := c.call(ctx, http.MethodGet, "/api/v1/ai/insights", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AnalyzeActivities analyses recent activities
+func (c *aiClient) AnalyzeActivities(ctx context.Context, days int) (*dto.ActivityAnalysisResponse, error) {
+	var out dto.ActivityAnalysisResponse
+	if err := c.call(ctx, http.MethodGet, "/api/v1/ai/analyze-activities", daysQuery(days), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ProductivityTips returns tips personalised to the calling user
+func (c *aiClient) ProductivityTips(ctx context.Context) (*dto.ProductivityTipsResponse, error) {
+	var out dto.ProductivityTipsResponse
+	if err := c.call(ctx, http.MethodGet, "/api/v1/ai/productivity-tips", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,339 @@
+// Package client provides typed HTTP clients for the DailyTrackr services.
+// Each client speaks the services' JSON envelopes and returns shared/dto types;
+// failures are decoded into *errors.Error so callers can branch on the code.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"dailytrackr/shared/config"
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/metrics"
+	"dailytrackr/shared/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Default client settings
+const (
+	DefaultTimeout = 10 * time.Second
+	DefaultRetries = 2
+	DefaultBackoff = 200 * time.Millisecond
+)
+
+// Options configures a service client. Zero values fall back to the defaults.
+type Options struct {
+	BaseURL    string        // e.g. http://localhost:3001
+	Timeout    time.Duration // per attempt
+	Retries    int           // extra attempts for idempotent requests; negative disables retries
+	Backoff    time.Duration // delay before the first retry, doubled on each further retry
+	HTTPClient *http.Client
+}
+
+// Clients bundles a client for every service
+type Clients struct {
+	User     UserClient
+	Activity ActivityClient
+	Habit    HabitClient
+	Stat     StatClient
+	AI       AIClient
+}
+
+// New creates clients for every service at the ports in cfg
+func New(cfg *config.Config) *Clients {
+	opts := func(port string) Options {
+		return Options{BaseURL: "http://localhost:" + port}
+	}
+
+	return &Clients{
+		User:     NewUserClient(opts(cfg.UserServicePort)),
+		Activity: NewActivityClient(opts(cfg.ActivityPort)),
+		Habit:    NewHabitClient(opts(cfg.HabitPort)),
+		Stat:     NewStatClient(opts(cfg.StatPort)),
+		AI:       NewAIClient(opts(cfg.AIPort)),
+	}
+}
+
+// Request metadata carried in the context and forwarded on every call
+type contextKey int
+
+const (
+	authorizationKey contextKey = iota
+	requestIDKey
+	languageKey
+)
+
+// WithToken returns ctx carrying a bearer token for outgoing calls
+func WithToken(ctx context.Context, token string) context.Context {
+	return WithAuthorization(ctx, constants.BearerPrefix+token)
+}
+
+// WithAuthorization returns ctx carrying a raw Authorization header value
+func WithAuthorization(ctx context.Context, authorization string) context.Context {
+	return context.WithValue(ctx, authorizationKey, authorization)
+}
+
+// WithRequestID returns ctx carrying the request ID to forward
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// WithLanguage returns ctx carrying the Accept-Language to forward
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey, language)
+}
+
+// Propagate returns ctx carrying the auth, request ID and language headers of an
+// incoming request, so calls made while serving it act on behalf of the same user
+func Propagate(ctx context.Context, header http.Header) context.Context {
+	if v := header.Get(constants.AuthorizationHeader); v != "" {
+		ctx = WithAuthorization(ctx, v)
+	}
+	if v := header.Get(constants.RequestIDHeader); v != "" {
+		ctx = WithRequestID(ctx, v)
+	}
+	if v := header.Get(constants.AcceptLanguageHeader); v != "" {
+		ctx = WithLanguage(ctx, v)
+	}
+	return ctx
+}
+
+// envelope is the success body every service returns
+type envelope struct {
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Data       json.RawMessage `json:"data"`
+	Total      int             `json:"total"`
+	Pagination json.RawMessage `json:"pagination"`
+}
+
+// base holds the transport shared by all service clients
+type base struct {
+	service string
+	opts    Options
+}
+
+func newBase(service string, opts Options) base {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.Retries == 0 {
+		opts.Retries = DefaultRetries
+	} else if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = DefaultBackoff
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{}
+	}
+	opts.BaseURL = strings.TrimRight(opts.BaseURL, "/")
+
+	return base{service: service, opts: opts}
+}
+
+// call performs a request and decodes the envelope's data into out, if non-nil
+func (b base) call(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	env, err := b.do(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	return decodeData(env.Data, out)
+}
+
+// do performs a request, retrying idempotent methods on transport errors and
+// gateway statuses, and returns the decoded success envelope
+func (b base) do(ctx context.Context, method, path string, query url.Values, body interface{}) (*envelope, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, apperrors.Internal("Failed to encode request", err)
+		}
+	}
+
+	target := b.opts.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	ctx, span := tracing.StartClient(ctx, b.service+" "+method+" "+path,
+		attribute.String("peer.service", b.service),
+		attribute.String("http.request.method", method),
+		attribute.String("url.full", b.opts.BaseURL+path),
+	)
+	defer span.End()
+
+	attempts := 1
+	if isIdempotent(method) {
+		attempts += b.opts.Retries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if err := wait(ctx, b.opts.Backoff<<(attempt-1)); err != nil {
+				break
+			}
+		}
+
+		env, retry, err := b.attempt(ctx, method, target, payload)
+		if err == nil {
+			return env, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	span.RecordError(lastErr)
+	span.SetStatus(codes.Error, lastErr.Error())
+	return nil, lastErr
+}
+
+// attempt makes a single request; retry reports whether a failure is worth repeating
+func (b base) attempt(parent context.Context, method, target string, payload []byte) (env *envelope, retry bool, err error) {
+	ctx, cancel := context.WithTimeout(parent, b.opts.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, false, apperrors.Internal("Failed to create request", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	setHeaders(ctx, req.Header)
+	tracing.Inject(ctx, req.Header)
+
+	start := time.Now()
+	resp, err := b.opts.HTTPClient.Do(req)
+	if err != nil {
+		metrics.ObserveUpstream(b.service, 0, time.Since(start))
+		// Our own per-attempt timeout is retried; the caller giving up is not
+		return nil, parent.Err() == nil,
+			apperrors.UpstreamUnavailable("Service temporarily unavailable", fmt.Errorf("%s: %w", b.service, err))
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	metrics.ObserveUpstream(b.service, resp.StatusCode, time.Since(start))
+	if err != nil {
+		return nil, true, apperrors.UpstreamUnavailable("Failed to read service response", fmt.Errorf("%s: %w", b.service, err))
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, isRetryableStatus(resp.StatusCode), decodeError(resp, data)
+	}
+
+	env = &envelope{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, env); err != nil {
+			return nil, false, apperrors.UpstreamUnavailable("Invalid service response", fmt.Errorf("%s: %w", b.service, err))
+		}
+	}
+	return env, false, nil
+}
+
+// setHeaders copies the request metadata in ctx onto outgoing headers
+func setHeaders(ctx context.Context, header http.Header) {
+	if v, ok := ctx.Value(authorizationKey).(string); ok && v != "" {
+		header.Set(constants.AuthorizationHeader, v)
+	}
+	if v, ok := ctx.Value(requestIDKey).(string); ok && v != "" {
+		header.Set(constants.RequestIDHeader, v)
+	}
+	if v, ok := ctx.Value(languageKey).(string); ok && v != "" {
+		header.Set(constants.AcceptLanguageHeader, v)
+	}
+}
+
+// decodeError turns an error response into a typed error. Problem documents keep
+// their code and field details; anything else is mapped from the status code.
+func decodeError(resp *http.Response, data []byte) error {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == apperrors.ContentTypeProblem {
+		var problem apperrors.Problem
+		if err := json.Unmarshal(data, &problem); err == nil {
+			code := problem.Code
+			if code == "" {
+				code = apperrors.CodeForStatus(resp.StatusCode)
+			}
+			return &apperrors.Error{Code: code, Message: problem.Detail, Fields: problem.Errors}
+		}
+	}
+
+	var legacy dto.ErrorResponse
+	message := http.StatusText(resp.StatusCode)
+	if err := json.Unmarshal(data, &legacy); err == nil && legacy.Message != "" {
+		message = legacy.Message
+	}
+
+	var cause error
+	if legacy.Error != "" {
+		cause = fmt.Errorf("%s", legacy.Error)
+	}
+	return apperrors.FromStatus(resp.StatusCode, message, cause)
+}
+
+// decodeData unmarshals an envelope's data into out
+func decodeData(data json.RawMessage, out interface{}) error {
+	if out == nil || len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return apperrors.UpstreamUnavailable("Invalid service response", err)
+	}
+	return nil
+}
+
+// isIdempotent reports whether a request may safely be sent more than once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// isRetryableStatus reports whether a status means the upstream may recover
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// wait sleeps for d or until ctx is done
+func wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
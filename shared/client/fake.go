@@ -0,0 +1,896 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/money"
+)
+
+// Fakes are in-memory stand-ins for the service clients, for use in tests.
+// Setting Err makes every call fail with it. Fakes act as a single user and
+// ignore the auth headers carried in the context.
+
+// FakeUserClient is an in-memory UserClient. The last user to register or
+// log in is the caller for profile calls.
+type FakeUserClient struct {
+	Err error
+
+	mu        sync.Mutex
+	users     map[int64]*dto.UserResponse
+	passwords map[int64]string
+	settings  map[int64]*dto.UserSettings
+	current   int64
+	nextID    int64
+}
+
+// NewFakeUserClient creates an empty fake user client
+func NewFakeUserClient() *FakeUserClient {
+	return &FakeUserClient{
+		users:     map[int64]*dto.UserResponse{},
+		passwords: map[int64]string{},
+		settings:  map[int64]*dto.UserSettings{},
+	}
+}
+
+// Register implements UserClient
+func (f *FakeUserClient) Register(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	for _, user := range f.users {
+		if user.Email == req.Email {
+			return nil, apperrors.Conflict(constants.ErrEmailAlreadyExists)
+		}
+		if user.Username == req.Username {
+			return nil, apperrors.Conflict(constants.ErrUsernameExists)
+		}
+	}
+
+	f.nextID++
+	now := time.Now()
+	user := &dto.UserResponse{
+		ID:        f.nextID,
+		Username:  req.Username,
+		Email:     req.Email,
+		Language:  constants.DefaultLanguage,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	f.users[user.ID] = user
+	f.passwords[user.ID] = req.Password
+	f.current = user.ID
+
+	return &dto.AuthResponse{Token: fakeToken(user.ID), User: *user}, nil
+}
+
+// Login implements UserClient
+func (f *FakeUserClient) Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	for id, user := range f.users {
+		if user.Email == req.Email && f.passwords[id] == req.Password {
+			f.current = id
+			return &dto.AuthResponse{Token: fakeToken(id), User: *user}, nil
+		}
+	}
+	return nil, apperrors.Unauthorized(constants.ErrInvalidCredentials)
+}
+
+// GetProfile implements UserClient
+func (f *FakeUserClient) GetProfile(ctx context.Context) (*dto.UserResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	user, err := f.caller()
+	if err != nil {
+		return nil, err
+	}
+	out := *user
+	return &out, nil
+}
+
+// UpdateProfile implements UserClient
+func (f *FakeUserClient) UpdateProfile(ctx context.Context, req dto.UpdateProfileRequest) (*dto.UserResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	user, err := f.caller()
+	if err != nil {
+		return nil, err
+	}
+	if req.Username != "" {
+		user.Username = req.Username
+	}
+	if req.Email != "" {
+		user.Email = req.Email
+	}
+	if req.Bio != nil {
+		user.Bio = *req.Bio
+	}
+	if req.Language != nil {
+		user.Language = *req.Language
+	}
+	user.UpdatedAt = time.Now()
+
+	out := *user
+	return &out, nil
+}
+
+// ChangePassword implements UserClient
+func (f *FakeUserClient) ChangePassword(ctx context.Context, req dto.ChangePasswordRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+
+	user, err := f.caller()
+	if err != nil {
+		return err
+	}
+	if f.passwords[user.ID] != req.CurrentPassword {
+		return apperrors.Unauthorized(constants.ErrInvalidCurrentPassword)
+	}
+	f.passwords[user.ID] = req.NewPassword
+	return nil
+}
+
+// DeleteAccount implements UserClient. The caller is signed out and the
+// deletion stays scheduled, as the fake has no grace period ending.
+func (f *FakeUserClient) DeleteAccount(ctx context.Context, req dto.DeleteAccountRequest) (*dto.AccountDeletionResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	user, err := f.caller()
+	if err != nil {
+		return nil, err
+	}
+	if f.passwords[user.ID] != req.Password {
+		return nil, apperrors.Unauthorized(constants.ErrInvalidCredentials)
+	}
+	f.current = 0
+
+	now := time.Now()
+	return &dto.AccountDeletionResponse{
+		ID:            "fake-deletion-" + strconv.FormatInt(user.ID, 10),
+		Status:        "scheduled",
+		RequestedAt:   now,
+		ScheduledFor:  now,
+		Confirmations: []dto.DeletionConfirmation{},
+	}, nil
+}
+
+// GetUser implements UserClient
+func (f *FakeUserClient) GetUser(ctx context.Context, id int64) (*dto.UserResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	user, ok := f.users[id]
+	if !ok {
+		return nil, apperrors.NotFound(constants.ErrUserNotFound)
+	}
+	out := *user
+	return &out, nil
+}
+
+// GetSettings implements UserClient
+func (f *FakeUserClient) GetSettings(ctx context.Context) (*dto.UserSettings, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	user, err := f.caller()
+	if err != nil {
+		return nil, err
+	}
+	out := *f.settingsFor(user)
+	return &out, nil
+}
+
+// UpdateSettings implements UserClient
+func (f *FakeUserClient) UpdateSettings(ctx context.Context, req dto.UpdateSettingsRequest) (*dto.UserSettings, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	user, err := f.caller()
+	if err != nil {
+		return nil, err
+	}
+	settings := f.settingsFor(user)
+	if req.Timezone != nil {
+		settings.Timezone, user.Timezone = *req.Timezone, *req.Timezone
+	}
+	if req.WeekStart != nil {
+		settings.WeekStart = *req.WeekStart
+	}
+	if req.Currency != nil {
+		settings.Currency, user.Currency = *req.Currency, *req.Currency
+	}
+	if req.Language != nil {
+		settings.Language, user.Language = *req.Language, *req.Language
+	}
+	if req.Theme != nil {
+		settings.Theme = *req.Theme
+	}
+	if n := req.Notifications; n != nil {
+		if n.HabitReminders != nil {
+			settings.Notifications.HabitReminders = *n.HabitReminders
+		}
+		if n.WeeklySummary != nil {
+			settings.Notifications.WeeklySummary = *n.WeeklySummary
+		}
+		if n.ProductUpdates != nil {
+			settings.Notifications.ProductUpdates = *n.ProductUpdates
+		}
+	}
+
+	out := *settings
+	return &out, nil
+}
+
+// settingsFor returns user's settings, starting from the defaults; f.mu must be held
+func (f *FakeUserClient) settingsFor(user *dto.UserResponse) *dto.UserSettings {
+	settings, ok := f.settings[user.ID]
+	if !ok {
+		settings = &dto.UserSettings{
+			Timezone:  user.Timezone,
+			WeekStart: constants.DefaultWeekStart,
+			Currency:  user.Currency,
+			Language:  user.Language,
+			Theme:     constants.DefaultTheme,
+			Notifications: dto.NotificationSettings{
+				HabitReminders: constants.DefaultHabitReminders,
+				WeeklySummary:  constants.DefaultWeeklySummary,
+				ProductUpdates: constants.DefaultProductUpdates,
+			},
+		}
+		f.settings[user.ID] = settings
+	}
+	return settings
+}
+
+// caller returns the logged-in user; f.mu must be held
+func (f *FakeUserClient) caller() (*dto.UserResponse, error) {
+	user, ok := f.users[f.current]
+	if !ok {
+		return nil, apperrors.Unauthorized(constants.ErrInvalidToken)
+	}
+	return user, nil
+}
+
+func fakeToken(userID int64) string {
+	return "fake-token-" + strconv.FormatInt(userID, 10)
+}
+
+// FakeActivityClient is an in-memory ActivityClient owned by UserID
+type FakeActivityClient struct {
+	UserID   int64
+	Currency string // applied to costs sent without one
+	Err      error
+
+	mu         sync.Mutex
+	activities map[int64]*dto.ActivityResponse
+	nextID     int64
+}
+
+// NewFakeActivityClient creates an empty fake activity client for userID
+func NewFakeActivityClient(userID int64) *FakeActivityClient {
+	return &FakeActivityClient{UserID: userID, Currency: constants.DefaultCurrency, activities: map[int64]*dto.ActivityResponse{}}
+}
+
+// Create implements ActivityClient
+func (f *FakeActivityClient) Create(ctx context.Context, req dto.CreateActivityRequest) (*dto.ActivityResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	startTime, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return nil, apperrors.Validation("Invalid start_time format")
+	}
+
+	f.nextID++
+	now := time.Now()
+	activity := &dto.ActivityResponse{
+		ID:           f.nextID,
+		UserID:       f.UserID,
+		Title:        req.Title,
+		StartTime:    startTime,
+		DurationMins: req.DurationMins,
+		Cost:         f.cost(req.Cost, req.Currency, nil),
+		Note:         req.Note,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	f.activities[activity.ID] = activity
+
+	out := *activity
+	return &out, nil
+}
+
+// List implements ActivityClient. Only page-based paging is supported.
+func (f *FakeActivityClient) List(ctx context.Context, opts ActivityListOptions) (*dto.ActivityListResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	var start, end time.Time
+	if opts.StartDate != "" {
+		start, _ = time.Parse(constants.DateFormat, opts.StartDate)
+	}
+	if opts.EndDate != "" {
+		end, _ = time.Parse(constants.DateFormat, opts.EndDate)
+		end = end.AddDate(0, 0, 1)
+	}
+
+	matched := []dto.ActivityResponse{}
+	for _, activity := range f.activities {
+		if !start.IsZero() && activity.StartTime.Before(start) {
+			continue
+		}
+		if !end.IsZero() && !activity.StartTime.Before(end) {
+			continue
+		}
+		matched = append(matched, *activity)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].StartTime.Equal(matched[j].StartTime) {
+			return matched[i].ID > matched[j].ID
+		}
+		return matched[i].StartTime.After(matched[j].StartTime)
+	})
+
+	page, pagination := fakePage(len(matched), opts.PageOptions)
+	return &dto.ActivityListResponse{Activities: matched[page[0]:page[1]], PaginationResponse: pagination}, nil
+}
+
+// Get implements ActivityClient
+func (f *FakeActivityClient) Get(ctx context.Context, id int64) (*dto.ActivityResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	activity, ok := f.activities[id]
+	if !ok {
+		return nil, apperrors.NotFound(constants.ErrActivityNotFound)
+	}
+	out := *activity
+	return &out, nil
+}
+
+// Update implements ActivityClient
+func (f *FakeActivityClient) Update(ctx context.Context, id int64, req dto.UpdateActivityRequest) (*dto.ActivityResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	activity, ok := f.activities[id]
+	if !ok {
+		return nil, apperrors.NotFound(constants.ErrActivityNotFound)
+	}
+	if req.StartTime != "" {
+		startTime, err := time.Parse(time.RFC3339, req.StartTime)
+		if err != nil {
+			return nil, apperrors.Validation("Invalid start_time format")
+		}
+		activity.StartTime = startTime
+	}
+	if req.Title != "" {
+		activity.Title = req.Title
+	}
+	if req.DurationMins > 0 {
+		activity.DurationMins = req.DurationMins
+	}
+	if req.Cost != nil {
+		activity.Cost = f.cost(req.Cost, req.Currency, activity.Cost)
+	}
+	if req.Note != "" {
+		activity.Note = req.Note
+	}
+	activity.UpdatedAt = time.Now()
+
+	out := *activity
+	return &out, nil
+}
+
+// cost builds an activity cost as the service does: a missing currency keeps the
+// current one, or falls back to the user's default
+func (f *FakeActivityClient) cost(amount *int64, currency string, current *money.Money) *money.Money {
+	if amount == nil {
+		return nil
+	}
+	if currency == "" && current != nil {
+		currency = current.Currency
+	}
+	if currency == "" {
+		currency = f.Currency
+	}
+	cost := money.New(*amount, currency)
+	return &cost
+}
+
+// Delete implements ActivityClient
+func (f *FakeActivityClient) Delete(ctx context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+
+	if _, ok := f.activities[id]; !ok {
+		return apperrors.NotFound(constants.ErrActivityNotFound)
+	}
+	delete(f.activities, id)
+	return nil
+}
+
+// FakeHabitClient is an in-memory HabitClient owned by UserID
+type FakeHabitClient struct {
+	UserID int64
+	Err    error
+
+	mu      sync.Mutex
+	habits  map[int64]*dto.HabitResponse
+	logs    map[int64]*dto.HabitLogResponse
+	nextID  int64
+	nextLog int64
+}
+
+// NewFakeHabitClient creates an empty fake habit client for userID
+func NewFakeHabitClient(userID int64) *FakeHabitClient {
+	return &FakeHabitClient{
+		UserID: userID,
+		habits: map[int64]*dto.HabitResponse{},
+		logs:   map[int64]*dto.HabitLogResponse{},
+	}
+}
+
+// Create implements HabitClient
+func (f *FakeHabitClient) Create(ctx context.Context, req dto.CreateHabitRequest) (*dto.HabitResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	startDate, err := time.Parse(constants.DateFormat, req.StartDate)
+	if err != nil {
+		return nil, apperrors.Validation("Invalid start_date format")
+	}
+	endDate, err := time.Parse(constants.DateFormat, req.EndDate)
+	if err != nil {
+		return nil, apperrors.Validation("Invalid end_date format")
+	}
+
+	f.nextID++
+	now := time.Now()
+	habit := &dto.HabitResponse{
+		ID:           f.nextID,
+		UserID:       f.UserID,
+		Title:        req.Title,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		ReminderTime: req.ReminderTime,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	f.habits[habit.ID] = habit
+
+	out := *habit
+	return &out, nil
+}
+
+// List implements HabitClient
+func (f *FakeHabitClient) List(ctx context.Context, activeOnly bool) ([]dto.HabitResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	habits := []dto.HabitResponse{}
+	for _, habit := range f.habits {
+		if activeOnly && (today.Before(habit.StartDate) || today.After(habit.EndDate)) {
+			continue
+		}
+		habits = append(habits, *habit)
+	}
+	sort.Slice(habits, func(i, j int) bool { return habits[i].ID > habits[j].ID })
+	return habits, nil
+}
+
+// Get implements HabitClient
+func (f *FakeHabitClient) Get(ctx context.Context, id int64) (*dto.HabitResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	habit, ok := f.habits[id]
+	if !ok {
+		return nil, apperrors.NotFound(constants.ErrHabitNotFound)
+	}
+	out := *habit
+	return &out, nil
+}
+
+// Update implements HabitClient
+func (f *FakeHabitClient) Update(ctx context.Context, id int64, req dto.UpdateHabitRequest) (*dto.HabitResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	habit, ok := f.habits[id]
+	if !ok {
+		return nil, apperrors.NotFound(constants.ErrHabitNotFound)
+	}
+	if req.Title != "" {
+		habit.Title = req.Title
+	}
+	if req.ReminderTime != "" {
+		habit.ReminderTime = req.ReminderTime
+	}
+	habit.UpdatedAt = time.Now()
+
+	out := *habit
+	return &out, nil
+}
+
+// Delete implements HabitClient; the habit's logs go with it
+func (f *FakeHabitClient) Delete(ctx context.Context, id int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+
+	if _, ok := f.habits[id]; !ok {
+		return apperrors.NotFound(constants.ErrHabitNotFound)
+	}
+	delete(f.habits, id)
+	for logID, log := range f.logs {
+		if log.HabitID == id {
+			delete(f.logs, logID)
+		}
+	}
+	return nil
+}
+
+// CreateLog implements HabitClient
+func (f *FakeHabitClient) CreateLog(ctx context.Context, req dto.CreateHabitLogRequest) (*dto.HabitLogResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	if _, ok := f.habits[req.HabitID]; !ok {
+		return nil, apperrors.NotFound(constants.ErrHabitNotFound)
+	}
+	date, err := time.Parse(constants.DateFormat, req.Date)
+	if err != nil {
+		return nil, apperrors.Validation("Invalid date format")
+	}
+	for _, log := range f.logs {
+		if log.HabitID == req.HabitID && log.Date.Equal(date) {
+			return nil, apperrors.Conflict("Habit log already exists for this date")
+		}
+	}
+
+	f.nextLog++
+	now := time.Now()
+	log := &dto.HabitLogResponse{
+		ID:        f.nextLog,
+		HabitID:   req.HabitID,
+		Date:      date,
+		Status:    req.Status,
+		Note:      req.Note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	f.logs[log.ID] = log
+
+	out := *log
+	return &out, nil
+}
+
+// ListLogs implements HabitClient. Only page-based paging is supported.
+func (f *FakeHabitClient) ListLogs(ctx context.Context, habitID int64, opts PageOptions) (*dto.HabitLogListResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	if _, ok := f.habits[habitID]; !ok {
+		return nil, apperrors.NotFound(constants.ErrHabitNotFound)
+	}
+	logs := f.logsFor(habitID)
+
+	page, pagination := fakePage(len(logs), opts)
+	return &dto.HabitLogListResponse{Logs: logs[page[0]:page[1]], PaginationResponse: pagination}, nil
+}
+
+// UpdateLog implements HabitClient
+func (f *FakeHabitClient) UpdateLog(ctx context.Context, logID int64, req dto.UpdateHabitLogRequest) (*dto.HabitLogResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	log, ok := f.logs[logID]
+	if !ok {
+		return nil, apperrors.NotFound(constants.ErrHabitLogNotFound)
+	}
+	if req.Status != "" {
+		log.Status = req.Status
+	}
+	if req.Note != "" {
+		log.Note = req.Note
+	}
+	log.UpdatedAt = time.Now()
+
+	out := *log
+	return &out, nil
+}
+
+// Stats implements HabitClient
+func (f *FakeHabitClient) Stats(ctx context.Context, habitID int64) (*dto.HabitStatsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	if _, ok := f.habits[habitID]; !ok {
+		return nil, apperrors.NotFound(constants.ErrHabitNotFound)
+	}
+	stats := fakeHabitStats(f.logsFor(habitID))
+	return &stats, nil
+}
+
+// GetWithLogs implements HabitClient
+func (f *FakeHabitClient) GetWithLogs(ctx context.Context, habitID int64) (*dto.HabitWithLogsResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	habit, ok := f.habits[habitID]
+	if !ok {
+		return nil, apperrors.NotFound(constants.ErrHabitNotFound)
+	}
+	logs := f.logsFor(habitID)
+	return &dto.HabitWithLogsResponse{Habit: *habit, Logs: logs, Stats: fakeHabitStats(logs)}, nil
+}
+
+// logsFor returns a habit's logs, newest first; f.mu must be held
+func (f *FakeHabitClient) logsFor(habitID int64) []dto.HabitLogResponse {
+	logs := []dto.HabitLogResponse{}
+	for _, log := range f.logs {
+		if log.HabitID == habitID {
+			logs = append(logs, *log)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Date.After(logs[j].Date) })
+	return logs
+}
+
+// fakeHabitStats computes totals and streaks from logs sorted newest first
+func fakeHabitStats(logs []dto.HabitLogResponse) dto.HabitStatsResponse {
+	stats := dto.HabitStatsResponse{TotalDays: len(logs)}
+	streak, counting := 0, true
+	for _, log := range logs {
+		switch log.Status {
+		case constants.HabitStatusDone:
+			stats.CompletedDays++
+			streak++
+			if streak > stats.LongestStreak {
+				stats.LongestStreak = streak
+			}
+			if counting {
+				stats.CurrentStreak = streak
+			}
+			continue
+		case constants.HabitStatusSkipped:
+			stats.SkippedDays++
+		case constants.HabitStatusFailed:
+			stats.FailedDays++
+		}
+		streak, counting = 0, false
+	}
+	if stats.TotalDays > 0 {
+		stats.SuccessRate = float64(stats.CompletedDays) / float64(stats.TotalDays) * 100
+	}
+	return stats
+}
+
+// fakePage returns the [start, end) bounds of the requested page and its pagination
+func fakePage(total int, opts PageOptions) ([2]int, dto.PaginationResponse) {
+	page, limit := opts.Page, opts.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = constants.DefaultPageLimit
+	}
+
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	return [2]int{start, end}, dto.PaginationResponse{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: (total + limit - 1) / limit,
+	}
+}
+
+// FakeStatClient returns the canned values it holds; nil values are returned as zero structs
+type FakeStatClient struct {
+	Err             error
+	DashboardStats  *dto.DashboardStats
+	Summary         *dto.ActivitySummary
+	Progress        *dto.HabitProgressSummary
+	ProgressDetails map[int64]*dto.HabitProgressDetail
+	Chart           *dto.ChartData
+	Expenses        *dto.ExpenseReport
+	Usage           *dto.UserUsage
+}
+
+// Dashboard implements StatClient
+func (f *FakeStatClient) Dashboard(ctx context.Context) (*dto.DashboardStats, error) {
+	return canned(f.Err, f.DashboardStats)
+}
+
+// ActivitySummary implements StatClient
+func (f *FakeStatClient) ActivitySummary(ctx context.Context, startDate, endDate string) (*dto.ActivitySummary, error) {
+	return canned(f.Err, f.Summary)
+}
+
+// HabitProgress implements StatClient
+func (f *FakeStatClient) HabitProgress(ctx context.Context) (*dto.HabitProgressSummary, error) {
+	return canned(f.Err, f.Progress)
+}
+
+// HabitProgressDetail implements StatClient; unknown habits are not found
+func (f *FakeStatClient) HabitProgressDetail(ctx context.Context, habitID int64) (*dto.HabitProgressDetail, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	detail, ok := f.ProgressDetails[habitID]
+	if !ok {
+		return nil, apperrors.NotFound(constants.ErrHabitNotFound)
+	}
+	return detail, nil
+}
+
+// ActivityChart implements StatClient
+func (f *FakeStatClient) ActivityChart(ctx context.Context, chartType string, period int) (*dto.ChartData, error) {
+	return canned(f.Err, f.Chart)
+}
+
+// ExpenseReport implements StatClient
+func (f *FakeStatClient) ExpenseReport(ctx context.Context, startDate, endDate, currency string) (*dto.ExpenseReport, error) {
+	return canned(f.Err, f.Expenses)
+}
+
+// UserUsage implements StatClient
+func (f *FakeStatClient) UserUsage(ctx context.Context, userID int64) (*dto.UserUsage, error) {
+	return canned(f.Err, f.Usage)
+}
+
+// FakeAIClient returns the canned values it holds; nil values are returned as zero structs
+type FakeAIClient struct {
+	Err            error
+	Summary        *dto.DailySummary
+	Summaries      []dto.DailySummary
+	Recommendation *dto.HabitRecommendationResponse
+	UserInsights   *dto.UserInsights
+	Analysis       *dto.ActivityAnalysisResponse
+	Tips           *dto.ProductivityTipsResponse
+}
+
+// DailySummary implements AIClient
+func (f *FakeAIClient) DailySummary(ctx context.Context, date string) (*dto.DailySummary, error) {
+	return canned(f.Err, f.Summary)
+}
+
+// DailySummaries implements AIClient
+func (f *FakeAIClient) DailySummaries(ctx context.Context) ([]dto.DailySummary, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	return f.Summaries, nil
+}
+
+// HabitRecommendation implements AIClient
+func (f *FakeAIClient) HabitRecommendation(ctx context.Context, days int) (*dto.HabitRecommendationResponse, error) {
+	return canned(f.Err, f.Recommendation)
+}
+
+// Insights implements AIClient
+func (f *FakeAIClient) Insights(ctx context.Context) (*dto.UserInsights, error) {
+	return canned(f.Err, f.UserInsights)
+}
+
+// AnalyzeActivities implements AIClient
+func (f *FakeAIClient) AnalyzeActivities(ctx context.Context, days int) (*dto.ActivityAnalysisResponse, error) {
+	return canned(f.Err, f.Analysis)
+}
+
+// ProductivityTips implements AIClient
+func (f *FakeAIClient) ProductivityTips(ctx context.Context) (*dto.ProductivityTipsResponse, error) {
+	return canned(f.Err, f.Tips)
+}
+
+// canned returns err, or value (a zero T when nil)
+func canned[T any](err error, value *T) (*T, error) {
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = new(T)
+	}
+	return value, nil
+}
+
+// Compile-time checks that the fakes satisfy the interfaces
+var (
+	_ UserClient     = (*FakeUserClient)(nil)
+	_ ActivityClient = (*FakeActivityClient)(nil)
+	_ HabitClient    = (*FakeHabitClient)(nil)
+	_ StatClient     = (*FakeStatClient)(nil)
+	_ AIClient       = (*FakeAIClient)(nil)
+)
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+)
+
+// HabitClient calls the habit service
+type HabitClient interface {
+	Create(ctx context.Context, req dto.CreateHabitRequest) (*dto.HabitResponse, error)
+	List(ctx context.Context, activeOnly bool) ([]dto.HabitResponse, error)
+	Get(ctx context.Context, id int64) (*dto.HabitResponse, error)
+	Update(ctx context.Context, id int64, req dto.UpdateHabitRequest) (*dto.HabitResponse, error)
+	Delete(ctx context.Context, id int64) error
+	CreateLog(ctx context.Context, req dto.CreateHabitLogRequest) (*dto.HabitLogResponse, error)
+	ListLogs(ctx context.Context, habitID int64, opts PageOptions) (*dto.HabitLogListResponse, error)
+	UpdateLog(ctx context.Context, logID int64, req dto.UpdateHabitLogRequest) (*dto.HabitLogResponse, error)
+	Stats(ctx context.Context, habitID int64) (*dto.HabitStatsResponse, error)
+	GetWithLogs(ctx context.Context, habitID int64) (*dto.HabitWithLogsResponse, error)
+}
+
+type habitClient struct {
+	base
+}
+
+// NewHabitClient creates a habit service client
+func NewHabitClient(opts Options) HabitClient {
+	return &habitClient{base: newBase(constants.HabitService, opts)}
+}
+
+func habitPath(id int64) string {
+	return "/api/v1/habits/" + strconv.FormatInt(id, 10)
+}
+
+// Create adds a habit for the calling user
+func (c *habitClient) Create(ctx context.Context, req dto.CreateHabitRequest) (*dto.HabitResponse, error) {
+	var out dto.HabitResponse
+	if err := c.call(ctx, http.MethodPost, "/api/v1/habits", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// List returns the calling user's habits, optionally only those still running
+func (c *habitClient) List(ctx context.Context, activeOnly bool) ([]dto.HabitResponse, error) {
+	var query url.Values
+	if activeOnly {
+		query = url.Values{"active": {"true"}}
+	}
+
+	var out []dto.HabitResponse
+	if err := c.call(ctx, http.MethodGet, "/api/v1/habits", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Get returns one of the calling user's habits
+func (c *habitClient) Get(ctx context.Context, id int64) (*dto.HabitResponse, error) {
+	var out dto.HabitResponse
+	if err := c.call(ctx, http.MethodGet, habitPath(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Update changes one of the calling user's habits
+func (c *habitClient) Update(ctx context.Context, id int64, req dto.UpdateHabitRequest) (*dto.HabitResponse, error) {
+	var out dto.HabitResponse
+	if err := c.call(ctx, http.MethodPut, habitPath(id), nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Delete removes one of the calling user's habits
+func (c *habitClient) Delete(ctx context.Context, id int64) error {
+	return c.call(ctx, http.MethodDelete, habitPath(id), nil, nil, nil)
+}
+
+// CreateLog records a day's outcome for req.HabitID
+func (c *habitClient) CreateLog(ctx context.Context, req dto.CreateHabitLogRequest) (*dto.HabitLogResponse, error) {
+	var out dto.HabitLogResponse
+	if err := c.call(ctx, http.MethodPost, habitPath(req.HabitID)+"/logs", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListLogs returns a page of a habit's logs, newest first
+func (c *habitClient) ListLogs(ctx context.Context, habitID int64, opts PageOptions) (*dto.HabitLogListResponse, error) {
+	env, err := c.do(ctx, http.MethodGet, habitPath(habitID)+"/logs", opts.values(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := dto.HabitLogListResponse{Logs: []dto.HabitLogResponse{}}
+	if err := decodeData(env.Data, &out.Logs); err != nil {
+		return nil, err
+	}
+	if err := decodeData(env.Pagination, &out.PaginationResponse); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateLog changes a habit log
+func (c *habitClient) UpdateLog(ctx context.Context, logID int64, req dto.UpdateHabitLogRequest) (*dto.HabitLogResponse, error) {
+	var out dto.HabitLogResponse
+	if err := c.call(ctx, http.MethodPut, "/api/v1/habit-logs/"+strconv.FormatInt(logID, 10), nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Stats returns completion statistics for a habit
+func (c *habitClient) Stats(ctx context.Context, habitID int64) (*dto.HabitStatsResponse, error) {
+	var out dto.HabitStatsResponse
+	if err := c.call(ctx, http.MethodGet, habitPath(habitID)+"/stats", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWithLogs returns a habit together with all its logs and statistics
+func (c *habitClient) GetWithLogs(ctx context.Context, habitID int64) (*dto.HabitWithLogsResponse, error) {
+	var out dto.HabitWithLogsResponse
+	if err := c.call(ctx, http.MethodGet, habitPath(habitID)+"/complete", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+)
+
+// StatClient calls the stat service. Dates are YYYY-MM-DD; empty dates use the
+// service defaults.
+type StatClient interface {
+	Dashboard(ctx context.Context) (*dto.DashboardStats, error)
+	ActivitySummary(ctx context.Context, startDate, endDate string) (*dto.ActivitySummary, error)
+	HabitProgress(ctx context.Context) (*dto.HabitProgressSummary, error)
+	HabitProgressDetail(ctx context.Context, habitID int64) (*dto.HabitProgressDetail, error)
+	ActivityChart(ctx context.Context, chartType string, period int) (*dto.ChartData, error)
+	// ExpenseReport groups spending by currency, or converts it all into currency when set
+	ExpenseReport(ctx context.Context, startDate, endDate, currency string) (*dto.ExpenseReport, error)
+	// UserUsage needs the usage:read permission
+	UserUsage(ctx context.Context, userID int64) (*dto.UserUsage, error)
+}
+
+type statClient struct {
+	base
+}
+
+// NewStatClient creates a stat service client
+func NewStatClient(opts Options) StatClient {
+	return &statClient{base: newBase(constants.StatService, opts)}
+}
+
+// dateRange encodes an optional date range as query parameters
+func dateRange(startDate, endDate string) url.Values {
+	query := url.Values{}
+	if startDate != "" {
+		query.Set("start_date", startDate)
+	}
+	if endDate != "" {
+		query.Set("end_date", endDate)
+	}
+	return query
+}
+
+// Dashboard returns the calling user's overview statistics
+func (c *statClient) Dashboard(ctx context.Context) (*dto.DashboardStats, error) {
+	var out dto.DashboardStats
+	if err := c.call(ctx, http.MethodGet, "/api/v1/stats/dashboard", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ActivitySummary returns activity totals for a date range
+func (c *statClient) ActivitySummary(ctx context.Context, startDate, endDate string) (*dto.ActivitySummary, error) {
+	var out dto.ActivitySummary
+	if err := c.call(ctx, http.MethodGet, "/api/v1/stats/activities/summary", dateRange(startDate, endDate), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HabitProgress returns progress across all of the calling user's habits
+func (c *statClient) HabitProgress(ctx context.Context) (*dto.HabitProgressSummary, error) {
+	var out dto.HabitProgressSummary
+	if err := c.call(ctx, http.MethodGet, "/api/v1/stats/habits/progress", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HabitProgressDetail returns progress for a single habit
+func (c *statClient) HabitProgressDetail(ctx context.Context, habitID int64) (*dto.HabitProgressDetail, error) {
+	query := url.Values{"habit_id": {strconv.FormatInt(habitID, 10)}}
+
+	var out dto.HabitProgressDetail
+	if err := c.call(ctx, http.MethodGet, "/api/v1/stats/habits/progress", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ActivityChart returns chart points; chartType and period fall back to daily and 7
+func (c *statClient) ActivityChart(ctx context.Context, chartType string, period int) (*dto.ChartData, error) {
+	query := url.Values{}
+	if chartType != "" {
+		query.Set("type", chartType)
+	}
+	if period > 0 {
+		query.Set("period", strconv.Itoa(period))
+	}
+
+	var out dto.ChartData
+	if err := c.call(ctx, http.MethodGet, "/api/v1/stats/activities/chart", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExpenseReport returns spending for a date range
+func (c *statClient) ExpenseReport(ctx context.Context, startDate, endDate, currency string) (*dto.ExpenseReport, error) {
+	query := dateRange(startDate, endDate)
+	if currency != "" {
+		query.Set("currency", currency)
+	}
+
+	var out dto.ExpenseReport
+	if err := c.call(ctx, http.MethodGet, "/api/v1/stats/expenses/report", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UserUsage returns how much of the app any user has used
+func (c *statClient) UserUsage(ctx context.Context, userID int64) (*dto.UserUsage, error) {
+	var out dto.UserUsage
+	if err := c.call(ctx, http.MethodGet, "/api/v1/stats/users/"+strconv.FormatInt(userID, 10)+"/usage", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+)
+
+// UserClient calls the user service
+type UserClient interface {
+	Register(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error)
+	Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error)
+	GetProfile(ctx context.Context) (*dto.UserResponse, error)
+	UpdateProfile(ctx context.Context, req dto.UpdateProfileRequest) (*dto.UserResponse, error)
+	ChangePassword(ctx context.Context, req dto.ChangePasswordRequest) error
+	DeleteAccount(ctx context.Context, req dto.DeleteAccountRequest) (*dto.AccountDeletionResponse, error)
+	GetUser(ctx context.Context, id int64) (*dto.UserResponse, error)
+	GetSettings(ctx context.Context) (*dto.UserSettings, error)
+	UpdateSettings(ctx context.Context, req dto.UpdateSettingsRequest) (*dto.UserSettings, error)
+}
+
+type userClient struct {
+	base
+}
+
+// NewUserClient creates a user service client
+func NewUserClient(opts Options) UserClient {
+	return &userClient{base: newBase(constants.UserService, opts)}
+}
+
+// Register creates an account and returns its token
+func (c *userClient) Register(ctx context.Context, req dto.RegisterRequest) (*dto.AuthResponse, error) {
+	var out dto.AuthResponse
+	if err := c.call(ctx, http.MethodPost, "/auth/register", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Login exchanges credentials for a token
+func (c *userClient) Login(ctx context.Context, req dto.LoginRequest) (*dto.AuthResponse, error) {
+	var out dto.AuthResponse
+	if err := c.call(ctx, http.MethodPost, "/auth/login", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetProfile returns the calling user's profile
+func (c *userClient) GetProfile(ctx context.Context) (*dto.UserResponse, error) {
+	var out dto.UserResponse
+	if err := c.call(ctx, http.MethodGet, "/api/v1/users/profile", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateProfile changes the calling user's profile
+func (c *userClient) UpdateProfile(ctx context.Context, req dto.UpdateProfileRequest) (*dto.UserResponse, error) {
+	var out dto.UserResponse
+	if err := c.call(ctx, http.MethodPut, "/api/v1/users/profile", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ChangePassword changes the calling user's password
+func (c *userClient) ChangePassword(ctx context.Context, req dto.ChangePasswordRequest) error {
+	return c.call(ctx, http.MethodPut, "/api/v1/users/password", nil, req, nil)
+}
+
+// DeleteAccount schedules the calling user's account for deletion and returns the deletion record
+func (c *userClient) DeleteAccount(ctx context.Context, req dto.DeleteAccountRequest) (*dto.AccountDeletionResponse, error) {
+	var out dto.AccountDeletionResponse
+	if err := c.call(ctx, http.MethodDelete, "/api/v1/users/account", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetUser returns a user by ID
+func (c *userClient) GetUser(ctx context.Context, id int64) (*dto.UserResponse, error) {
+	var out dto.UserResponse
+	if err := c.call(ctx, http.MethodGet, "/api/v1/users/"+strconv.FormatInt(id, 10), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSettings returns the calling user's settings
+func (c *userClient) GetSettings(ctx context.Context) (*dto.UserSettings, error) {
+	var out dto.UserSettings
+	if err := c.call(ctx, http.MethodGet, "/api/v1/users/settings", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateSettings changes the calling user's settings
+func (c *userClient) UpdateSettings(ctx context.Context, req dto.UpdateSettingsRequest) (*dto.UserSettings, error) {
+	var out dto.UserSettings
+	if err := c.call(ctx, http.MethodPatch, "/api/v1/users/settings", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
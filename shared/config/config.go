@@ -2,9 +2,12 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +20,20 @@ type Config struct {
 	DBPassword string
 	DBName     string
 
+	// Database connection pool
+	DBMaxOpenConns    int
+	DBMaxIdleConns    int
+	DBConnMaxLifetime time.Duration
+	DBConnMaxIdleTime time.Duration
+
+	// DBTimesUTC confirms that times stored before connections switched to UTC
+	// were UTC already or have been converted; see database.Migrate
+	DBTimesUTC bool
+
+	// Database read replicas (optional)
+	DBReplicaDSNs           []string
+	DBReplicaHealthInterval time.Duration
+
 	// Service Ports
 	GatewayPort      string
 	UserServicePort  string
@@ -41,6 +58,45 @@ type Config struct {
 	SMTPPort     string
 	SMTPUser     string
 	SMTPPassword string
+	SMTPFrom     string
+
+	// Public URL of the gateway, used for links in emails
+	PublicURL string
+
+	// Email verification
+	EmailVerificationTTL     time.Duration
+	VerificationResendPeriod time.Duration
+	UnverifiedPolicy         string // allow, read_only or block
+
+	// Password reset
+	PasswordResetTTL          time.Duration
+	PasswordResetResendPeriod time.Duration
+
+	// Two-factor authentication
+	TOTPEncryptionKey string // seals stored TOTP secrets; derived from JWTSecret when empty
+	MFAChallengeTTL   time.Duration
+
+	// Brute-force protection
+	LoginMaxFailures     int           // failed passwords in a row before an account locks
+	LoginLockoutDuration time.Duration // also how long the emailed unlock link works
+	LoginDelayAfter      int           // failures in a row before each attempt has to wait
+	LoginDelayBase       time.Duration // first wait, doubling with every further failure
+	LoginMaxDelay        time.Duration
+	LoginIPMaxFailures   int // failed logins from one IP within LoginIPWindow before it is blocked
+	LoginIPWindow        time.Duration
+
+	// Administration
+	AdminEmails []string // verified accounts granted the admin role when they sign in
+
+	// Account deletion
+	AccountDeletionGracePeriod time.Duration // how long a deleted account can still be restored by signing in
+
+	// Personal data exports
+	ExportDir     string        // where finished export archives are kept
+	ExportLinkTTL time.Duration // how long an archive can be downloaded
+
+	// OpenID Connect sign-in providers
+	OIDCProviders []OIDCProvider
 
 	// WhatsApp (Optional)
 	WhatsAppAPIURL   string
@@ -53,13 +109,37 @@ type Config struct {
 
 	// Environment
 	Environment string
+
+	// Lifecycle
+	ShutdownTimeout time.Duration
+
+	// Logging
+	LogLevel  string // debug, info, warn, error
+	LogFormat string // text or json
+
+	// Tracing
+	TraceExporter    string // none or otlp
+	OTLPEndpoint     string // host:port of the OTLP/HTTP collector
+	OTLPInsecure     bool
+	TraceSampleRatio float64
+
+	// Domain events
+	EventTransport      string // inprocess, redis or nats
+	NATSURL             string
+	EventRelayInterval  time.Duration
+	EventRelayBatchSize int
+
+	// Feature flags
+	FlagsSource         string // file or db
+	FlagsFile           string
+	FlagsReloadInterval time.Duration
 }
 
 // LoadConfig loads configuration from environment variables
 func LoadConfig() *Config {
 	// Load .env file if it exists
 	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
+		slog.Debug("no .env file found, using system environment variables")
 	}
 
 	// Force set GO111MODULE if specified in .env
@@ -75,6 +155,18 @@ func LoadConfig() *Config {
 		DBPassword: getEnv("DB_PASSWORD", "password"), // MySQL default (no password)
 		DBName:     getEnv("DB_NAME", "dailytrackr"),
 
+		// Database connection pool
+		DBMaxOpenConns:    getEnvAsInt("DB_MAX_OPEN_CONNS", 25),
+		DBMaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 25),
+		DBConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+		DBConnMaxIdleTime: getEnvAsDuration("DB_CONN_MAX_IDLE_TIME", 2*time.Minute),
+
+		DBTimesUTC: getEnvAsBool("DB_TIMES_UTC", false),
+
+		// Database read replicas
+		DBReplicaDSNs:           getEnvAsSlice("DB_REPLICA_DSNS"),
+		DBReplicaHealthInterval: getEnvAsDuration("DB_REPLICA_HEALTH_INTERVAL", 10*time.Second),
+
 		// Service Ports
 		GatewayPort:      getEnv("GATEWAY_PORT", "3000"),
 		UserServicePort:  getEnv("USER_SERVICE_PORT", "3001"),
@@ -99,6 +191,45 @@ func LoadConfig() *Config {
 		SMTPPort:     getEnv("SMTP_PORT", "587"),
 		SMTPUser:     getEnv("SMTP_USER", ""),
 		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "DailyTrackr <no-reply@dailytrackr.dev>"),
+
+		// Public URL
+		PublicURL: strings.TrimSuffix(getEnv("PUBLIC_URL", "http://localhost:3000"), "/"),
+
+		// Email verification
+		EmailVerificationTTL:     getEnvAsDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		VerificationResendPeriod: getEnvAsDuration("VERIFICATION_RESEND_PERIOD", time.Minute),
+		UnverifiedPolicy:         getEnv("UNVERIFIED_POLICY", "read_only"),
+
+		// Password reset
+		PasswordResetTTL:          getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+		PasswordResetResendPeriod: getEnvAsDuration("PASSWORD_RESET_RESEND_PERIOD", time.Minute),
+
+		// Two-factor authentication
+		TOTPEncryptionKey: getEnv("TOTP_ENCRYPTION_KEY", ""),
+		MFAChallengeTTL:   getEnvAsDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+
+		// Brute-force protection
+		LoginMaxFailures:     getEnvAsInt("LOGIN_MAX_FAILURES", 5),
+		LoginLockoutDuration: getEnvAsDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		LoginDelayAfter:      getEnvAsInt("LOGIN_DELAY_AFTER", 3),
+		LoginDelayBase:       getEnvAsDuration("LOGIN_DELAY_BASE", time.Second),
+		LoginMaxDelay:        getEnvAsDuration("LOGIN_MAX_DELAY", 30*time.Second),
+		LoginIPMaxFailures:   getEnvAsInt("LOGIN_IP_MAX_FAILURES", 20),
+		LoginIPWindow:        getEnvAsDuration("LOGIN_IP_WINDOW", 15*time.Minute),
+
+		// Administration
+		AdminEmails: getEnvAsSlice("ADMIN_EMAILS"),
+
+		// Account deletion
+		AccountDeletionGracePeriod: getEnvAsDuration("ACCOUNT_DELETION_GRACE_PERIOD", 14*24*time.Hour),
+
+		// Personal data exports
+		ExportDir:     getEnv("EXPORT_DIR", filepath.Join(os.TempDir(), "dailytrackr-exports")),
+		ExportLinkTTL: getEnvAsDuration("EXPORT_LINK_TTL", 24*time.Hour),
+
+		// OpenID Connect
+		OIDCProviders: getOIDCProviders(),
 
 		// WhatsApp
 		WhatsAppAPIURL:   getEnv("WHATSAPP_API_URL", ""),
@@ -111,11 +242,70 @@ func LoadConfig() *Config {
 
 		// Environment
 		Environment: getEnv("ENV", "development"),
+
+		// Lifecycle
+		ShutdownTimeout: getEnvAsDuration("SHUTDOWN_TIMEOUT", 15*time.Second),
+
+		// Logging
+		LogLevel:  getEnv("LOG_LEVEL", "info"),
+		LogFormat: getEnv("LOG_FORMAT", "text"),
+
+		// Tracing
+		TraceExporter:    getEnv("TRACE_EXPORTER", "none"),
+		OTLPEndpoint:     getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318"),
+		OTLPInsecure:     getEnvAsBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+		TraceSampleRatio: getEnvAsFloat("TRACE_SAMPLE_RATIO", 1.0),
+
+		// Domain events
+		EventTransport:      getEnv("EVENT_TRANSPORT", "inprocess"),
+		NATSURL:             getEnv("NATS_URL", "nats://localhost:4222"),
+		EventRelayInterval:  getEnvAsDuration("EVENT_RELAY_INTERVAL", time.Second),
+		EventRelayBatchSize: getEnvAsInt("EVENT_RELAY_BATCH_SIZE", 100),
+
+		// Feature flags
+		FlagsSource:         getEnv("FLAGS_SOURCE", "file"),
+		FlagsFile:           getEnv("FLAGS_FILE", "flags.json"),
+		FlagsReloadInterval: getEnvAsDuration("FLAGS_RELOAD_INTERVAL", 30*time.Second),
 	}
 
 	return config
 }
 
+// OIDCProvider is an OpenID Connect identity provider users can sign in with.
+// Its callback is PUBLIC_URL/auth/oidc/<name>/callback.
+type OIDCProvider struct {
+	Name         string // path segment, e.g. "google"
+	IssuerURL    string // discovery happens at <issuer>/.well-known/openid-configuration
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// getOIDCProviders reads the providers named in OIDC_PROVIDERS (e.g. "google,mock"),
+// each configured by OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET and optional _SCOPES
+func getOIDCProviders() []OIDCProvider {
+	var providers []OIDCProvider
+	for _, name := range getEnvAsSlice("OIDC_PROVIDERS") {
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProvider{
+			Name:         strings.ToLower(name),
+			IssuerURL:    getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       getEnvAsSlice(prefix + "SCOPES"),
+		}
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			slog.Warn("skipping OIDC provider without issuer or client id", "provider", name)
+			continue
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"openid", "email", "profile"}
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -134,10 +324,52 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsBool gets an environment variable as boolean or returns a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsFloat gets an environment variable as float or returns a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsDuration gets an environment variable as duration (e.g. "30s", "5m") or returns a default value
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsSlice gets a comma-separated environment variable as a slice of trimmed values
+func getEnvAsSlice(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // GetMySQLDSN returns the MySQL connection string
 func (c *Config) GetMySQLDSN() string {
 	// MySQL DSN format: username:password@tcp(host:port)/database
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+	// Timestamps are read and written in UTC; users' day boundaries are applied per query
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC&time_zone=%%27%%2B00%%3A00%%27",
 		c.DBUser,
 		c.DBPassword,
 		c.DBHost,
@@ -54,14 +54,19 @@ const (
 const (
 	DefaultJWTExpireHours = 24
 	DefaultPageLimit      = 20
+	MaxPageLimit          = 100
 	DefaultPageOffset     = 0
 )
 
 // HTTP Headers
 const (
-	AuthorizationHeader = "Authorization"
-	ContentTypeHeader   = "Content-Type"
-	BearerPrefix        = "Bearer "
+	AuthorizationHeader   = "Authorization"
+	ContentTypeHeader     = "Content-Type"
+	BearerPrefix          = "Bearer "
+	RequestIDHeader       = "X-Request-ID"
+	AcceptLanguageHeader  = "Accept-Language"
+	ContentLanguageHeader = "Content-Language"
+	DeviceNameHeader      = "X-Device-Name" // optional label for the session a login starts
 )
 
 // Error Messages - General
@@ -72,6 +77,10 @@ const (
 	ErrUnauthorizedAccess = "unauthorized access to resource"
 	ErrInvalidRequestBody = "invalid request body"
 	ErrDatabaseConnection = "database connection error"
+	ErrValidationFailed   = "validation failed"
+	ErrUnexpected         = "an unexpected error occurred"
+	ErrInvalidCursor      = "invalid pagination cursor"
+	ErrRouteNotFound      = "route not found"
 )
 
 // Error Messages - User Related
@@ -88,6 +97,33 @@ const (
 	ErrPhotoServiceUnavailable = "photo upload service is not available"
 	ErrInvalidBio              = "bio contains invalid content"
 	ErrReservedUsername        = "username is reserved and cannot be used"
+	ErrEmailNotVerified        = "verify your email address to use this feature"
+	ErrInvalidVerifyToken      = "verification link is invalid or has expired"
+	ErrEmailAlreadyVerified    = "email address is already verified"
+	ErrVerificationThrottled   = "a verification email was sent recently, please wait before requesting another"
+	ErrEmailDeliveryFailed     = "failed to send email"
+	ErrInvalidResetToken       = "password reset link is invalid or has expired"
+	ErrTwoFactorEnabled        = "two-factor authentication is already enabled"
+	ErrTwoFactorNotEnabled     = "two-factor authentication is not enabled"
+	ErrTwoFactorNotEnrolled    = "start two-factor enrollment before confirming it"
+	ErrInvalidTwoFactorCode    = "invalid two-factor code"
+	ErrInvalidMFAChallenge     = "sign-in challenge is invalid or has expired, please sign in again"
+	ErrOIDCProviderNotFound    = "sign-in provider not found"
+	ErrOIDCProviderUnavailable = "sign-in provider is not available"
+	ErrOIDCLoginFailed         = "external sign-in failed, please try again"
+	ErrOIDCEmailRequired       = "the sign-in provider did not share an email address"
+	ErrOIDCEmailUnverified     = "an account already uses this email; verify it with your provider or sign in with your password"
+	ErrOIDCAccountUnverified   = "an account with this email has not verified it yet; reset its password and verify the email before signing in with this provider"
+	ErrSessionNotFound         = "session not found"
+	ErrLoginThrottled          = "too many failed sign-in attempts, please wait before trying again"
+	ErrAccountLocked           = "account temporarily locked after too many failed sign-in attempts; check your email to unlock it"
+	ErrInvalidUnlockToken      = "unlock link is invalid or has expired"
+	ErrPermissionDenied        = "you do not have permission to do this"
+	ErrAccountDisabled         = "this account has been disabled"
+	ErrInvalidRole             = "unknown role"
+	ErrOwnAccount              = "you cannot do this to your own account"
+	ErrInvalidExportLink       = "download link is invalid or has expired"
+	ErrDeletionNotFound        = "deletion record not found"
 )
 
 // Error Messages - Activity Related
@@ -101,6 +137,12 @@ const (
 	ErrHabitLogNotFound = "habit log not found"
 )
 
+// Error Messages - Statistics Related
+const (
+	ErrInvalidCurrency = "unsupported currency code"
+	ErrNoExchangeRate  = "no exchange rate available to convert into the requested currency"
+)
+
 // Success Messages - User Related
 const (
 	MsgUserCreated          = "user created successfully"
@@ -110,22 +152,76 @@ const (
 	MsgProfilePhotoUploaded = "profile photo uploaded successfully"
 	MsgAccountDeleted       = "account deleted successfully"
 	MsgProfileRetrieved     = "profile retrieved successfully"
+	MsgSettingsRetrieved    = "settings retrieved successfully"
+	MsgSettingsUpdated      = "settings updated, other services pick them up on your next sign-in"
+	MsgUserRetrieved        = "user retrieved successfully"
+	MsgEmailVerified        = "email verified successfully"
+	MsgEmailVerifiedSignIn  = "email verified successfully, sign in again to refresh your session"
+	MsgVerificationSent     = "verification email sent"
+	MsgPasswordResetSent    = "if an account exists for that email, a password reset link has been sent"
+	MsgPasswordReset        = "password reset successfully, sign in with your new password"
+	MsgTwoFactorEnrollment  = "scan the QR code with your authenticator app, then confirm with a code"
+	MsgTwoFactorEnabled     = "two-factor authentication enabled, store your recovery codes somewhere safe"
+	MsgTwoFactorDisabled    = "two-factor authentication disabled"
+	MsgMFARequired          = "enter the code from your authenticator app to finish signing in"
+	MsgSessionsRetrieved    = "sessions retrieved successfully"
+	MsgSessionRevoked       = "session signed out"
+	MsgOtherSessionsRevoked = "signed out of all other sessions"
+	MsgAccountUnlocked      = "account unlocked, you can sign in again"
+	MsgExportPending        = "your data export is being prepared, check back shortly"
+	MsgExportReady          = "your data export is ready to download"
+	MsgExportFailed         = "your data export failed, request it again with refresh=true to retry"
+	MsgDeletionScheduled    = "account scheduled for deletion; sign in before then to cancel it"
+	MsgDeletionRetrieved    = "deletion record retrieved successfully"
+	MsgUsersRetrieved       = "users retrieved successfully"
+	MsgAccountDisabled      = "account disabled and signed out everywhere"
+	MsgAccountEnabled       = "account enabled"
+	MsgTwoFactorReset       = "two-factor authentication reset"
+	MsgRolesUpdated         = "roles updated, they apply once the user signs in again"
 )
 
 // Success Messages - Activity Related
 const (
-	MsgActivityCreated = "activity created successfully"
-	MsgActivityUpdated = "activity updated successfully"
-	MsgActivityDeleted = "activity deleted successfully"
+	MsgActivityCreated     = "activity created successfully"
+	MsgActivityUpdated     = "activity updated successfully"
+	MsgActivityDeleted     = "activity deleted successfully"
+	MsgActivityRetrieved   = "activity retrieved successfully"
+	MsgActivitiesRetrieved = "activities retrieved successfully"
 )
 
 // Success Messages - Habit Related
 const (
-	MsgHabitCreated    = "habit created successfully"
-	MsgHabitUpdated    = "habit updated successfully"
-	MsgHabitDeleted    = "habit deleted successfully"
-	MsgHabitLogCreated = "habit log created successfully"
-	MsgHabitLogUpdated = "habit log updated successfully"
+	MsgHabitCreated          = "habit created successfully"
+	MsgHabitUpdated          = "habit updated successfully"
+	MsgHabitDeleted          = "habit deleted successfully"
+	MsgHabitLogCreated       = "habit log created successfully"
+	MsgHabitLogUpdated       = "habit log updated successfully"
+	MsgHabitRetrieved        = "habit retrieved successfully"
+	MsgHabitsRetrieved       = "habits retrieved successfully"
+	MsgHabitLogsRetrieved    = "habit logs retrieved successfully"
+	MsgHabitStatsRetrieved   = "habit statistics retrieved successfully"
+	MsgHabitDetailsRetrieved = "habit with logs and stats retrieved successfully"
+)
+
+// Success Messages - Statistics Related
+const (
+	MsgDashboardRetrieved       = "dashboard statistics retrieved successfully"
+	MsgActivitySummaryRetrieved = "activity summary retrieved successfully"
+	MsgHabitProgressRetrieved   = "habit progress retrieved successfully"
+	MsgChartDataRetrieved       = "activity chart data retrieved successfully"
+	MsgExpenseReportRetrieved   = "expense report retrieved successfully"
+	MsgUsageRetrieved           = "usage retrieved successfully"
+)
+
+// Success Messages - AI Related
+const (
+	MsgDailySummaryCached        = "daily summary retrieved from cache"
+	MsgDailySummaryGenerated     = "daily summary generated successfully"
+	MsgDailySummariesRetrieved   = "daily summaries retrieved successfully"
+	MsgRecommendationGenerated   = "habit recommendation generated successfully"
+	MsgInsightsRetrieved         = "user insights retrieved successfully"
+	MsgAnalysisCompleted         = "activity analysis completed successfully"
+	MsgProductivityTipsGenerated = "productivity tips generated successfully"
 )
 
 // Success Messages - General
@@ -150,6 +246,24 @@ const (
 	NotificationWeeklyReport  = "weekly_report"
 )
 
+// Email Templates - subject and body pairs rendered through the i18n catalogue
+const (
+	EmailVerifySubject = "email_verify_subject"
+	EmailVerifyBody    = "email_verify_body"
+	EmailResetSubject  = "email_reset_subject"
+	EmailResetBody     = "email_reset_body"
+	EmailLockedSubject = "email_locked_subject"
+	EmailLockedBody    = "email_locked_body"
+)
+
+// Unverified Email Policies - what accounts with an unverified email may do
+// in the other services; the user service itself always stays usable
+const (
+	UnverifiedPolicyAllow    = "allow"
+	UnverifiedPolicyReadOnly = "read_only"
+	UnverifiedPolicyBlock    = "block"
+)
+
 // AI Service Types
 const (
 	AITypeDailySummary        = "daily_summary"
@@ -163,6 +277,46 @@ const (
 	DateTimeFormat = "2006-01-02T15:04:05Z"
 )
 
+// Time Zones - timestamps are stored in UTC and shown in the user's zone
+const (
+	DefaultTimezone = "UTC"
+)
+
+// Currencies - amounts are stored in minor units with an ISO 4217 code
+const (
+	DefaultCurrency = "IDR"
+)
+
+// Languages
+const (
+	LanguageEnglish    = "en"
+	LanguageIndonesian = "id"
+	DefaultLanguage    = LanguageEnglish
+)
+
+// Week Start Days - the day weeks begin on in statistics
+const (
+	WeekStartMonday   = "monday"
+	WeekStartSunday   = "sunday"
+	WeekStartSaturday = "saturday"
+	DefaultWeekStart  = WeekStartMonday
+)
+
+// Themes
+const (
+	ThemeSystem  = "system"
+	ThemeLight   = "light"
+	ThemeDark    = "dark"
+	DefaultTheme = ThemeSystem
+)
+
+// Notification Defaults - what users get until they choose otherwise
+const (
+	DefaultHabitReminders = true
+	DefaultWeeklySummary  = true
+	DefaultProductUpdates = false
+)
+
 // User Profile Validation
 const (
 	MinUsernameLength = 3
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dailytrackr/shared/config"
+)
+
+// replicaPingTimeout bounds a single replica health check
+const replicaPingTimeout = 2 * time.Second
+
+// DB wraps the primary connection pool and optional read replicas.
+// The embedded *sql.DB is the primary and should be used for all writes;
+// read-only repository calls go through Reader().
+type DB struct {
+	*sql.DB
+
+	replicas []*replica
+	next     uint32
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// replica is a read replica connection pool with its last known health
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// NewDB connects to the primary and every configured read replica.
+// Replicas that cannot be reached at startup are kept but marked unhealthy,
+// so reads fall back to the primary until the health checker sees them recover.
+func NewDB(cfg *config.Config) (*DB, error) {
+	primary, err := GetMySQLConnection(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DB{
+		DB:   primary,
+		stop: make(chan struct{}),
+	}
+
+	for i, dsn := range cfg.DBReplicaDSNs {
+		conn, err := openMySQL(dsn)
+		if err != nil {
+			d.Close()
+			return nil, fmt.Errorf("error opening read replica %d: %v", i+1, err)
+		}
+		configurePool(conn, cfg)
+
+		r := &replica{name: fmt.Sprintf("replica-%d", i+1), db: conn}
+		r.check()
+		if !r.healthy.Load() {
+			slog.Warn("read replica unreachable, reads will use the primary", "replica", r.name)
+		}
+		d.replicas = append(d.replicas, r)
+	}
+
+	if len(d.replicas) > 0 {
+		slog.Info("read routing enabled", "replicas", len(d.replicas))
+		d.wg.Add(1)
+		go d.monitorReplicas(cfg.DBReplicaHealthInterval)
+	}
+
+	return d, nil
+}
+
+// Reader returns a connection pool for read-only queries.
+// Healthy replicas are used in round-robin order; when none are healthy
+// (or none are configured) the primary is returned.
+func (d *DB) Reader() *sql.DB {
+	n := len(d.replicas)
+	if n == 0 {
+		return d.DB
+	}
+
+	start := atomic.AddUint32(&d.next, 1)
+	for i := 0; i < n; i++ {
+		r := d.replicas[(int(start)+i)%n]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+
+	return d.DB
+}
+
+// UnhealthyReplicas returns the names of replicas currently failing their health check
+func (d *DB) UnhealthyReplicas() []string {
+	var names []string
+	for _, r := range d.replicas {
+		if !r.healthy.Load() {
+			names = append(names, r.name)
+		}
+	}
+	return names
+}
+
+// Stats returns pool statistics for the primary and each replica, keyed by name
+func (d *DB) Stats() map[string]sql.DBStats {
+	stats := map[string]sql.DBStats{"primary": d.DB.Stats()}
+	for _, r := range d.replicas {
+		stats[r.name] = r.db.Stats()
+	}
+	return stats
+}
+
+// Close stops the replica health checker and closes every connection pool
+func (d *DB) Close() error {
+	d.stopOnce.Do(func() { close(d.stop) })
+	d.wg.Wait()
+
+	for _, r := range d.replicas {
+		r.db.Close()
+	}
+	return d.DB.Close()
+}
+
+// monitorReplicas periodically pings replicas and updates their health
+func (d *DB) monitorReplicas(interval time.Duration) {
+	defer d.wg.Done()
+
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			for _, r := range d.replicas {
+				wasHealthy := r.healthy.Load()
+				r.check()
+				if healthy := r.healthy.Load(); healthy != wasHealthy {
+					if healthy {
+						slog.Info("read replica recovered", "replica", r.name)
+					} else {
+						slog.Warn("read replica unhealthy, falling back", "replica", r.name)
+					}
+				}
+			}
+		}
+	}
+}
+
+// check pings the replica and records the result
+func (r *replica) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+	defer cancel()
+	r.healthy.Store(r.db.PingContext(ctx) == nil)
+}
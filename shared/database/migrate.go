@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"dailytrackr/shared/config"
+)
+
+// migrationLockTimeout bounds how long a service waits for another instance's migrations (seconds)
+const migrationLockTimeout = 30
+
+// utcMigrationID records that the database's stored times are known to be UTC
+const utcMigrationID = "database/utc_timestamps"
+
+// dataTables are checked for rows written before connections switched to UTC
+var dataTables = []string{"users", "activities"}
+
+// Migration is a single schema change, applied once per database.
+// Services share one database, so IDs are prefixed with the owning service.
+type Migration struct {
+	ID  string
+	SQL string
+}
+
+// Migrate applies the migrations that have not run yet, in order.
+// A MySQL named lock keeps concurrent instances from applying the same change twice.
+func Migrate(ctx context.Context, db *sql.DB, cfg *config.Config, migrations []Migration) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("error acquiring migration connection: %v", err)
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK('dailytrackr_migrations', ?)", migrationLockTimeout).Scan(&locked); err != nil {
+		return fmt.Errorf("error acquiring migration lock: %v", err)
+	}
+	if locked.Int64 != 1 {
+		return fmt.Errorf("timed out waiting for migration lock")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK('dailytrackr_migrations')")
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			id VARCHAR(191) PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("error creating schema_migrations: %v", err)
+	}
+
+	if err := checkUTC(ctx, conn, cfg); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		var applied int
+		err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE id = ?", m.ID).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("error checking migration %s: %v", m.ID, err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		if _, err := conn.ExecContext(ctx, m.SQL); err != nil {
+			return fmt.Errorf("error applying migration %s: %v", m.ID, err)
+		}
+		if _, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations (id) VALUES (?)", m.ID); err != nil {
+			return fmt.Errorf("error recording migration %s: %v", m.ID, err)
+		}
+
+		slog.Info("migration applied", "migration", m.ID)
+	}
+
+	return nil
+}
+
+// checkUTC refuses to migrate a database that may hold local times.
+// Connections used to read and write times in the service host's zone
+// (loc=Local) and the server's session zone; they now use UTC, so rows
+// written before the switch are off by that zone's offset unless it was UTC.
+// Neither zone can be known after the fact, so a database that already has
+// users or activities fails until its times are converted and DB_TIMES_UTC is
+// set. The result is recorded so the check runs once per database.
+func checkUTC(ctx context.Context, conn *sql.Conn, cfg *config.Config) error {
+	var done int
+	err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE id = ?", utcMigrationID).Scan(&done)
+	if err != nil {
+		return fmt.Errorf("error checking migration %s: %v", utcMigrationID, err)
+	}
+	if done > 0 {
+		return nil
+	}
+
+	if !cfg.DBTimesUTC {
+		for _, table := range dataTables {
+			hasRows, err := tableHasRows(ctx, conn, table)
+			if err != nil {
+				return fmt.Errorf("error checking %s for existing rows: %v", table, err)
+			}
+			if hasRows {
+				return fmt.Errorf("table %s holds rows that may be in a non-UTC zone: "+
+					"convert stored times to UTC, then set DB_TIMES_UTC=true", table)
+			}
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "INSERT INTO schema_migrations (id) VALUES (?)", utcMigrationID); err != nil {
+		return fmt.Errorf("error recording migration %s: %v", utcMigrationID, err)
+	}
+	slog.Info("migration applied", "migration", utcMigrationID)
+	return nil
+}
+
+// tableHasRows reports whether table exists in the current database and is not empty
+func tableHasRows(ctx context.Context, conn *sql.Conn, table string) (bool, error) {
+	var exists int
+	err := conn.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", table).Scan(&exists)
+	if err != nil || exists == 0 {
+		return false, err
+	}
+
+	var hasRows bool
+	err = conn.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+table+")").Scan(&hasRows)
+	return hasRows, err
+}
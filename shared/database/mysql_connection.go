@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
 
 	"dailytrackr/shared/config"
 	_ "github.com/go-sql-driver/mysql"
@@ -14,10 +15,10 @@ func GetMySQLConnection(cfg *config.Config) (*sql.DB, error) {
 	// Use the new GetMySQLDSN method
 	dsn := cfg.GetMySQLDSN()
 
-	log.Printf("Connecting to MySQL: %s@%s:%s/%s",
-		cfg.DBUser, cfg.DBHost, cfg.DBPort, cfg.DBName)
+	slog.Debug("connecting to mysql",
+		"user", cfg.DBUser, "host", cfg.DBHost, "port", cfg.DBPort, "database", cfg.DBName)
 
-	db, err := sql.Open("mysql", dsn)
+	db, err := openMySQL(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("error opening database: %v", err)
 	}
@@ -28,13 +29,20 @@ func GetMySQLConnection(cfg *config.Config) (*sql.DB, error) {
 	}
 
 	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
+	configurePool(db, cfg)
 
-	log.Println("✅ Successfully connected to MySQL database")
+	slog.Info("connected to mysql", "host", cfg.DBHost, "database", cfg.DBName)
 	return db, nil
 }
 
+// configurePool applies the connection pool settings from config
+func configurePool(db *sql.DB, cfg *config.Config) {
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+}
+
 // TestMySQLConnection tests MySQL database connectivity
 func TestMySQLConnection() {
 	cfg := config.LoadConfig()
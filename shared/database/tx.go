@@ -0,0 +1,20 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// WithTx runs fn inside a transaction, committing when fn returns nil and rolling back otherwise
+func WithTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -1,42 +1,46 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"dailytrackr/shared/money"
+)
 
 // Activity DTOs
 type CreateActivityRequest struct {
 	Title        string `json:"title" validate:"required,min=3,max=200"`
-	StartTime    string `json:"start_time" validate:"required"` // Format: "2006-01-02T15:04:05Z"
+	StartTime    string `json:"start_time" validate:"required,rfc3339"` // Format: "2006-01-02T15:04:05Z"
 	DurationMins int    `json:"duration_mins" validate:"required,min=1"`
-	Cost         *int   `json:"cost,omitempty"` // Nullable
+	Cost         *int64 `json:"cost,omitempty" validate:"omitempty,min=0"`        // Nullable, in minor units (sen, cents)
+	Currency     string `json:"currency,omitempty" validate:"omitempty,currency"` // ISO 4217; defaults to the user's currency
 	Note         string `json:"note,omitempty"`
 }
 
 type UpdateActivityRequest struct {
-	Title        string `json:"title,omitempty"`
-	StartTime    string `json:"start_time,omitempty"`
-	DurationMins int    `json:"duration_mins,omitempty"`
-	Cost         *int   `json:"cost,omitempty"`
+	Title        string `json:"title,omitempty" validate:"omitempty,min=3,max=200"`
+	StartTime    string `json:"start_time,omitempty" validate:"omitempty,rfc3339"`
+	DurationMins int    `json:"duration_mins,omitempty" validate:"omitempty,min=1"`
+	Cost         *int64 `json:"cost,omitempty" validate:"omitempty,min=0"`
+	Currency     string `json:"currency,omitempty" validate:"omitempty,currency"`
 	Note         string `json:"note,omitempty"`
 }
 
 type ActivityResponse struct {
-	ID           int64     `json:"id"`
-	UserID       int64     `json:"user_id"`
-	Title        string    `json:"title"`
-	StartTime    time.Time `json:"start_time"`
-	DurationMins int       `json:"duration_mins"`
-	Cost         *int      `json:"cost,omitempty"`
-	PhotoURL     string    `json:"photo_url,omitempty"`
-	Note         string    `json:"note,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           int64        `json:"id"`
+	UserID       int64        `json:"user_id"`
+	Title        string       `json:"title"`
+	StartTime    time.Time    `json:"start_time"`
+	DurationMins int          `json:"duration_mins"`
+	Cost         *money.Money `json:"cost,omitempty"`
+	PhotoURL     string       `json:"photo_url,omitempty"`
+	Note         string       `json:"note,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
 }
 
 type ActivityListResponse struct {
 	Activities []ActivityResponse `json:"activities"`
-	Total      int                `json:"total"`
-	Page       int                `json:"page"`
-	Limit      int                `json:"limit"`
+	PaginationResponse
 }
 
 // Note: PhotoUploadResponse sekarang di common_dto.go untuk menghindari duplikasi
@@ -0,0 +1,59 @@
+package dto
+
+import "time"
+
+// AI DTOs - returned by the AI service
+
+// DailySummary is a generated (or cached) summary of one day
+type DailySummary struct {
+	ID          int64     `json:"id"`
+	UserID      int64     `json:"user_id"`
+	Date        time.Time `json:"date"`
+	SummaryText string    `json:"summary_text"`
+	AIGenerated bool      `json:"ai_generated"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserInsights represents comprehensive user insights
+type UserInsights struct {
+	UserID             int64     `json:"user_id"`
+	TotalActivities    int       `json:"total_activities"`
+	TotalHours         float64   `json:"total_hours"`
+	TotalExpenses      int       `json:"total_expenses"` // minor units of Currency, the user's default
+	Currency           string    `json:"currency"`
+	ActiveHabits       int       `json:"active_habits"`
+	AvgDailyHours      float64   `json:"avg_daily_hours"`
+	MostProductiveTime string    `json:"most_productive_time"`
+	TopActivityType    string    `json:"top_activity_type"`
+	SpendingPattern    string    `json:"spending_pattern"`
+	AIInsights         string    `json:"ai_insights,omitempty"`
+	LastUpdated        time.Time `json:"last_updated"`
+}
+
+// HabitRecommendationResponse is returned by POST /api/v1/ai/habit-recommendation
+type HabitRecommendationResponse struct {
+	Recommendation  string    `json:"recommendation"`
+	BasedOnDays     int       `json:"based_on_days"`
+	TotalActivities int       `json:"total_activities"`
+	ExistingHabits  int       `json:"existing_habits"`
+	AnalysisPeriod  string    `json:"analysis_period"`
+	GeneratedAt     time.Time `json:"generated_at"`
+}
+
+// ActivityAnalysisResponse is returned by POST /api/v1/ai/analyze-activities
+type ActivityAnalysisResponse struct {
+	Analysis        string    `json:"analysis"`
+	PeriodDays      int       `json:"period_days"`
+	ActivitiesCount int       `json:"activities_count"`
+	Period          string    `json:"period"`
+	GeneratedAt     time.Time `json:"generated_at"`
+}
+
+// ProductivityTipsResponse is returned by GET /api/v1/ai/productivity-tips
+type ProductivityTipsResponse struct {
+	Tips         string    `json:"tips"`
+	Personalized bool      `json:"personalized"`
+	BasedOn      string    `json:"based_on"`
+	GeneratedAt  time.Time `json:"generated_at"`
+}
@@ -16,24 +16,27 @@ type FileUploadRequest struct {
 	MaxSize   int64  `json:"max_size"`   // maksimum size dalam bytes
 }
 
-// Pagination Request
+// Pagination Request - cursor takes precedence over page when both are sent
 type PaginationRequest struct {
-	Page  int `json:"page" validate:"min=1"`
-	Limit int `json:"limit" validate:"min=1,max=100"`
+	Page   int    `json:"page" validate:"min=1"`
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+	Cursor string `json:"cursor,omitempty"`
 }
 
-// Pagination Response
+// Pagination Response - page is 0 when the listing was requested by cursor
 type PaginationResponse struct {
-	Page       int `json:"page"`
-	Limit      int `json:"limit"`
-	Total      int `json:"total"`
-	TotalPages int `json:"total_pages"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 // Date Range Request
 type DateRangeRequest struct {
-	StartDate string `json:"start_date" validate:"required"` // Format: "2006-01-02"
-	EndDate   string `json:"end_date" validate:"required"`   // Format: "2006-01-02"
+	StartDate string `json:"start_date" validate:"required,date_format"`                        // Format: "2006-01-02"
+	EndDate   string `json:"end_date" validate:"required,date_format,date_gtefield=start_date"` // Format: "2006-01-02"
 }
 
 // Health Check Response
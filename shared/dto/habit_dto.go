@@ -5,14 +5,14 @@ import "time"
 // Habit DTOs
 type CreateHabitRequest struct {
 	Title        string `json:"title" validate:"required,min=3,max=200"`
-	StartDate    string `json:"start_date" validate:"required"` // Format: "2006-01-02"
-	EndDate      string `json:"end_date" validate:"required"`   // Format: "2006-01-02"
-	ReminderTime string `json:"reminder_time,omitempty"`        // Format: "15:04"
+	StartDate    string `json:"start_date" validate:"required,date_format"`                        // Format: "2006-01-02"
+	EndDate      string `json:"end_date" validate:"required,date_format,date_gtefield=start_date"` // Format: "2006-01-02"
+	ReminderTime string `json:"reminder_time,omitempty" validate:"omitempty,time_format"`          // Format: "15:04"
 }
 
 type UpdateHabitRequest struct {
-	Title        string `json:"title,omitempty"`
-	ReminderTime string `json:"reminder_time,omitempty"`
+	Title        string `json:"title,omitempty" validate:"omitempty,min=3,max=200"`
+	ReminderTime string `json:"reminder_time,omitempty" validate:"omitempty,time_format"`
 }
 
 type HabitResponse struct {
@@ -29,13 +29,13 @@ type HabitResponse struct {
 // Habit Log DTOs
 type CreateHabitLogRequest struct {
 	HabitID int64  `json:"habit_id" validate:"required"`
-	Date    string `json:"date" validate:"required"` // Format: "2006-01-02"
+	Date    string `json:"date" validate:"required,date_format"` // Format: "2006-01-02"
 	Status  string `json:"status" validate:"required,oneof=DONE SKIPPED FAILED"`
 	Note    string `json:"note,omitempty"`
 }
 
 type UpdateHabitLogRequest struct {
-	Status string `json:"status,omitempty"`
+	Status string `json:"status,omitempty" validate:"omitempty,oneof=DONE SKIPPED FAILED"`
 	Note   string `json:"note,omitempty"`
 }
 
@@ -50,6 +50,12 @@ type HabitLogResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// HabitLogListResponse is one page of a habit's logs
+type HabitLogListResponse struct {
+	Logs []HabitLogResponse `json:"logs"`
+	PaginationResponse
+}
+
 type HabitWithLogsResponse struct {
 	Habit HabitResponse      `json:"habit"`
 	Logs  []HabitLogResponse `json:"logs"`
@@ -0,0 +1,117 @@
+package dto
+
+import "time"
+
+// Statistics DTOs - returned by the stat service.
+// Expense amounts are in minor units (sen, cents) of the currency given alongside.
+
+// Dashboard statistics structure
+type DashboardStats struct {
+	TotalActivities int     `json:"total_activities"`
+	TotalHours      float64 `json:"total_hours"`
+	TotalExpenses   int     `json:"total_expenses"` // in Currency only; see the expense report for others
+	Currency        string  `json:"currency"`       // the user's default currency
+	ActiveHabits    int     `json:"active_habits"`
+	CompletedHabits int     `json:"completed_habits"`
+	AvgDailyHours   float64 `json:"avg_daily_hours"`
+	StreakDays      int     `json:"streak_days"`
+	ThisWeekHours   float64 `json:"this_week_hours"`
+	LastWeekHours   float64 `json:"last_week_hours"`
+	HoursGrowth     float64 `json:"hours_growth_percent"`
+}
+
+// Activity summary structure
+type ActivitySummary struct {
+	Period            string          `json:"period"`
+	TotalActivities   int             `json:"total_activities"`
+	TotalHours        float64         `json:"total_hours"`
+	TotalExpenses     int             `json:"total_expenses"` // in Currency only
+	Currency          string          `json:"currency"`
+	AvgDuration       float64         `json:"avg_duration_mins"`
+	MostProductiveDay string          `json:"most_productive_day"`
+	TopCategories     []CategoryStats `json:"top_categories"`
+}
+
+type CategoryStats struct {
+	Category   string  `json:"category"`
+	Count      int     `json:"count"`
+	TotalHours float64 `json:"total_hours"`
+	Percentage float64 `json:"percentage"`
+}
+
+// Habit progress structures
+type HabitProgressSummary struct {
+	TotalHabits     int                   `json:"total_habits"`
+	ActiveHabits    int                   `json:"active_habits"`
+	CompletedHabits int                   `json:"completed_habits"`
+	OverallSuccess  float64               `json:"overall_success_rate"`
+	HabitDetails    []HabitProgressDetail `json:"habit_details"`
+}
+
+type HabitProgressDetail struct {
+	HabitID       int64   `json:"habit_id"`
+	Title         string  `json:"title"`
+	StartDate     string  `json:"start_date"`
+	EndDate       string  `json:"end_date"`
+	TotalDays     int     `json:"total_days"`
+	CompletedDays int     `json:"completed_days"`
+	SuccessRate   float64 `json:"success_rate"`
+	CurrentStreak int     `json:"current_streak"`
+	Status        string  `json:"status"` // active, completed, upcoming
+}
+
+// Chart data structures
+type ChartData struct {
+	Labels   []string     `json:"labels"`
+	Data     []ChartPoint `json:"data"`
+	Currency string       `json:"currency"` // expenses are in this currency only
+}
+
+type ChartPoint struct {
+	Date       string  `json:"date"`
+	Hours      float64 `json:"hours"`
+	Activities int     `json:"activities"`
+	Expenses   int     `json:"expenses"`
+}
+
+// Expense report structure. Spending is reported per currency, or as a single
+// entry when the report was converted into one currency.
+type ExpenseReport struct {
+	Period     string             `json:"period"`
+	Converted  bool               `json:"converted"`
+	Currencies []CurrencyExpenses `json:"currencies"`
+}
+
+type CurrencyExpenses struct {
+	Currency           string            `json:"currency"`
+	TotalExpenses      int               `json:"total_expenses"`
+	AverageDaily       float64           `json:"average_daily"`
+	HighestDay         ExpenseDay        `json:"highest_day"`
+	ExpensesByCategory []ExpenseCategory `json:"expenses_by_category"`
+	DailyBreakdown     []ExpenseDay      `json:"daily_breakdown"`
+}
+
+type ExpenseDay struct {
+	Date   string `json:"date"`
+	Amount int    `json:"amount"`
+	Count  int    `json:"count"`
+}
+
+type ExpenseCategory struct {
+	Category   string  `json:"category"`
+	Amount     int     `json:"amount"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// UserUsage is how much of the app a user has used, for administrators
+type UserUsage struct {
+	UserID          int64      `json:"user_id"`
+	Activities      int        `json:"activities"`
+	ActivityMinutes int        `json:"activity_minutes"`
+	LastActivityAt  *time.Time `json:"last_activity_at,omitempty"`
+	Habits          int        `json:"habits"`
+	HabitLogs       int        `json:"habit_logs"`
+	LastHabitLogAt  *time.Time `json:"last_habit_log_at,omitempty"`
+	DailySummaries  int        `json:"daily_summaries"`
+}
@@ -7,6 +7,8 @@ type RegisterRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,iana_timezone"`
+	Currency string `json:"currency,omitempty" validate:"omitempty,currency"`
 }
 
 type LoginRequest struct {
@@ -19,6 +21,44 @@ type UpdateProfileRequest struct {
 	Username string  `json:"username,omitempty" validate:"omitempty,min=3,max=50"`
 	Email    string  `json:"email,omitempty" validate:"omitempty,email"`
 	Bio      *string `json:"bio,omitempty" validate:"omitempty,max=500"`
+	Language *string `json:"language,omitempty" validate:"omitempty,oneof=en id"`
+	Timezone *string `json:"timezone,omitempty" validate:"omitempty,iana_timezone"`
+	Currency *string `json:"currency,omitempty" validate:"omitempty,currency"`
+}
+
+// Settings DTOs
+
+// UpdateSettingsRequest changes only the settings it includes
+type UpdateSettingsRequest struct {
+	Timezone      *string                            `json:"timezone,omitempty" validate:"omitempty,iana_timezone"`
+	WeekStart     *string                            `json:"week_start,omitempty" validate:"omitempty,oneof=monday sunday saturday"`
+	Currency      *string                            `json:"currency,omitempty" validate:"omitempty,currency"`
+	Language      *string                            `json:"language,omitempty" validate:"omitempty,oneof=en id"`
+	Theme         *string                            `json:"theme,omitempty" validate:"omitempty,oneof=system light dark"`
+	Notifications *UpdateNotificationSettingsRequest `json:"notifications,omitempty"`
+}
+
+type UpdateNotificationSettingsRequest struct {
+	HabitReminders *bool `json:"habit_reminders,omitempty"`
+	WeeklySummary  *bool `json:"weekly_summary,omitempty"`
+	ProductUpdates *bool `json:"product_updates,omitempty"`
+}
+
+// UserSettings are a user's preferences, each at its default until changed
+type UserSettings struct {
+	Timezone      string               `json:"timezone"`
+	WeekStart     string               `json:"week_start"` // monday, sunday or saturday
+	Currency      string               `json:"currency"`
+	Language      string               `json:"language"`
+	Theme         string               `json:"theme"` // system, light or dark
+	Notifications NotificationSettings `json:"notifications"`
+}
+
+// NotificationSettings are which optional notifications the user wants
+type NotificationSettings struct {
+	HabitReminders bool `json:"habit_reminders"`
+	WeeklySummary  bool `json:"weekly_summary"`
+	ProductUpdates bool `json:"product_updates"`
 }
 
 type ChangePasswordRequest struct {
@@ -30,15 +70,149 @@ type DeleteAccountRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// Email Verification DTOs
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Password Reset DTOs
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
+
+// Two-Factor Authentication DTOs. Code is a 6-digit authenticator code or,
+// where noted, one of the recovery codes.
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"` // authenticator or recovery code
+}
+
+type MFALoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"` // authenticator or recovery code
+}
+
+type TwoFactorEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"` // data:image/png;base64 URI of the otpauth URI
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallengeResponse is returned by Login instead of AuthResponse when the
+// account has two-factor authentication enabled
+type MFAChallengeResponse struct {
+	MFARequired    bool   `json:"mfa_required"`
+	ChallengeToken string `json:"challenge_token"`
+	ExpiresIn      int    `json:"expires_in"` // seconds
+}
+
+// SessionResponse describes one signed-in device; Current marks the session
+// the request was made with
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type RevokeSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// DataExportResponse is the state of a personal data export; DownloadURL is
+// set once it is ready and works until ExpiresAt
+type DataExportResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"` // pending, ready or failed
+	DownloadURL string     `json:"download_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// AccountDeletionResponse is the record of an account deletion. Its ID is the
+// receipt for looking it up after the account is gone; EmailHash is the
+// SHA-256 of the account's lower-cased email.
+type AccountDeletionResponse struct {
+	ID            string                 `json:"id"`
+	Status        string                 `json:"status"` // scheduled, cancelled, purging or completed
+	EmailHash     string                 `json:"email_hash"`
+	RequestedAt   time.Time              `json:"requested_at"`
+	ScheduledFor  time.Time              `json:"scheduled_for"`
+	CancelledAt   *time.Time             `json:"cancelled_at,omitempty"`
+	PurgedAt      *time.Time             `json:"purged_at,omitempty"`
+	CompletedAt   *time.Time             `json:"completed_at,omitempty"`
+	Confirmations []DeletionConfirmation `json:"confirmations"`
+}
+
+// DeletionConfirmation is a service's confirmation that it purged the account's data
+type DeletionConfirmation struct {
+	Service     string    `json:"service"`
+	Records     int64     `json:"records"`
+	ConfirmedAt time.Time `json:"confirmed_at"`
+}
+
+// Administration DTOs
+
+// UpdateRolesRequest replaces a user's roles; an empty list leaves only the user role
+type UpdateRolesRequest struct {
+	Roles []string `json:"roles" validate:"required"`
+}
+
+// AdminUserResponse is a user as administrators see them
+type AdminUserResponse struct {
+	UserResponse
+	Roles      []string   `json:"roles"`
+	Disabled   bool       `json:"disabled"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+}
+
+type AdminUserListResponse struct {
+	Users []AdminUserResponse `json:"users"`
+	PaginationResponse
+}
+
+// AdminUsageResponse is how much of the app a user has used, with where they are signed in
+type AdminUsageResponse struct {
+	User     AdminUserResponse `json:"user"`
+	Sessions []SessionResponse `json:"sessions"`
+	Usage    *UserUsage        `json:"usage"`
+}
+
 // Response DTOs
 type UserResponse struct {
-	ID           int64     `json:"id"`
-	Username     string    `json:"username"`
-	Email        string    `json:"email"`
-	Bio          string    `json:"bio,omitempty"`
-	ProfilePhoto string    `json:"profile_photo,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID               int64     `json:"id"`
+	Username         string    `json:"username"`
+	Email            string    `json:"email"`
+	EmailVerified    bool      `json:"email_verified"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	Bio              string    `json:"bio,omitempty"`
+	ProfilePhoto     string    `json:"profile_photo,omitempty"`
+	Language         string    `json:"language"`
+	Timezone         string    `json:"timezone"`
+	Currency         string    `json:"currency"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 type AuthResponse struct {
@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+)
+
+// Code identifies a class of application error independent of transport
+type Code string
+
+// Error codes
+const (
+	CodeNotFound            Code = "not_found"
+	CodeValidation          Code = "validation"
+	CodeConflict            Code = "conflict"
+	CodeUnauthorized        Code = "unauthorized"
+	CodeForbidden           Code = "forbidden"
+	CodeRateLimited         Code = "rate_limited"
+	CodeUpstreamUnavailable Code = "upstream_unavailable"
+	CodeInternal            Code = "internal"
+)
+
+// FieldError describes a single invalid field in a request.
+// Key and Args, when set, let the message be re-rendered in the caller's language.
+type FieldError struct {
+	Field   string        `json:"field"`
+	Code    string        `json:"code"`
+	Message string        `json:"message"`
+	Key     string        `json:"-"`
+	Args    []interface{} `json:"-"`
+}
+
+// Error is a typed application error carrying a code, a client-safe message
+// and, optionally, the underlying cause and field-level validation details
+type Error struct {
+	Code    Code
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying cause
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status code for the error
+func (e *Error) Status() int {
+	return StatusFor(e.Code)
+}
+
+// New creates an error with the given code and message
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap creates an error with the given code and message around a cause
+func Wrap(err error, code Code, message string) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// NotFound creates a not_found error
+func NotFound(message string) *Error {
+	return New(CodeNotFound, message)
+}
+
+// Validation creates a validation error with optional field details
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Code: CodeValidation, Message: message, Fields: fields}
+}
+
+// Conflict creates a conflict error
+func Conflict(message string) *Error {
+	return New(CodeConflict, message)
+}
+
+// Unauthorized creates an unauthorized error
+func Unauthorized(message string) *Error {
+	return New(CodeUnauthorized, message)
+}
+
+// Forbidden creates a forbidden error
+func Forbidden(message string) *Error {
+	return New(CodeForbidden, message)
+}
+
+// RateLimited creates a rate_limited error
+func RateLimited(message string) *Error {
+	return New(CodeRateLimited, message)
+}
+
+// UpstreamUnavailable creates an upstream_unavailable error around a cause
+func UpstreamUnavailable(message string, err error) *Error {
+	return Wrap(err, CodeUpstreamUnavailable, message)
+}
+
+// Internal creates an internal error; the cause is logged but never sent to clients
+func Internal(message string, err error) *Error {
+	return Wrap(err, CodeInternal, message)
+}
+
+// From converts any error into an *Error. Typed errors are returned as-is,
+// sql.ErrNoRows becomes not_found, and everything else is internal.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if stderrors.As(err, &appErr) {
+		return appErr
+	}
+
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return Wrap(err, CodeNotFound, "resource not found")
+	}
+
+	return Internal("internal server error", err)
+}
+
+// FromStatus creates an error for a legacy status code and message
+func FromStatus(status int, message string, err error) *Error {
+	return Wrap(err, CodeForStatus(status), message)
+}
+
+// HasCode reports whether err is an *Error with the given code
+func HasCode(err error, code Code) bool {
+	var appErr *Error
+	return stderrors.As(err, &appErr) && appErr.Code == code
+}
+
+// StatusFor maps an error code to its HTTP status
+func StatusFor(code Code) int {
+	switch code {
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeValidation:
+		return http.StatusBadRequest
+	case CodeConflict:
+		return http.StatusConflict
+	case CodeUnauthorized:
+		return http.StatusUnauthorized
+	case CodeForbidden:
+		return http.StatusForbidden
+	case CodeRateLimited:
+		return http.StatusTooManyRequests
+	case CodeUpstreamUnavailable:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CodeForStatus maps an HTTP status to the closest error code
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeValidation
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return CodeUpstreamUnavailable
+	default:
+		return CodeInternal
+	}
+}
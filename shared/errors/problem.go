@@ -0,0 +1,81 @@
+package errors
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/i18n"
+)
+
+// ContentTypeProblem is the media type for RFC 7807 problem details
+const ContentTypeProblem = "application/problem+json"
+
+// problemTypeBase prefixes the problem type URI for each error code
+const problemTypeBase = "https://dailytrackr.dev/problems/"
+
+// Problem is an RFC 7807 problem details document
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     Code         `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// ToProblem converts an error into a problem document.
+// Internal errors are logged with their cause and returned with a generic detail.
+func ToProblem(err error, instance string) Problem {
+	appErr := From(err)
+	status := appErr.Status()
+
+	problem := Problem{
+		Type:     problemTypeBase + string(appErr.Code),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   appErr.Message,
+		Instance: instance,
+		Code:     appErr.Code,
+		Errors:   appErr.Fields,
+	}
+
+	if appErr.Code == CodeInternal {
+		slog.Error("internal error", "instance", instance, "error", appErr)
+		problem.Detail = constants.ErrUnexpected
+	} else if appErr.Code == CodeUpstreamUnavailable && appErr.Err != nil {
+		slog.Warn("upstream error", "instance", instance, "error", appErr)
+	}
+
+	return problem
+}
+
+// Localize translates the detail and field messages into lang
+func (p Problem) Localize(lang string) Problem {
+	p.Detail = i18n.T(lang, p.Detail)
+
+	if len(p.Errors) > 0 {
+		fields := make([]FieldError, len(p.Errors))
+		for i, field := range p.Errors {
+			if field.Key != "" {
+				field.Message = i18n.Tf(lang, field.Key, field.Args...)
+			}
+			fields[i] = field
+		}
+		p.Errors = fields
+	}
+
+	return p
+}
+
+// WriteProblem writes err as an application/problem+json response
+// in the language recorded on the response by the language middleware
+func WriteProblem(w http.ResponseWriter, instance string, err error) {
+	problem := ToProblem(err, instance).Localize(i18n.FromHeader(w.Header()))
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
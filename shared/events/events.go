@@ -0,0 +1,116 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event types. The part before the dot names the aggregate that changed.
+const (
+	ActivityCreated = "activity.created"
+	ActivityUpdated = "activity.updated"
+	ActivityDeleted = "activity.deleted"
+	HabitCreated    = "habit.created"
+	HabitUpdated    = "habit.updated"
+	HabitDeleted    = "habit.deleted"
+	HabitLogged     = "habit.logged"
+	HabitLogUpdated = "habit_log.updated"
+	UserUpdated     = "user.updated"
+	UserDeleted     = "user.deleted"
+	// UserPurged is recorded by each service once it has removed a deleted user's data
+	UserPurged = "user.purged"
+)
+
+// Event is a domain change recorded by one service for the others.
+// Delivery is at-least-once, so consumers must tolerate seeing an ID twice.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	UserID     int64           `json:"user_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// ActivityPayload accompanies the activity.* events
+type ActivityPayload struct {
+	ActivityID int64     `json:"activity_id"`
+	StartTime  time.Time `json:"start_time"`
+	// PreviousStartTime is set on activity.updated when the activity moved to another time
+	PreviousStartTime *time.Time `json:"previous_start_time,omitempty"`
+}
+
+// HabitPayload accompanies the habit.* events
+type HabitPayload struct {
+	HabitID int64 `json:"habit_id"`
+}
+
+// HabitLogPayload accompanies habit.logged and habit_log.updated
+type HabitLogPayload struct {
+	HabitID int64  `json:"habit_id"`
+	LogID   int64  `json:"log_id"`
+	Date    string `json:"date"` // Format: "2006-01-02"
+	Status  string `json:"status"`
+}
+
+// UserPayload accompanies the user.* events
+type UserPayload struct {
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Language string `json:"language,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// PurgePayload accompanies user.purged; Records counts the rows removed
+type PurgePayload struct {
+	Service string `json:"service"`
+	Records int64  `json:"records"`
+}
+
+// New creates an event with a fresh ID; payload is stored as JSON
+func New(eventType string, userID int64, payload interface{}) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("error encoding %s payload: %v", eventType, err)
+	}
+
+	return Event{
+		ID:         newID(),
+		Type:       eventType,
+		UserID:     userID,
+		OccurredAt: time.Now().UTC(),
+		Payload:    data,
+	}, nil
+}
+
+// Decode unmarshals the event payload into v
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// Handler processes one delivered event. Returning an error leaves the event
+// unacknowledged so the transport delivers it again.
+type Handler func(ctx context.Context, event Event) error
+
+// Route dispatches events to the handler registered for their type; other types are acknowledged and skipped
+func Route(handlers map[string]Handler) Handler {
+	return func(ctx context.Context, event Event) error {
+		if handle, ok := handlers[event.Type]; ok {
+			return handle(ctx, event)
+		}
+		return nil
+	}
+}
+
+// newID generates a random event identifier
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format("20060102150405.000000")))
+	}
+	return hex.EncodeToString(b)
+}
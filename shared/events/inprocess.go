@@ -0,0 +1,125 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// inProcessRetryDelay is how long a consumer waits before retrying a failed event
+const inProcessRetryDelay = time.Second
+
+// InProcess is an in-memory transport for a single process (development, one binary).
+// It holds the stream in memory and tracks one offset per consumer name, so it
+// offers no delivery across services or restarts; a Relay makes up for that by
+// delivering every outbox event to each process itself and recording the
+// delivery once OnConsumed reports it handled. Events are dropped from memory
+// as soon as every consumer has handled them. Consumers start at the oldest
+// event still held, which is every event until the first consumer subscribes,
+// so consumers should all subscribe as the process starts.
+type InProcess struct {
+	mu         sync.Mutex
+	log        []Event
+	base       int // offset of log[0] in the stream
+	offsets    map[string]int
+	notify     chan struct{}
+	onConsumed func(Event)
+}
+
+// NewInProcess creates an empty in-process stream
+func NewInProcess() *InProcess {
+	return &InProcess{
+		offsets: make(map[string]int),
+		notify:  make(chan struct{}),
+	}
+}
+
+// OnConsumed sets a function called with each event, in stream order, once
+// every consumer has handled it
+func (t *InProcess) OnConsumed(fn func(Event)) {
+	t.mu.Lock()
+	t.onConsumed = fn
+	t.mu.Unlock()
+}
+
+// Publish appends events and wakes every waiting consumer
+func (t *InProcess) Publish(_ context.Context, events ...Event) error {
+	t.mu.Lock()
+	t.log = append(t.log, events...)
+	close(t.notify)
+	t.notify = make(chan struct{})
+	t.mu.Unlock()
+	return nil
+}
+
+// Subscribe delivers events from the consumer's offset, advancing it only after handle succeeds
+func (t *InProcess) Subscribe(ctx context.Context, consumer string, handle Handler) error {
+	t.mu.Lock()
+	if _, ok := t.offsets[consumer]; !ok {
+		t.offsets[consumer] = t.base
+	}
+	t.mu.Unlock()
+
+	for {
+		t.mu.Lock()
+		offset := t.offsets[consumer]
+		wait := t.notify
+		var event *Event
+		if offset < t.base+len(t.log) {
+			e := t.log[offset-t.base]
+			event = &e
+		}
+		t.mu.Unlock()
+
+		if event == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-wait:
+				continue
+			}
+		}
+
+		if err := handle(ctx, *event); err != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(inProcessRetryDelay):
+				continue
+			}
+		}
+
+		t.mu.Lock()
+		t.offsets[consumer] = offset + 1
+		consumed, onConsumed := t.trim()
+		t.mu.Unlock()
+
+		if onConsumed != nil {
+			for _, e := range consumed {
+				onConsumed(e)
+			}
+		}
+	}
+}
+
+// trim drops the events every consumer has handled and returns them with the
+// function to report them to; t.mu must be held
+func (t *InProcess) trim() ([]Event, func(Event)) {
+	oldest := t.base + len(t.log)
+	for _, offset := range t.offsets {
+		oldest = min(oldest, offset)
+	}
+	if oldest == t.base {
+		return nil, nil
+	}
+
+	consumed := t.log[:oldest-t.base]
+	t.log = append([]Event(nil), t.log[oldest-t.base:]...)
+	t.base = oldest
+	return consumed, t.onConsumed
+}
+
+// Close releases nothing; the stream lives as long as the process
+func (t *InProcess) Close() error {
+	return nil
+}
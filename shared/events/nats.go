@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"dailytrackr/shared/config"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	natsStreamName  = "DAILYTRACKR_EVENTS"
+	natsFetchWait   = 5 * time.Second
+	natsRetryDelay  = time.Second
+	natsAckWait     = 30 * time.Second
+	natsDedupWindow = 10 * time.Minute
+)
+
+// NATS carries events on a JetStream stream. Each consumer name is a durable
+// consumer, so the server tracks its offset and redelivers unacknowledged events.
+type NATS struct {
+	conn   *nats.Conn
+	stream jetstream.Stream
+	js     jetstream.JetStream
+}
+
+// NewNATS connects to the NATS server from config and ensures the stream exists
+func NewNATS(cfg *config.Config) (*NATS, error) {
+	conn, err := nats.Connect(cfg.NATSURL, nats.Name("dailytrackr"))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to nats: %v", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error opening jetstream: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Duplicates lets JetStream drop an event the relay publishes twice
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       natsStreamName,
+		Subjects:   []string{streamName + ".>"},
+		Duplicates: natsDedupWindow,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error creating event stream: %v", err)
+	}
+
+	return &NATS{conn: conn, stream: stream, js: js}, nil
+}
+
+// Publish sends each event on a subject named after its type, using the event ID as the message ID
+func (t *NATS) Publish(ctx context.Context, events ...Event) error {
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if _, err := t.js.Publish(ctx, streamName+"."+e.Type, data, jetstream.WithMsgID(e.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe pulls from the consumer's durable consumer one event at a time,
+// acknowledging after handle succeeds and asking for redelivery when it fails
+func (t *NATS) Subscribe(ctx context.Context, consumer string, handle Handler) error {
+	cons, err := t.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       consumer,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       natsAckWait,
+		DeliverPolicy: jetstream.DeliverNewPolicy,
+		MaxAckPending: 1, // keeps delivery in stream order
+	})
+	if err != nil {
+		return fmt.Errorf("error creating consumer %s: %v", consumer, err)
+	}
+
+	for ctx.Err() == nil {
+		msg, err := cons.Next(jetstream.FetchMaxWait(natsFetchWait))
+		if err != nil {
+			if !errors.Is(err, nats.ErrTimeout) && !errors.Is(err, jetstream.ErrNoMessages) {
+				sleep(ctx, natsRetryDelay)
+			}
+			continue
+		}
+
+		var event Event
+		if err := json.Unmarshal(msg.Data(), &event); err != nil {
+			msg.Term() // undecodable, never redeliver
+			continue
+		}
+
+		if err := handle(ctx, event); err != nil {
+			msg.NakWithDelay(natsRetryDelay)
+			continue
+		}
+		msg.Ack()
+	}
+
+	return ctx.Err()
+}
+
+// Close drains the NATS connection
+func (t *NATS) Close() error {
+	return t.conn.Drain()
+}
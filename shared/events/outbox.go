@@ -0,0 +1,451 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/health"
+	"dailytrackr/shared/tracing"
+)
+
+// Migrations creates the outbox table shared by every service
+var Migrations = []database.Migration{
+	{
+		ID: "events/001_event_outbox",
+		SQL: `CREATE TABLE IF NOT EXISTS event_outbox (
+			seq BIGINT AUTO_INCREMENT PRIMARY KEY,
+			id CHAR(32) NOT NULL UNIQUE,
+			type VARCHAR(64) NOT NULL,
+			user_id BIGINT NOT NULL,
+			payload JSON NOT NULL,
+			occurred_at DATETIME(6) NOT NULL,
+			published_at DATETIME(6) NULL,
+			INDEX idx_event_outbox_pending (published_at, seq)
+		)`,
+	},
+	{
+		// Relays on a transport local to their process each deliver every
+		// event, so they track what their process handled per relay
+		ID: "events/002_event_relays",
+		SQL: `CREATE TABLE IF NOT EXISTS event_relays (
+			name VARCHAR(64) PRIMARY KEY,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+	},
+	{
+		ID: "events/003_event_outbox_deliveries",
+		SQL: `CREATE TABLE IF NOT EXISTS event_outbox_deliveries (
+			relay VARCHAR(64) NOT NULL,
+			seq BIGINT NOT NULL,
+			delivered_at DATETIME(6) NOT NULL,
+			PRIMARY KEY (relay, seq),
+			INDEX idx_event_outbox_deliveries_delivered (delivered_at)
+		)`,
+	},
+}
+
+// OutboxRetention is how long published events stay in the outbox before
+// they are pruned. With a process-local transport an event also stays until
+// every relay in event_relays has delivered it, however long one is down; a
+// service that is gone for good must have its row removed from there.
+const OutboxRetention = 7 * 24 * time.Hour
+
+// Record writes events to the outbox inside tx, so they are published
+// if and only if the change that produced them commits
+func Record(ctx context.Context, tx *sql.Tx, events ...Event) error {
+	for _, e := range events {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO event_outbox (id, type, user_id, payload, occurred_at) VALUES (?, ?, ?, ?, ?)",
+			e.ID, e.Type, e.UserID, []byte(e.Payload), e.OccurredAt,
+		)
+		if err != nil {
+			return fmt.Errorf("error recording %s event: %v", e.Type, err)
+		}
+	}
+	return nil
+}
+
+// RecordPurged confirms that service has removed the data of a deleted user,
+// so the user service can complete its deletion record
+func RecordPurged(ctx context.Context, db *sql.DB, userID int64, service string, records int64) error {
+	event, err := New(UserPurged, userID, PurgePayload{Service: service, Records: records})
+	if err != nil {
+		return err
+	}
+	return database.WithTx(ctx, db, func(tx *sql.Tx) error {
+		return Record(ctx, tx, event)
+	})
+}
+
+// Relay publishes outbox rows to a transport in insertion order and marks them
+// published afterwards. A crash between the two publishes the batch again,
+// which is what makes delivery at-least-once rather than at-most-once.
+//
+// Every service shares the outbox. With a transport that reaches every process
+// (redis, nats) the first relay to claim a row publishes it for all of them.
+// The in-process transport only reaches its own process, so there each relay,
+// named after its service, delivers every event to its own process and records
+// it in event_outbox_deliveries once every consumer there has handled it; a
+// restart before then delivers it again.
+type Relay struct {
+	db        *sql.DB
+	name      string
+	transport Transport
+	log       *slog.Logger
+	interval  time.Duration
+	batchSize int
+
+	mu sync.Mutex
+	// inFlight maps the IDs of events published to this process but not yet
+	// handled by every consumer to their outbox seq
+	inFlight map[string]int64
+	// handled lists the seqs of in-flight events every consumer has handled
+	handled []int64
+}
+
+// NewRelay creates a relay for the service called name that polls the outbox every interval
+func NewRelay(db *sql.DB, name string, transport Transport, log *slog.Logger, interval time.Duration, batchSize int) *Relay {
+	return &Relay{
+		db:        db,
+		name:      name,
+		transport: transport,
+		log:       log,
+		interval:  interval,
+		batchSize: batchSize,
+		inFlight:  make(map[string]int64),
+	}
+}
+
+// fanOut reports whether the transport only reaches this process, so the
+// relay has to deliver every event itself
+func (r *Relay) fanOut() bool {
+	_, local := r.transport.(*InProcess)
+	return local
+}
+
+// Run relays pending events until ctx is cancelled; register it with lifecycle.Runner.Go
+func (r *Relay) Run(ctx context.Context) error {
+	if local, ok := r.transport.(*InProcess); ok {
+		if _, err := r.db.ExecContext(ctx, "INSERT IGNORE INTO event_relays (name) VALUES (?)", r.name); err != nil {
+			return fmt.Errorf("error registering event relay: %v", err)
+		}
+		local.OnConsumed(r.consumed)
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	relayBatch := r.relayBatch
+	if r.fanOut() {
+		relayBatch = r.deliverBatch
+	}
+
+	for {
+		// Drain the backlog before waiting for the next tick
+		for {
+			n, err := relayBatch(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				r.log.Warn("event relay batch failed", "error", err)
+				break
+			}
+			if n < r.batchSize {
+				break
+			}
+		}
+
+		if err := r.prune(ctx); err != nil && ctx.Err() == nil {
+			r.log.Warn("event outbox pruning failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayBatch publishes one batch of pending events and returns how many it handled.
+// Rows stay locked until the batch is marked, so relays in other service
+// instances skip them instead of publishing duplicates.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "events.Relay.relayBatch")
+	defer span.End()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT seq, id, type, user_id, payload, occurred_at
+		FROM event_outbox
+		WHERE published_at IS NULL
+		ORDER BY seq
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var seqs []int64
+	var batch []Event
+	for rows.Next() {
+		var seq int64
+		var e Event
+		var payload []byte
+		if err := rows.Scan(&seq, &e.ID, &e.Type, &e.UserID, &payload, &e.OccurredAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		e.Payload = payload
+		seqs = append(seqs, seq)
+		batch = append(batch, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	if err := r.transport.Publish(ctx, batch...); err != nil {
+		return 0, fmt.Errorf("error publishing events: %v", err)
+	}
+
+	for _, seq := range seqs {
+		if _, err := tx.ExecContext(ctx, "UPDATE event_outbox SET published_at = CURRENT_TIMESTAMP(6) WHERE seq = ?", seq); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	r.log.Debug("events relayed", "count", len(batch))
+	return len(batch), nil
+}
+
+// deliverBatch records the events this process has handled since the last
+// call, then publishes to it the oldest events this relay has not delivered
+// yet and returns how many it published. Events still in flight are not
+// published again, so a consumer that falls behind holds the relay back.
+// Instances of the same service each deliver to their own process, so an
+// event may be handled by more than one of them.
+func (r *Relay) deliverBatch(ctx context.Context) (int, error) {
+	ctx, span := tracing.Start(ctx, "events.Relay.deliverBatch")
+	defer span.End()
+
+	if err := r.recordHandled(ctx); err != nil {
+		return 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT o.seq, o.id, o.type, o.user_id, o.payload, o.occurred_at
+		FROM event_outbox o
+		LEFT JOIN event_outbox_deliveries d ON d.relay = ? AND d.seq = o.seq
+		WHERE d.seq IS NULL
+		ORDER BY o.seq
+		LIMIT ?
+	`, r.name, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	seqs := make(map[string]int64)
+	var batch []Event
+	r.mu.Lock()
+	for rows.Next() {
+		var seq int64
+		var e Event
+		var payload []byte
+		if err := rows.Scan(&seq, &e.ID, &e.Type, &e.UserID, &payload, &e.OccurredAt); err != nil {
+			r.mu.Unlock()
+			rows.Close()
+			return 0, err
+		}
+		if _, ok := r.inFlight[e.ID]; ok {
+			continue
+		}
+		e.Payload = payload
+		seqs[e.ID] = seq
+		batch = append(batch, e)
+	}
+	r.mu.Unlock()
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	// In flight before publishing, so a consumer finishing early finds them
+	r.mu.Lock()
+	for id, seq := range seqs {
+		r.inFlight[id] = seq
+	}
+	r.mu.Unlock()
+
+	if err := r.transport.Publish(ctx, batch...); err != nil {
+		r.mu.Lock()
+		for id := range seqs {
+			delete(r.inFlight, id)
+		}
+		r.mu.Unlock()
+		return 0, fmt.Errorf("error publishing events: %v", err)
+	}
+
+	r.log.Debug("events delivered", "count", len(batch))
+	return len(batch), nil
+}
+
+// consumed notes that every consumer in this process has handled event
+func (r *Relay) consumed(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if seq, ok := r.inFlight[event.ID]; ok {
+		r.handled = append(r.handled, seq)
+	}
+}
+
+// recordHandled records the deliveries of handled events; published_at is
+// when the first relay's process handled the event
+func (r *Relay) recordHandled(ctx context.Context) error {
+	r.mu.Lock()
+	handled := r.handled
+	r.handled = nil
+	r.mu.Unlock()
+
+	if len(handled) == 0 {
+		return nil
+	}
+
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		for _, seq := range handled {
+			if _, err := tx.ExecContext(ctx, "INSERT IGNORE INTO event_outbox_deliveries (relay, seq, delivered_at) VALUES (?, ?, CURRENT_TIMESTAMP(6))", r.name, seq); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, "UPDATE event_outbox SET published_at = COALESCE(published_at, CURRENT_TIMESTAMP(6)) WHERE seq = ?", seq); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Try again with the next batch
+		r.mu.Lock()
+		r.handled = append(handled, r.handled...)
+		r.mu.Unlock()
+		return err
+	}
+
+	recorded := make(map[int64]bool, len(handled))
+	for _, seq := range handled {
+		recorded[seq] = true
+	}
+	r.mu.Lock()
+	for id, seq := range r.inFlight {
+		if recorded[seq] {
+			delete(r.inFlight, id)
+		}
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// prune deletes one batch of events published more than OutboxRetention ago,
+// keeping those a relay on a process-local transport has yet to deliver, and
+// the deliveries of events that are gone
+func (r *Relay) prune(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "events.Relay.prune")
+	defer span.End()
+
+	cutoff := time.Now().Add(-OutboxRetention)
+	if !r.fanOut() {
+		_, err := r.db.ExecContext(ctx,
+			"DELETE FROM event_outbox WHERE published_at < ? ORDER BY seq LIMIT ?", cutoff, r.batchSize)
+		return err
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM event_outbox
+		WHERE published_at < ?
+		AND NOT EXISTS (
+			SELECT 1 FROM event_relays er
+			WHERE NOT EXISTS (
+				SELECT 1 FROM event_outbox_deliveries d
+				WHERE d.relay = er.name AND d.seq = event_outbox.seq
+			)
+		)
+		ORDER BY seq
+		LIMIT ?
+	`, cutoff, r.batchSize)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		DELETE d FROM event_outbox_deliveries d
+		LEFT JOIN event_outbox o ON o.seq = d.seq
+		WHERE d.relay = ? AND o.seq IS NULL
+	`, r.name)
+	return err
+}
+
+// Outbox lag thresholds: events waiting longer than these mean the relay or
+// the transport is struggling
+const (
+	OutboxLagDegraded = time.Minute
+	OutboxLagDown     = 10 * time.Minute
+)
+
+// Lag checks how long the oldest event the relay has yet to publish has been waiting
+func (r *Relay) Lag() health.CheckFunc {
+	return func(ctx context.Context) error {
+		var oldest sql.NullTime
+		var err error
+		if r.fanOut() {
+			err = r.db.QueryRowContext(ctx, `
+				SELECT MIN(o.occurred_at)
+				FROM event_outbox o
+				LEFT JOIN event_outbox_deliveries d ON d.relay = ? AND d.seq = o.seq
+				WHERE d.seq IS NULL
+			`, r.name).Scan(&oldest)
+		} else {
+			err = r.db.QueryRowContext(ctx,
+				"SELECT MIN(occurred_at) FROM event_outbox WHERE published_at IS NULL",
+			).Scan(&oldest)
+		}
+		if err != nil {
+			return err
+		}
+		if !oldest.Valid {
+			return nil
+		}
+
+		lag := time.Since(oldest.Time).Round(time.Second)
+		switch {
+		case lag >= OutboxLagDown:
+			return fmt.Errorf("oldest unpublished event is %s old", lag)
+		case lag >= OutboxLagDegraded:
+			return health.Degraded(fmt.Errorf("oldest unpublished event is %s old", lag))
+		}
+		return nil
+	}
+}
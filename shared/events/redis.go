@@ -0,0 +1,162 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"dailytrackr/shared/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisReadCount  = 50
+	redisBlock      = 5 * time.Second
+	redisRetryDelay = time.Second
+	// redisClaimIdle is how long an entry may stay unacknowledged by a crashed
+	// instance before another instance of the same consumer takes it over
+	redisClaimIdle = 5 * time.Minute
+)
+
+// RedisStreams carries events on a Redis stream. Each consumer name is a consumer
+// group, so Redis tracks its offset and the entries it has not acknowledged yet.
+type RedisStreams struct {
+	client *redis.Client
+	member string // this process within a consumer group
+}
+
+// NewRedisStreams connects to the Redis server from config
+func NewRedisStreams(cfg *config.Config) (*RedisStreams, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisHost + ":" + cfg.RedisPort,
+		Password: cfg.RedisPassword,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("error connecting to redis: %v", err)
+	}
+
+	host, _ := os.Hostname()
+	return &RedisStreams{
+		client: client,
+		member: fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}, nil
+}
+
+// Publish appends events to the stream in one round trip
+func (t *RedisStreams) Publish(ctx context.Context, events ...Event) error {
+	pipe := t.client.Pipeline()
+	for _, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: streamName, Values: map[string]interface{}{"event": data}})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Subscribe reads the stream through the consumer's group, acknowledging each
+// entry after handle succeeds. Unacknowledged entries are re-read first, which
+// keeps a failing event at the head until it is handled.
+func (t *RedisStreams) Subscribe(ctx context.Context, consumer string, handle Handler) error {
+	// New groups start at the end of the stream, like the other transports
+	err := t.client.XGroupCreateMkStream(ctx, streamName, consumer, "$").Err()
+	if err != nil && !strings.Contains(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("error creating consumer group %s: %v", consumer, err)
+	}
+
+	pending := true
+	for ctx.Err() == nil {
+		start := ">"
+		if pending {
+			start = "0"
+		}
+
+		streams, err := t.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    consumer,
+			Consumer: t.member,
+			Streams:  []string{streamName, start},
+			Count:    redisReadCount,
+			Block:    redisBlock,
+		}).Result()
+		if err == redis.Nil {
+			pending = t.claimStale(ctx, consumer)
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			sleep(ctx, redisRetryDelay)
+			continue
+		}
+
+		var messages []redis.XMessage
+		if len(streams) > 0 {
+			messages = streams[0].Messages
+		}
+		if pending && len(messages) == 0 {
+			pending = false
+			continue
+		}
+
+		for _, m := range messages {
+			if err := t.deliver(ctx, consumer, m, handle); err != nil {
+				pending = true
+				sleep(ctx, redisRetryDelay)
+				break
+			}
+		}
+	}
+
+	return ctx.Err()
+}
+
+// deliver hands one entry to handle and acknowledges it on success.
+// Entries that do not decode are acknowledged and dropped.
+func (t *RedisStreams) deliver(ctx context.Context, consumer string, m redis.XMessage, handle Handler) error {
+	var event Event
+	data, _ := m.Values["event"].(string)
+	if err := json.Unmarshal([]byte(data), &event); err == nil {
+		if err := handle(ctx, event); err != nil {
+			return err
+		}
+	}
+	return t.client.XAck(ctx, streamName, consumer, m.ID).Err()
+}
+
+// claimStale takes over entries left unacknowledged by instances that went away;
+// it reports whether anything was claimed
+func (t *RedisStreams) claimStale(ctx context.Context, consumer string) bool {
+	messages, _, err := t.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   streamName,
+		Group:    consumer,
+		Consumer: t.member,
+		MinIdle:  redisClaimIdle,
+		Start:    "0-0",
+		Count:    redisReadCount,
+	}).Result()
+	return err == nil && len(messages) > 0
+}
+
+// Close closes the Redis connection pool
+func (t *RedisStreams) Close() error {
+	return t.client.Close()
+}
+
+// sleep waits for d or until ctx is cancelled
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"dailytrackr/shared/config"
+)
+
+// streamName identifies the event stream on every transport
+const streamName = "dailytrackr.events"
+
+// Transport carries events from the outbox relay to consumers.
+// Each consumer name has its own offset: a consumer sees every event published
+// after it first subscribed, and an event it fails to handle is delivered again.
+type Transport interface {
+	// Publish appends events to the stream in order
+	Publish(ctx context.Context, events ...Event) error
+	// Subscribe delivers events to handle under the given consumer name until ctx is cancelled
+	Subscribe(ctx context.Context, consumer string, handle Handler) error
+	Close() error
+}
+
+// NewTransport creates the transport selected by EVENT_TRANSPORT
+func NewTransport(cfg *config.Config) (Transport, error) {
+	switch cfg.EventTransport {
+	case "", "inprocess":
+		return NewInProcess(), nil
+	case "redis":
+		return NewRedisStreams(cfg)
+	case "nats":
+		return NewNATS(cfg)
+	default:
+		return nil, fmt.Errorf("unknown event transport %q (want inprocess, redis or nats)", cfg.EventTransport)
+	}
+}
+
+// Consume returns a worker that subscribes consumer to transport and logs handler
+// failures before they are retried; register it with lifecycle.Runner.Go
+func Consume(transport Transport, consumer string, handle Handler, log *slog.Logger) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return transport.Subscribe(ctx, consumer, func(ctx context.Context, event Event) error {
+			err := handle(ctx, event)
+			if err != nil {
+				log.Warn("event handler failed, will retry",
+					"consumer", consumer, "event_id", event.ID, "event_type", event.Type, "error", err)
+			}
+			return err
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// Package flags evaluates feature flags loaded from a JSON file or the
+// feature_flags table. A flag can be switched on or off outright, limited to
+// some environments, granted to listed users and rolled out to a percentage
+// of everyone else.
+package flags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"dailytrackr/shared/config"
+)
+
+// Flag keys
+const (
+	AI            = "ai"            // AI summaries, insights and recommendations
+	Notifications = "notifications" // notification routes, still being built
+)
+
+// Defaults apply until a source overrides them, so that a missing flags file
+// keeps shipped features on and unfinished ones hidden
+var Defaults = []Flag{
+	{Key: AI, Description: "AI summaries, insights and recommendations", Enabled: true},
+	{Key: Notifications, Description: "Notification routes", Enabled: false},
+}
+
+// Flag is a single feature switch
+type Flag struct {
+	Key          string   `json:"key"`
+	Description  string   `json:"description,omitempty"`
+	Enabled      bool     `json:"enabled"`                // master switch
+	Percentage   *int     `json:"percentage,omitempty"`   // share of users (0-100) it is on for; nil means everyone
+	Users        []int64  `json:"users,omitempty"`        // always on for these users, whatever the percentage
+	Environments []string `json:"environments,omitempty"` // only on in these environments; empty means all
+}
+
+// Evaluate reports whether the flag is on for userID in environment.
+// Anonymous callers (userID 0) only see flags that are on for everyone.
+func (f Flag) Evaluate(environment string, userID int64) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	if len(f.Environments) > 0 && !contains(f.Environments, environment) {
+		return false
+	}
+
+	for _, id := range f.Users {
+		if id == userID && userID != 0 {
+			return true
+		}
+	}
+
+	if f.Percentage == nil || *f.Percentage >= 100 {
+		return true
+	}
+	if userID == 0 || *f.Percentage <= 0 {
+		return false
+	}
+	return bucket(f.Key, userID) < *f.Percentage
+}
+
+// bucket places a user in one of 100 buckets. Hashing the key with the user
+// spreads users differently per flag, and raising the percentage only ever
+// adds users to a rollout.
+func bucket(key string, userID int64) int {
+	h := fnv.New32a()
+	h.Write([]byte(key + ":" + strconv.FormatInt(userID, 10)))
+	return int(h.Sum32() % 100)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Source loads the current set of flags
+type Source interface {
+	Load(ctx context.Context) ([]Flag, error)
+}
+
+// Store holds the flags last loaded from a source and evaluates them
+type Store struct {
+	source      Source
+	environment string
+	log         *slog.Logger
+
+	mu    sync.RWMutex
+	flags map[string]Flag
+}
+
+// NewStore creates a store for environment holding the defaults; call Reload to load the source
+func NewStore(source Source, environment string, log *slog.Logger) *Store {
+	s := &Store{source: source, environment: environment, log: log}
+	s.set(nil)
+	return s
+}
+
+// New creates a store for the source selected in cfg and loads it.
+// db is only needed by the db source.
+func New(ctx context.Context, cfg *config.Config, db DB, log *slog.Logger) (*Store, error) {
+	var source Source
+	switch cfg.FlagsSource {
+	case "file":
+		source = FileSource(cfg.FlagsFile)
+	case "db":
+		if db == nil {
+			return nil, fmt.Errorf("flag source db needs a database connection")
+		}
+		source = DBSource(db)
+	default:
+		return nil, fmt.Errorf("unknown flag source %q", cfg.FlagsSource)
+	}
+
+	store := NewStore(source, cfg.Environment, log)
+	if err := store.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reload replaces the flags with the source's. On error the current flags are kept.
+func (s *Store) Reload(ctx context.Context) error {
+	flags, err := s.source.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("error loading feature flags: %v", err)
+	}
+	s.set(flags)
+	return nil
+}
+
+// set replaces the flags with the defaults overridden by flags
+func (s *Store) set(flags []Flag) {
+	merged := make(map[string]Flag, len(Defaults)+len(flags))
+	for _, f := range Defaults {
+		merged[f.Key] = f
+	}
+	for _, f := range flags {
+		merged[f.Key] = f
+	}
+
+	s.mu.Lock()
+	s.flags = merged
+	s.mu.Unlock()
+}
+
+// Run reloads the flags every interval until ctx is done
+func (s *Store) Run(interval time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if err := s.Reload(ctx); err != nil {
+					s.log.Warn("feature flag reload failed", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// Enabled reports whether the flag key is on for userID; unknown flags are off.
+// Pass 0 when there is no authenticated user.
+func (s *Store) Enabled(key string, userID int64) bool {
+	s.mu.RLock()
+	f, ok := s.flags[key]
+	s.mu.RUnlock()
+
+	return ok && f.Evaluate(s.environment, userID)
+}
+
+// Flags returns the loaded flags sorted by key
+func (s *Store) Flags() []Flag {
+	s.mu.RLock()
+	flags := make([]Flag, 0, len(s.flags))
+	for _, f := range s.flags {
+		flags = append(flags, f)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags
+}
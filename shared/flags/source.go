@@ -0,0 +1,106 @@
+package flags
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/tracing"
+)
+
+// FileSource loads flags from a JSON file of the form {"flags": [...]}.
+// A missing file is not an error; the defaults apply.
+type FileSource string
+
+// Load reads the file
+func (path FileSource) Load(ctx context.Context) ([]Flag, error) {
+	data, err := os.ReadFile(string(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Flags []Flag `json:"flags"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return file.Flags, nil
+}
+
+// Migrations creates the feature_flags table read by the db source
+var Migrations = []database.Migration{
+	{
+		ID: "flags/001_feature_flags",
+		SQL: `CREATE TABLE IF NOT EXISTS feature_flags (
+			` + "`key`" + ` VARCHAR(100) PRIMARY KEY,
+			description VARCHAR(255) NOT NULL DEFAULT '',
+			enabled BOOLEAN NOT NULL DEFAULT FALSE,
+			percentage TINYINT UNSIGNED NULL,
+			users JSON NULL,
+			environments JSON NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+		)`,
+	},
+}
+
+// DB is the part of *sql.DB the db source needs
+type DB interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type dbSource struct {
+	db DB
+}
+
+// DBSource loads flags from the feature_flags table
+func DBSource(db DB) Source {
+	return &dbSource{db: db}
+}
+
+// Load reads every row of feature_flags
+func (s *dbSource) Load(ctx context.Context) ([]Flag, error) {
+	ctx, span := tracing.Start(ctx, "flags.DBSource.Load")
+	defer span.End()
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT `key`, description, enabled, percentage, users, environments FROM feature_flags")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var flags []Flag
+	for rows.Next() {
+		var f Flag
+		var percentage sql.NullInt64
+		var users, environments []byte
+		if err := rows.Scan(&f.Key, &f.Description, &f.Enabled, &percentage, &users, &environments); err != nil {
+			return nil, err
+		}
+
+		if percentage.Valid {
+			p := int(percentage.Int64)
+			f.Percentage = &p
+		}
+		if len(users) > 0 {
+			if err := json.Unmarshal(users, &f.Users); err != nil {
+				return nil, fmt.Errorf("flag %s: invalid users: %v", f.Key, err)
+			}
+		}
+		if len(environments) > 0 {
+			if err := json.Unmarshal(environments, &f.Environments); err != nil {
+				return nil, fmt.Errorf("flag %s: invalid environments: %v", f.Key, err)
+			}
+		}
+		flags = append(flags, f)
+	}
+	return flags, rows.Err()
+}
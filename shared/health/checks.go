@@ -0,0 +1,148 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"dailytrackr/shared/config"
+	"dailytrackr/shared/dto"
+)
+
+// Ping checks that a database answers
+func Ping(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// Replicas checks that every read replica is healthy; reads fall back to the
+// primary otherwise, so a failing replica only degrades the service
+func Replicas(unhealthy func() []string) CheckFunc {
+	return func(ctx context.Context) error {
+		if names := unhealthy(); len(names) > 0 {
+			return Degraded(fmt.Errorf("unreachable, reads use the primary: %s", strings.Join(names, ", ")))
+		}
+		return nil
+	}
+}
+
+// Configured checks that every value is set; use it for API keys and the like
+func Configured(values ...string) CheckFunc {
+	return func(ctx context.Context) error {
+		for _, v := range values {
+			if v == "" {
+				return errors.New("not configured")
+			}
+		}
+		return nil
+	}
+}
+
+// Reachable checks that url answers at all; any status below 500 counts,
+// since external APIs usually reject an unauthenticated probe
+func Reachable(client *http.Client, url string) CheckFunc {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// All runs checks in order and returns the first failure
+func All(checks ...CheckFunc) CheckFunc {
+	return func(ctx context.Context) error {
+		for _, check := range checks {
+			if err := check(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Remote checks another service through its /health endpoint and passes on
+// the status it reports
+func Remote(client *http.Client, url string) CheckFunc {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		var report dto.HealthResponse
+		if err := json.NewDecoder(resp.Body).Decode(&report); err != nil || report.Status == "" {
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("status %d", resp.StatusCode)
+			}
+			return nil // predates health reports
+		}
+
+		switch report.Status {
+		case StatusHealthy:
+			return nil
+		case StatusDegraded:
+			return Degraded(errors.New(StatusDegraded))
+		default:
+			return errors.New(report.Status)
+		}
+	}
+}
+
+// Cached reuses the outcome of check for ttl, for checks that call external
+// services and should not run on every probe
+func Cached(ttl time.Duration, check CheckFunc) CheckFunc {
+	var (
+		mu      sync.Mutex
+		checked time.Time
+		last    error
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !checked.IsZero() && time.Since(checked) < ttl {
+			return last
+		}
+		last = check(ctx)
+		checked = time.Now()
+		return last
+	}
+}
+
+// cloudinaryAPI is probed to tell whether Cloudinary can be reached
+const cloudinaryAPI = "https://api.cloudinary.com/v1_1/"
+
+// Cloudinary checks that photo uploads are configured and, at most every few
+// minutes, that the API can be reached
+func Cloudinary(cfg *config.Config) CheckFunc {
+	client := &http.Client{Timeout: DefaultTimeout}
+	return All(
+		Configured(cfg.CloudinaryCloudName, cfg.CloudinaryAPIKey, cfg.CloudinaryAPISecret),
+		Cached(5*time.Minute, Reachable(client, cloudinaryAPI+cfg.CloudinaryCloudName)),
+	)
+}
@@ -0,0 +1,177 @@
+// Package health runs named dependency checks and reports a service as
+// healthy, degraded (working with reduced functionality) or down.
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"dailytrackr/shared/dto"
+)
+
+// Statuses, from best to worst
+const (
+	StatusHealthy  = "healthy"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// DefaultTimeout bounds a single check
+const DefaultTimeout = 2 * time.Second
+
+// CheckFunc checks one dependency. A nil error is healthy; wrap an error with
+// Degraded to report a partial failure from a critical check.
+type CheckFunc func(ctx context.Context) error
+
+// degradedError marks a failure that does not take the service down
+type degradedError struct {
+	err error
+}
+
+func (e *degradedError) Error() string { return e.err.Error() }
+func (e *degradedError) Unwrap() error { return e.err }
+
+// Degraded marks err as a partial failure
+func Degraded(err error) error {
+	return &degradedError{err: err}
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name    string
+	Status  string
+	Message string
+}
+
+// Detail renders the result for dto.HealthResponse.Details
+func (r Result) Detail() string {
+	if r.Message == "" {
+		return r.Status
+	}
+	return r.Status + ": " + r.Message
+}
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	critical bool
+}
+
+// Registry holds the checks of one service
+type Registry struct {
+	service  string
+	version  string
+	features []string
+	timeout  time.Duration
+	checks   []check
+}
+
+// NewRegistry creates an empty registry for service
+func NewRegistry(service, version string, features ...string) *Registry {
+	return &Registry{service: service, version: version, features: features, timeout: DefaultTimeout}
+}
+
+// Critical registers a check whose failure takes the service down
+func (r *Registry) Critical(name string, fn CheckFunc) *Registry {
+	r.checks = append(r.checks, check{name: name, fn: fn, critical: true})
+	return r
+}
+
+// Optional registers a check whose failure only degrades the service
+func (r *Registry) Optional(name string, fn CheckFunc) *Registry {
+	r.checks = append(r.checks, check{name: name, fn: fn})
+	return r
+}
+
+// Run executes every check concurrently, each bounded by the registry timeout,
+// and returns the results sorted by name
+func (r *Registry) Run(ctx context.Context) []Result {
+	results := make([]Result, len(r.checks))
+
+	var wg sync.WaitGroup
+	for i, c := range r.checks {
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			results[i] = r.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results
+}
+
+func (r *Registry) run(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	// Run the check in its own goroutine so a check that ignores ctx still times out
+	done := make(chan error, 1)
+	go func() { done <- c.fn(ctx) }()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err == nil {
+		return Result{Name: c.name, Status: StatusHealthy}
+	}
+
+	var degraded *degradedError
+	status := StatusDown
+	if !c.critical || errors.As(err, &degraded) {
+		status = StatusDegraded
+	}
+	return Result{Name: c.name, Status: status, Message: err.Error()}
+}
+
+// Check runs every check and summarises them; the service takes the worst status
+func (r *Registry) Check(ctx context.Context) dto.HealthResponse {
+	results := r.Run(ctx)
+
+	response := dto.HealthResponse{
+		Service:   r.service,
+		Status:    Worst(results),
+		Version:   r.version,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Features:  r.features,
+	}
+	if len(results) > 0 {
+		response.Details = make(map[string]string, len(results))
+		for _, result := range results {
+			response.Details[result.Name] = result.Detail()
+		}
+	}
+	return response
+}
+
+// Worst returns the worst status among results, healthy when there are none
+func Worst(results []Result) string {
+	status := StatusHealthy
+	for _, result := range results {
+		switch result.Status {
+		case StatusDown:
+			return StatusDown
+		case StatusDegraded:
+			status = StatusDegraded
+		}
+	}
+	return status
+}
+
+// StatusCode maps a status to its HTTP status: a degraded service still serves
+// traffic, a down one should be taken out of rotation
+func StatusCode(status string) int {
+	if status == StatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,230 @@
+package i18n
+
+import "dailytrackr/shared/constants"
+
+// Validation message keys. Each template takes the field name first,
+// followed by the rule parameters.
+const (
+	KeyRequired        = "validation.required"
+	KeyEmail           = "validation.email"
+	KeyMinLength       = "validation.min_length"
+	KeyMaxLength       = "validation.max_length"
+	KeyMin             = "validation.min"
+	KeyMax             = "validation.max"
+	KeyOneOf           = "validation.oneof"
+	KeyDateFormat      = "validation.date_format"
+	KeyTimeFormat      = "validation.time_format"
+	KeyRFC3339         = "validation.rfc3339"
+	KeyDateGTEField    = "validation.date_gtefield"
+	KeyTimezone        = "validation.timezone"
+	KeyCurrency        = "validation.currency"
+	KeyDateWithinRange = "validation.date_within_range"
+	KeyInvalid         = "validation.invalid"
+)
+
+// catalogues maps a language to its messages. English only holds templates whose
+// key is not already the English text; every other key translates to itself.
+var catalogues = map[string]map[string]string{
+	constants.LanguageEnglish:    english,
+	constants.LanguageIndonesian: indonesian,
+}
+
+var english = map[string]string{
+	// Validation
+	KeyRequired:        "%s is required",
+	KeyEmail:           "%s must be a valid email address",
+	KeyMinLength:       "%s must be at least %s characters",
+	KeyMaxLength:       "%s must not exceed %s characters",
+	KeyMin:             "%s must be at least %s",
+	KeyMax:             "%s must not exceed %s",
+	KeyOneOf:           "%s must be one of: %s",
+	KeyDateFormat:      "%s must be a date in YYYY-MM-DD format",
+	KeyTimeFormat:      "%s must be a time in HH:MM format",
+	KeyRFC3339:         "%s must be an RFC 3339 timestamp, e.g. 2006-01-02T15:04:05Z",
+	KeyDateGTEField:    "%s must not be before %s",
+	KeyTimezone:        "%s must be an IANA time zone, e.g. Asia/Jakarta",
+	KeyCurrency:        "%s must be a supported ISO 4217 currency code, e.g. IDR",
+	KeyDateWithinRange: "%s must be between %s and %s",
+	KeyInvalid:         "%s is invalid",
+
+	// Notifications
+	constants.NotificationHabitReminder: "Time for your habit: %s",
+	constants.NotificationDailySummary:  "Your daily summary for %s is ready",
+	constants.NotificationWeeklyReport:  "Your weekly report is ready: %d activities, %.1f hours",
+
+	// Emails
+	constants.EmailVerifySubject: "Confirm your DailyTrackr email address",
+	constants.EmailResetSubject:  "Reset your DailyTrackr password",
+	constants.EmailLockedSubject: "Your DailyTrackr account has been locked",
+	constants.EmailLockedBody:    "Hi %s,\n\nYour account was locked for %s after %d failed sign-in attempts in a row, the last one from %s.\n\nIf that was you, you can unlock it now by opening the link below:\n\n%s\n\nIf it was not you, someone may be guessing your password. Consider resetting it and turning on two-factor authentication.\n",
+	constants.EmailResetBody:     "Hi %s,\n\nWe received a request to reset your password. Choose a new one by opening the link below:\n\n%s\n\nThe link expires in %s and can only be used once. Resetting your password signs you out on every device. If you did not ask for this, you can ignore this email; your password stays the same.\n",
+	constants.EmailVerifyBody:    "Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not create a DailyTrackr account, you can ignore this email.\n",
+}
+
+var indonesian = map[string]string{
+	// Error Messages - General
+	constants.ErrInvalidToken:       "token tidak valid atau sudah kedaluwarsa",
+	constants.ErrMissingToken:       "token otorisasi diperlukan",
+	constants.ErrInvalidCredentials: "email atau kata sandi salah",
+	constants.ErrUnauthorizedAccess: "akses ke sumber daya tidak diizinkan",
+	constants.ErrInvalidRequestBody: "isi permintaan tidak valid",
+	constants.ErrDatabaseConnection: "terjadi kesalahan koneksi database",
+	constants.ErrValidationFailed:   "validasi gagal",
+	constants.ErrUnexpected:         "terjadi kesalahan yang tidak terduga",
+	constants.ErrInvalidCursor:      "kursor halaman tidak valid",
+	constants.ErrRouteNotFound:      "rute tidak ditemukan",
+
+	// Error Messages - User Related
+	constants.ErrUserNotFound:            "pengguna tidak ditemukan",
+	constants.ErrEmailAlreadyExists:      "email sudah terdaftar",
+	constants.ErrUsernameExists:          "username sudah digunakan",
+	constants.ErrInvalidCurrentPassword:  "kata sandi saat ini salah",
+	constants.ErrSamePassword:            "kata sandi baru harus berbeda dari kata sandi saat ini",
+	constants.ErrWeakPassword:            "kata sandi minimal 6 karakter",
+	constants.ErrInvalidFileType:         "jenis file foto profil tidak valid",
+	constants.ErrFileTooLarge:            "ukuran file terlalu besar",
+	constants.ErrPhotoUploadFailed:       "gagal mengunggah foto profil",
+	constants.ErrPhotoServiceUnavailable: "layanan unggah foto tidak tersedia",
+	constants.ErrInvalidBio:              "bio mengandung konten yang tidak valid",
+	constants.ErrReservedUsername:        "username ini dicadangkan dan tidak dapat digunakan",
+	constants.ErrEmailNotVerified:        "verifikasi alamat email Anda untuk menggunakan fitur ini",
+	constants.ErrInvalidVerifyToken:      "tautan verifikasi tidak valid atau sudah kedaluwarsa",
+	constants.ErrEmailAlreadyVerified:    "alamat email sudah terverifikasi",
+	constants.ErrVerificationThrottled:   "email verifikasi baru saja dikirim, harap tunggu sebelum meminta lagi",
+	constants.ErrEmailDeliveryFailed:     "gagal mengirim email",
+	constants.ErrInvalidResetToken:       "tautan atur ulang kata sandi tidak valid atau sudah kedaluwarsa",
+	constants.ErrTwoFactorEnabled:        "autentikasi dua faktor sudah aktif",
+	constants.ErrTwoFactorNotEnabled:     "autentikasi dua faktor belum aktif",
+	constants.ErrTwoFactorNotEnrolled:    "mulai pendaftaran dua faktor sebelum mengonfirmasinya",
+	constants.ErrInvalidTwoFactorCode:    "kode dua faktor tidak valid",
+	constants.ErrInvalidMFAChallenge:     "tantangan masuk tidak valid atau sudah kedaluwarsa, silakan masuk kembali",
+	constants.ErrOIDCProviderNotFound:    "penyedia masuk tidak ditemukan",
+	constants.ErrOIDCProviderUnavailable: "penyedia masuk tidak tersedia",
+	constants.ErrOIDCLoginFailed:         "masuk melalui penyedia eksternal gagal, silakan coba lagi",
+	constants.ErrOIDCEmailRequired:       "penyedia masuk tidak membagikan alamat email",
+	constants.ErrOIDCEmailUnverified:     "email ini sudah digunakan akun lain; verifikasi email di penyedia Anda atau masuk dengan kata sandi",
+	constants.ErrOIDCAccountUnverified:   "akun dengan email ini belum memverifikasinya; atur ulang kata sandinya dan verifikasi email sebelum masuk dengan penyedia ini",
+	constants.ErrSessionNotFound:         "sesi tidak ditemukan",
+	constants.ErrLoginThrottled:          "terlalu banyak percobaan masuk yang gagal, harap tunggu sebelum mencoba lagi",
+	constants.ErrAccountLocked:           "akun dikunci sementara setelah terlalu banyak percobaan masuk yang gagal; periksa email Anda untuk membukanya",
+	constants.ErrInvalidUnlockToken:      "tautan buka kunci tidak valid atau sudah kedaluwarsa",
+	constants.ErrPermissionDenied:        "Anda tidak memiliki izin untuk melakukan ini",
+	constants.ErrAccountDisabled:         "akun ini telah dinonaktifkan",
+	constants.ErrInvalidRole:             "peran tidak dikenal",
+	constants.ErrOwnAccount:              "Anda tidak dapat melakukan ini pada akun Anda sendiri",
+	constants.ErrInvalidExportLink:       "tautan unduhan tidak valid atau sudah kedaluwarsa",
+	constants.ErrDeletionNotFound:        "catatan penghapusan tidak ditemukan",
+
+	// Error Messages - Activity and Habit Related
+	constants.ErrActivityNotFound: "aktivitas tidak ditemukan",
+	constants.ErrHabitNotFound:    "kebiasaan tidak ditemukan",
+	constants.ErrHabitLogNotFound: "catatan kebiasaan tidak ditemukan",
+
+	// Error Messages - Statistics Related
+	constants.ErrInvalidCurrency: "kode mata uang tidak didukung",
+	constants.ErrNoExchangeRate:  "kurs untuk mengonversi ke mata uang yang diminta tidak tersedia",
+
+	// Success Messages - User Related
+	constants.MsgUserCreated:          "pengguna berhasil dibuat",
+	constants.MsgLoginSuccess:         "berhasil masuk",
+	constants.MsgProfileUpdated:       "profil berhasil diperbarui",
+	constants.MsgPasswordChanged:      "kata sandi berhasil diubah",
+	constants.MsgProfilePhotoUploaded: "foto profil berhasil diunggah",
+	constants.MsgAccountDeleted:       "akun berhasil dihapus",
+	constants.MsgProfileRetrieved:     "profil berhasil diambil",
+	constants.MsgSettingsRetrieved:    "pengaturan berhasil diambil",
+	constants.MsgSettingsUpdated:      "pengaturan diperbarui, layanan lain menggunakannya setelah Anda masuk kembali",
+	constants.MsgUserRetrieved:        "pengguna berhasil diambil",
+	constants.MsgEmailVerified:        "email berhasil diverifikasi",
+	constants.MsgEmailVerifiedSignIn:  "email berhasil diverifikasi, silakan masuk kembali untuk memperbarui sesi Anda",
+	constants.MsgVerificationSent:     "email verifikasi telah dikirim",
+	constants.MsgPasswordResetSent:    "jika ada akun dengan email tersebut, tautan atur ulang kata sandi telah dikirim",
+	constants.MsgPasswordReset:        "kata sandi berhasil diatur ulang, silakan masuk dengan kata sandi baru Anda",
+	constants.MsgTwoFactorEnrollment:  "pindai kode QR dengan aplikasi autentikator Anda, lalu konfirmasi dengan sebuah kode",
+	constants.MsgTwoFactorEnabled:     "autentikasi dua faktor diaktifkan, simpan kode pemulihan Anda di tempat yang aman",
+	constants.MsgTwoFactorDisabled:    "autentikasi dua faktor dinonaktifkan",
+	constants.MsgMFARequired:          "masukkan kode dari aplikasi autentikator Anda untuk menyelesaikan proses masuk",
+	constants.MsgSessionsRetrieved:    "sesi berhasil diambil",
+	constants.MsgSessionRevoked:       "sesi telah dikeluarkan",
+	constants.MsgOtherSessionsRevoked: "berhasil keluar dari semua sesi lain",
+	constants.MsgAccountUnlocked:      "akun telah dibuka, Anda dapat masuk kembali",
+	constants.MsgExportPending:        "ekspor data Anda sedang disiapkan, periksa kembali sebentar lagi",
+	constants.MsgExportReady:          "ekspor data Anda siap diunduh",
+	constants.MsgExportFailed:         "ekspor data Anda gagal, minta kembali dengan refresh=true untuk mencoba lagi",
+	constants.MsgDeletionScheduled:    "akun dijadwalkan untuk dihapus; masuk sebelum waktunya untuk membatalkannya",
+	constants.MsgDeletionRetrieved:    "catatan penghapusan berhasil diambil",
+	constants.MsgUsersRetrieved:       "daftar pengguna berhasil diambil",
+	constants.MsgAccountDisabled:      "akun dinonaktifkan dan dikeluarkan dari semua sesi",
+	constants.MsgAccountEnabled:       "akun diaktifkan kembali",
+	constants.MsgTwoFactorReset:       "autentikasi dua faktor telah diatur ulang",
+	constants.MsgRolesUpdated:         "peran diperbarui, berlaku setelah pengguna masuk kembali",
+
+	// Success Messages - Activity Related
+	constants.MsgActivityCreated:     "aktivitas berhasil dibuat",
+	constants.MsgActivityUpdated:     "aktivitas berhasil diperbarui",
+	constants.MsgActivityDeleted:     "aktivitas berhasil dihapus",
+	constants.MsgActivityRetrieved:   "aktivitas berhasil diambil",
+	constants.MsgActivitiesRetrieved: "daftar aktivitas berhasil diambil",
+
+	// Success Messages - Habit Related
+	constants.MsgHabitCreated:          "kebiasaan berhasil dibuat",
+	constants.MsgHabitUpdated:          "kebiasaan berhasil diperbarui",
+	constants.MsgHabitDeleted:          "kebiasaan berhasil dihapus",
+	constants.MsgHabitLogCreated:       "catatan kebiasaan berhasil dibuat",
+	constants.MsgHabitLogUpdated:       "catatan kebiasaan berhasil diperbarui",
+	constants.MsgHabitRetrieved:        "kebiasaan berhasil diambil",
+	constants.MsgHabitsRetrieved:       "daftar kebiasaan berhasil diambil",
+	constants.MsgHabitLogsRetrieved:    "catatan kebiasaan berhasil diambil",
+	constants.MsgHabitStatsRetrieved:   "statistik kebiasaan berhasil diambil",
+	constants.MsgHabitDetailsRetrieved: "kebiasaan beserta catatan dan statistik berhasil diambil",
+
+	// Success Messages - Statistics Related
+	constants.MsgDashboardRetrieved:       "statistik dasbor berhasil diambil",
+	constants.MsgActivitySummaryRetrieved: "ringkasan aktivitas berhasil diambil",
+	constants.MsgHabitProgressRetrieved:   "progres kebiasaan berhasil diambil",
+	constants.MsgChartDataRetrieved:       "data grafik aktivitas berhasil diambil",
+	constants.MsgExpenseReportRetrieved:   "laporan pengeluaran berhasil diambil",
+	constants.MsgUsageRetrieved:           "data penggunaan berhasil diambil",
+
+	// Success Messages - AI Related
+	constants.MsgDailySummaryCached:        "ringkasan harian diambil dari cache",
+	constants.MsgDailySummaryGenerated:     "ringkasan harian berhasil dibuat",
+	constants.MsgDailySummariesRetrieved:   "ringkasan harian berhasil diambil",
+	constants.MsgRecommendationGenerated:   "rekomendasi kebiasaan berhasil dibuat",
+	constants.MsgInsightsRetrieved:         "wawasan pengguna berhasil diambil",
+	constants.MsgAnalysisCompleted:         "analisis aktivitas berhasil diselesaikan",
+	constants.MsgProductivityTipsGenerated: "tips produktivitas berhasil dibuat",
+
+	// Success Messages - General
+	constants.MsgPhotoUploaded: "foto berhasil diunggah",
+
+	// Validation
+	KeyRequired:        "%s wajib diisi",
+	KeyEmail:           "%s harus berupa alamat email yang valid",
+	KeyMinLength:       "%s minimal %s karakter",
+	KeyMaxLength:       "%s maksimal %s karakter",
+	KeyMin:             "%s minimal %s",
+	KeyMax:             "%s maksimal %s",
+	KeyOneOf:           "%s harus salah satu dari: %s",
+	KeyDateFormat:      "%s harus berupa tanggal dengan format YYYY-MM-DD",
+	KeyTimeFormat:      "%s harus berupa waktu dengan format HH:MM",
+	KeyRFC3339:         "%s harus berupa timestamp RFC 3339, mis. 2006-01-02T15:04:05Z",
+	KeyDateGTEField:    "%s tidak boleh sebelum %s",
+	KeyTimezone:        "%s harus berupa zona waktu IANA, mis. Asia/Jakarta",
+	KeyCurrency:        "%s harus berupa kode mata uang ISO 4217 yang didukung, mis. IDR",
+	KeyDateWithinRange: "%s harus di antara %s dan %s",
+	KeyInvalid:         "%s tidak valid",
+
+	// Notifications
+	constants.NotificationHabitReminder: "Saatnya menjalankan kebiasaan: %s",
+	constants.NotificationDailySummary:  "Ringkasan harian Anda untuk %s sudah siap",
+	constants.NotificationWeeklyReport:  "Laporan mingguan Anda sudah siap: %d aktivitas, %.1f jam",
+
+	// Emails
+	constants.EmailVerifySubject: "Konfirmasi alamat email DailyTrackr Anda",
+	constants.EmailResetSubject:  "Atur ulang kata sandi DailyTrackr Anda",
+	constants.EmailLockedSubject: "Akun DailyTrackr Anda telah dikunci",
+	constants.EmailLockedBody:    "Halo %s,\n\nAkun Anda dikunci selama %s setelah %d percobaan masuk gagal berturut-turut, yang terakhir dari %s.\n\nJika itu Anda, buka kunci akun sekarang dengan membuka tautan berikut:\n\n%s\n\nJika bukan Anda, seseorang mungkin sedang menebak kata sandi Anda. Pertimbangkan untuk mengatur ulang kata sandi dan mengaktifkan autentikasi dua faktor.\n",
+	constants.EmailResetBody:     "Halo %s,\n\nKami menerima permintaan untuk mengatur ulang kata sandi Anda. Buat kata sandi baru dengan membuka tautan berikut:\n\n%s\n\nTautan ini berlaku selama %s dan hanya dapat digunakan sekali. Mengatur ulang kata sandi akan mengeluarkan Anda dari semua perangkat. Jika Anda tidak memintanya, abaikan email ini; kata sandi Anda tidak berubah.\n",
+	constants.EmailVerifyBody:    "Halo %s,\n\nSilakan konfirmasi alamat email Anda dengan membuka tautan berikut:\n\n%s\n\nTautan ini berlaku selama %s dan hanya dapat digunakan sekali. Jika Anda tidak membuat akun DailyTrackr, abaikan email ini.\n",
+}
@@ -0,0 +1,93 @@
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"dailytrackr/shared/constants"
+)
+
+// Supported reports whether lang has a message catalogue
+func Supported(lang string) bool {
+	_, ok := catalogues[lang]
+	return ok
+}
+
+// Match picks the best supported language from an Accept-Language header,
+// honouring q-values and falling back to the default language
+func Match(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		// Match on the primary subtag: "id-ID" and "in" (the legacy code) are Indonesian
+		primary := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if primary == "in" {
+			primary = constants.LanguageIndonesian
+		}
+		if Supported(primary) && q > 0 {
+			candidates = append(candidates, candidate{lang: primary, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return constants.DefaultLanguage
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// Resolve returns the user's saved preference when supported, otherwise the
+// best match for the Accept-Language header
+func Resolve(preference, acceptLanguage string) string {
+	if Supported(preference) {
+		return preference
+	}
+	return Match(acceptLanguage)
+}
+
+// FromHeader returns the language a response is being written in,
+// as recorded in its Content-Language header by the language middleware
+func FromHeader(h http.Header) string {
+	if lang := h.Get(constants.ContentLanguageHeader); Supported(lang) {
+		return lang
+	}
+	return constants.DefaultLanguage
+}
+
+// T translates a message key, which is usually one of the message constants.
+// Keys without a translation are returned unchanged, so English constants
+// pass through as their own text.
+func T(lang, key string) string {
+	if message, ok := catalogues[lang][key]; ok {
+		return message
+	}
+	if message, ok := catalogues[constants.DefaultLanguage][key]; ok {
+		return message
+	}
+	return key
+}
+
+// Tf translates a message template and formats it with args
+func Tf(lang, key string, args ...interface{}) string {
+	return fmt.Sprintf(T(lang, key), args...)
+}
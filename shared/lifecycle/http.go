@@ -0,0 +1,31 @@
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+)
+
+// httpServer adapts *http.Server, which Gin services run on
+type httpServer struct {
+	srv *http.Server
+}
+
+// HTTPServer wraps srv so the runner can start and drain it.
+// When draining passes the deadline, remaining connections are closed.
+func HTTPServer(srv *http.Server) Server {
+	return &httpServer{srv: srv}
+}
+
+// Serve implements Server
+func (s *httpServer) Serve() error {
+	return s.srv.ListenAndServe()
+}
+
+// Shutdown implements Server
+func (s *httpServer) Shutdown(ctx context.Context) error {
+	if err := s.srv.Shutdown(ctx); err != nil {
+		s.srv.Close()
+		return err
+	}
+	return nil
+}
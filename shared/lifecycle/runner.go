@@ -0,0 +1,174 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Server is an HTTP server the runner starts and drains.
+// Serve blocks until the server stops; Shutdown stops accepting connections
+// and waits for in-flight requests until ctx expires.
+type Server interface {
+	Serve() error
+	Shutdown(ctx context.Context) error
+}
+
+// StopFunc releases a resource during shutdown
+type StopFunc func(ctx context.Context) error
+
+// Closer adapts a Close method (database pools, clients) into a StopFunc
+func Closer(close func() error) StopFunc {
+	return func(context.Context) error {
+		return close()
+	}
+}
+
+type namedServer struct {
+	name   string
+	server Server
+}
+
+type worker struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+type stopper struct {
+	name string
+	stop StopFunc
+}
+
+// Runner runs a service's servers and background workers until SIGINT/SIGTERM,
+// then shuts everything down in order: servers drain, workers stop, and
+// resources registered with OnStop are released in reverse registration order.
+// The whole sequence shares a single deadline.
+type Runner struct {
+	log      *slog.Logger
+	timeout  time.Duration
+	servers  []namedServer
+	workers  []worker
+	stoppers []stopper
+}
+
+// New creates a runner whose shutdown sequence is bounded by timeout
+func New(log *slog.Logger, timeout time.Duration) *Runner {
+	return &Runner{log: log, timeout: timeout}
+}
+
+// AddServer registers a server to start on Run
+func (r *Runner) AddServer(name string, server Server) {
+	r.servers = append(r.servers, namedServer{name: name, server: server})
+}
+
+// Go registers a background worker; its ctx is cancelled once the servers have drained
+func (r *Runner) Go(name string, run func(ctx context.Context) error) {
+	r.workers = append(r.workers, worker{name: name, run: run})
+}
+
+// OnStop registers a resource to release after servers and workers have stopped
+func (r *Runner) OnStop(name string, stop StopFunc) {
+	r.stoppers = append(r.stoppers, stopper{name: name, stop: stop})
+}
+
+// Run blocks until a shutdown signal arrives or a server fails, then shuts down.
+// It returns the server error, if any; shutdown problems are logged.
+func (r *Runner) Run() error {
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	serveErrs := make(chan error, len(r.servers))
+	for _, s := range r.servers {
+		go func(s namedServer) {
+			if err := s.server.Serve(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErrs <- fmt.Errorf("%s server: %w", s.name, err)
+				return
+			}
+			serveErrs <- nil
+		}(s)
+	}
+
+	var workers sync.WaitGroup
+	for _, w := range r.workers {
+		workers.Add(1)
+		go func(w worker) {
+			defer workers.Done()
+			if err := w.run(workerCtx); err != nil && !errors.Is(err, context.Canceled) {
+				r.log.Error("background worker stopped", "worker", w.name, "error", err)
+			}
+		}(w)
+	}
+
+	var runErr error
+	select {
+	case <-signalCtx.Done():
+		r.log.Info("shutdown signal received", "timeout", r.timeout)
+	case runErr = <-serveErrs:
+		if runErr != nil {
+			r.log.Error("server failed, shutting down", "error", runErr)
+		}
+	}
+
+	// Restore default signal handling so a second signal terminates immediately
+	stopSignals()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	r.shutdownServers(ctx)
+
+	stopWorkers()
+	if !waitContext(ctx, &workers) {
+		r.log.Warn("background workers still running at shutdown deadline")
+	}
+
+	for i := len(r.stoppers) - 1; i >= 0; i-- {
+		s := r.stoppers[i]
+		if err := s.stop(ctx); err != nil {
+			r.log.Error("failed to stop resource", "resource", s.name, "error", err)
+		}
+	}
+
+	r.log.Info("shutdown complete")
+	return runErr
+}
+
+// shutdownServers drains every server concurrently
+func (r *Runner) shutdownServers(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, s := range r.servers {
+		wg.Add(1)
+		go func(s namedServer) {
+			defer wg.Done()
+			if err := s.server.Shutdown(ctx); err != nil {
+				r.log.Error("server did not drain cleanly", "server", s.name, "error", err)
+			}
+		}(s)
+	}
+	wg.Wait()
+}
+
+// waitContext waits for wg, giving up when ctx expires; it reports whether wg finished
+func waitContext(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"dailytrackr/shared/config"
+)
+
+// redactedValue replaces the value of any sensitive attribute
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeys are attribute key fragments whose values are never logged
+var sensitiveKeys = []string{
+	"authorization",
+	"password",
+	"secret",
+	"token",
+	"api_key",
+	"apikey",
+	"cookie",
+}
+
+// New builds a structured logger from config.
+// LOG_LEVEL selects debug/info/warn/error and LOG_FORMAT selects text or json.
+func New(cfg *config.Config, service string) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level:       ParseLevel(cfg.LogLevel),
+		ReplaceAttr: redactAttr,
+	}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.LogFormat, "json") {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+
+	return slog.New(handler).With("service", service)
+}
+
+// Init builds the logger and installs it as the process default,
+// which also routes the standard library log package through it
+func Init(cfg *config.Config, service string) *slog.Logger {
+	l := New(cfg, service)
+	slog.SetDefault(l)
+	return l
+}
+
+// ParseLevel converts a level name into a slog.Level, defaulting to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// IsSensitive reports whether an attribute or header key holds a secret
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAttr replaces the values of sensitive attributes
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup && IsSensitive(a.Key) {
+		return slog.String(a.Key, redactedValue)
+	}
+	return a
+}
+
+// AccessEntry describes a completed HTTP request
+type AccessEntry struct {
+	Method    string
+	Route     string
+	Path      string
+	Status    int
+	Latency   time.Duration
+	UserID    int64
+	RequestID string
+	ClientIP  string
+}
+
+// LogAccess writes a uniform access log line; 5xx are errors and 4xx warnings
+func LogAccess(l *slog.Logger, e AccessEntry) {
+	level := slog.LevelInfo
+	switch {
+	case e.Status >= 500:
+		level = slog.LevelError
+	case e.Status >= 400:
+		level = slog.LevelWarn
+	}
+
+	route := e.Route
+	if route == "" {
+		route = "unmatched"
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", e.Method),
+		slog.String("route", route),
+		slog.String("path", e.Path),
+		slog.Int("status", e.Status),
+		slog.Float64("latency_ms", float64(e.Latency.Microseconds())/1000.0),
+		slog.String("request_id", e.RequestID),
+		slog.String("client_ip", e.ClientIP),
+	}
+	if e.UserID != 0 {
+		attrs = append(attrs, slog.Int64("user_id", e.UserID))
+	}
+
+	l.LogAttrs(context.Background(), level, "request", attrs...)
+}
+
+// NewRequestID generates a random request identifier
+func NewRequestID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format("150405.000000")))
+	}
+	return hex.EncodeToString(b)
+}
+
+// UserIDFrom extracts the authenticated user ID stored by the auth middleware
+func UserIDFrom(value interface{}) int64 {
+	if id, ok := value.(int64); ok {
+		return id
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+// Package mail sends transactional emails (verification links and the like)
+// over SMTP. Leave SMTP_USER empty to deliver to a local sink such as MailHog
+// or smtp4dev without authentication.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"time"
+
+	"dailytrackr/shared/config"
+	"dailytrackr/shared/tracing"
+)
+
+// sendTimeout bounds a whole delivery so a slow server cannot hold up a request
+const sendTimeout = 10 * time.Second
+
+// Message is a plain-text email to a single recipient
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPSender delivers messages through an SMTP server, upgrading to TLS when
+// the server offers STARTTLS
+type SMTPSender struct {
+	host     string
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+// NewSMTPSender creates a sender from the SMTP_* settings
+func NewSMTPSender(cfg *config.Config) *SMTPSender {
+	return &SMTPSender{
+		host:     cfg.SMTPHost,
+		addr:     net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort),
+		from:     cfg.SMTPFrom,
+		username: cfg.SMTPUser,
+		password: cfg.SMTPPassword,
+	}
+}
+
+// Send delivers msg, giving up when ctx is done or after sendTimeout
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	ctx, span := tracing.Start(ctx, "mail.Send")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	from, err := netmail.ParseAddress(s.from)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP_FROM address: %w", err)
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(compose(from.String(), msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// compose renders msg as a MIME message with a quoted-printable UTF-8 body
+func compose(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	qp.Write([]byte(msg.Text))
+	qp.Close()
+
+	return buf.Bytes()
+}
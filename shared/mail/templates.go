@@ -0,0 +1,51 @@
+package mail
+
+import (
+	"strings"
+	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/i18n"
+)
+
+// VerifyEmail builds the message asking username to confirm their address by
+// opening link, which expires after ttl
+func VerifyEmail(lang, to, username, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: i18n.T(lang, constants.EmailVerifySubject),
+		Text:    i18n.Tf(lang, constants.EmailVerifyBody, username, link, shortDuration(ttl)),
+	}
+}
+
+// ResetPassword builds the message with the link letting username choose a
+// new password, which expires after ttl
+func ResetPassword(lang, to, username, link string, ttl time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: i18n.T(lang, constants.EmailResetSubject),
+		Text:    i18n.Tf(lang, constants.EmailResetBody, username, link, shortDuration(ttl)),
+	}
+}
+
+// AccountLocked builds the notice that username's account was locked for
+// lockout after failures failed sign-ins, the last from ip, with a link to unlock it
+func AccountLocked(lang, to, username, link, ip string, failures int, lockout time.Duration) Message {
+	return Message{
+		To:      to,
+		Subject: i18n.T(lang, constants.EmailLockedSubject),
+		Text:    i18n.Tf(lang, constants.EmailLockedBody, username, shortDuration(lockout), failures, ip, link),
+	}
+}
+
+// shortDuration formats d without zero units, e.g. "24h" or "1h30m"
+func shortDuration(d time.Duration) string {
+	s := d.Round(time.Minute).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
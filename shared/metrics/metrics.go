@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "dailytrackr"
+
+// UnmatchedRoute labels requests that did not match a registered route,
+// keeping raw paths out of the label set
+const UnmatchedRoute = "unmatched"
+
+// Registry holds every metric exposed on /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by route template and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Latency of gateway calls to upstream services.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"upstream", "status"})
+
+	geminiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gemini_requests_total",
+		Help:      "Gemini API calls, by outcome.",
+	}, []string{"outcome"})
+
+	geminiErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gemini_errors_total",
+		Help:      "Failed Gemini API calls, by reason.",
+	}, []string{"reason"})
+
+	geminiDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "gemini_request_duration_seconds",
+		Help:      "Gemini API call latency.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32},
+	})
+
+	cloudinaryUploads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cloudinary_uploads_total",
+		Help:      "Cloudinary photo uploads, by kind and outcome.",
+	}, []string{"kind", "outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		upstreamDuration,
+		geminiRequests,
+		geminiErrors,
+		geminiDuration,
+		cloudinaryUploads,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveRequest records one handled HTTP request
+func ObserveRequest(method, route string, status int, latency time.Duration) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(latency.Seconds())
+}
+
+// ObserveUpstream records one proxied call; status 0 means the upstream was unreachable
+func ObserveUpstream(upstream string, status int, latency time.Duration) {
+	code := "error"
+	if status > 0 {
+		code = strconv.Itoa(status)
+	}
+	upstreamDuration.WithLabelValues(upstream, code).Observe(latency.Seconds())
+}
+
+// ObserveGemini records one Gemini call; an empty reason marks success
+func ObserveGemini(reason string, latency time.Duration) {
+	geminiDuration.Observe(latency.Seconds())
+	if reason == "" {
+		geminiRequests.WithLabelValues("success").Inc()
+		return
+	}
+	geminiRequests.WithLabelValues("error").Inc()
+	geminiErrors.WithLabelValues(reason).Inc()
+}
+
+// ObserveUpload records one Cloudinary upload attempt
+func ObserveUpload(kind string, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	cloudinaryUploads.WithLabelValues(kind, outcome).Inc()
+}
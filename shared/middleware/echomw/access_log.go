@@ -0,0 +1,56 @@
+package echomw
+
+import (
+	"log/slog"
+	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestID propagates the X-Request-ID header, generating one when absent
+func RequestID() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestID := c.Request().Header.Get(constants.RequestIDHeader)
+			if requestID == "" {
+				requestID = logger.NewRequestID()
+				c.Request().Header.Set(constants.RequestIDHeader, requestID)
+			}
+
+			c.Set("request_id", requestID)
+			c.Response().Header().Set(constants.RequestIDHeader, requestID)
+			return next(c)
+		}
+	}
+}
+
+// AccessLog writes one structured log line per request
+func AccessLog(l *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			// Let Echo's error handler write the response so the logged status is final
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			requestID, _ := c.Get("request_id").(string)
+			logger.LogAccess(l, logger.AccessEntry{
+				Method:    c.Request().Method,
+				Route:     c.Path(),
+				Path:      c.Request().URL.Path,
+				Status:    c.Response().Status,
+				Latency:   time.Since(start),
+				UserID:    logger.UserIDFrom(c.Get("user_id")),
+				RequestID: requestID,
+				ClientIP:  c.RealIP(),
+			})
+
+			return nil
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package echomw
+
+import (
+	"dailytrackr/shared/authz"
+	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// permissionsKey holds the authenticated user's permissions
+const permissionsKey = "permissions"
+
+// SetPermissions records the permissions carried by the caller's token
+func SetPermissions(c echo.Context, perms []string) {
+	c.Set(permissionsKey, perms)
+}
+
+// Permissions returns the caller's permissions; none when unauthenticated
+func Permissions(c echo.Context) []string {
+	perms, _ := c.Get(permissionsKey).([]string)
+	return perms
+}
+
+// HasPermission reports whether the caller holds perm
+func HasPermission(c echo.Context, perm string) bool {
+	return authz.Has(Permissions(c), perm)
+}
+
+// CanAccessUser reports whether the caller may act on userID's resources,
+// being that user or holding perm
+func CanAccessUser(c echo.Context, userID int64, perm string) bool {
+	id, _ := c.Get("user_id").(int64)
+	return id != 0 && authz.CanAccessUser(id, Permissions(c), userID, perm)
+}
+
+// RequirePermission answers 403 unless the caller holds perm. Register it
+// after the auth middleware.
+func RequirePermission(perm string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !HasPermission(c, perm) {
+				apperrors.WriteProblem(c.Response(), c.Request().URL.Path, apperrors.Forbidden(constants.ErrPermissionDenied))
+				return nil
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package echomw
+
+import (
+	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/flags"
+	"dailytrackr/shared/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireFlag answers 404 when the flag is off for the caller, so that routes
+// behind it look as if they did not exist. Register it after the auth
+// middleware for per-user flags.
+func RequireFlag(store *flags.Store, key string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !FlagEnabled(c, store, key) {
+				apperrors.WriteProblem(c.Response(), c.Request().URL.Path, apperrors.NotFound(constants.ErrRouteNotFound))
+				return nil
+			}
+			return next(c)
+		}
+	}
+}
+
+// FlagEnabled reports whether the flag is on for the authenticated user, if any
+func FlagEnabled(c echo.Context, store *flags.Store, key string) bool {
+	return store.Enabled(key, logger.UserIDFrom(c.Get("user_id")))
+}
@@ -0,0 +1,15 @@
+package echomw
+
+import (
+	"dailytrackr/shared/health"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Health serves the registry's report with a status code that load balancers can act on
+func Health(registry *health.Registry) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		report := registry.Check(c.Request().Context())
+		return c.JSON(health.StatusCode(report.Status), report)
+	}
+}
@@ -0,0 +1,32 @@
+package echomw
+
+import (
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/i18n"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Language negotiates the response language from Accept-Language and records it
+// in the Content-Language header, which the response helpers translate into
+func Language() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Response().Header()
+			header.Set(constants.ContentLanguageHeader, i18n.Match(c.Request().Header.Get(constants.AcceptLanguageHeader)))
+			header.Add("Vary", constants.AcceptLanguageHeader)
+			return next(c)
+		}
+	}
+}
+
+// SetLanguagePreference switches the response to the authenticated user's saved language
+func SetLanguagePreference(c echo.Context, preference string) {
+	lang := i18n.Resolve(preference, c.Request().Header.Get(constants.AcceptLanguageHeader))
+	c.Response().Header().Set(constants.ContentLanguageHeader, lang)
+}
+
+// Lang returns the language the response is written in
+func Lang(c echo.Context) string {
+	return i18n.FromHeader(c.Response().Header())
+}
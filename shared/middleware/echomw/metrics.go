@@ -0,0 +1,31 @@
+package echomw
+
+import (
+	"time"
+
+	"dailytrackr/shared/metrics"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Metrics records request count and latency labelled by route template
+func Metrics() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			// Let Echo's error handler write the response so the recorded status is final
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			metrics.ObserveRequest(c.Request().Method, c.Path(), c.Response().Status, time.Since(start))
+			return nil
+		}
+	}
+}
+
+// MetricsHandler serves the Prometheus registry
+func MetricsHandler() echo.HandlerFunc {
+	return echo.WrapHandler(metrics.Handler())
+}
@@ -0,0 +1,34 @@
+package echomw
+
+import (
+	"context"
+
+	"dailytrackr/shared/lifecycle"
+
+	"github.com/labstack/echo/v4"
+)
+
+// echoServer adapts an Echo instance for the lifecycle runner
+type echoServer struct {
+	e    *echo.Echo
+	addr string
+}
+
+// Server adapts an Echo instance listening on addr for the lifecycle runner
+func Server(e *echo.Echo, addr string) lifecycle.Server {
+	return &echoServer{e: e, addr: addr}
+}
+
+// Serve implements lifecycle.Server
+func (s *echoServer) Serve() error {
+	return s.e.Start(s.addr)
+}
+
+// Shutdown implements lifecycle.Server; connections still open at the deadline are closed
+func (s *echoServer) Shutdown(ctx context.Context) error {
+	if err := s.e.Shutdown(ctx); err != nil {
+		s.e.Close()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package echomw
+
+import (
+	"time"
+
+	"dailytrackr/shared/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// timezoneKey holds the authenticated user's *time.Location
+const timezoneKey = "timezone"
+
+// SetTimezone records the authenticated user's time zone for date arithmetic
+func SetTimezone(c echo.Context, name string) {
+	c.Set(timezoneKey, utils.Location(name))
+}
+
+// Location returns the user's time zone, or UTC when none was recorded
+func Location(c echo.Context) *time.Location {
+	if loc, ok := c.Get(timezoneKey).(*time.Location); ok {
+		return loc
+	}
+	return time.UTC
+}
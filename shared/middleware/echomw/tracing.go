@@ -0,0 +1,28 @@
+package echomw
+
+import (
+	"dailytrackr/shared/tracing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Tracing starts a server span for each request, continuing any incoming trace context.
+// Handlers reach the span through c.Request().Context().
+func Tracing() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := tracing.Extract(req.Context(), req.Header)
+			ctx, span := tracing.StartServer(ctx, req.Method, req.URL.Path)
+			c.SetRequest(req.WithContext(ctx))
+
+			// Let Echo's error handler write the response so the recorded status is final
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			tracing.EndServer(span, req.Method, c.Path(), c.Response().Status)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package fibermw
+
+import (
+	"log/slog"
+	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestID propagates the X-Request-ID header, generating one when absent
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(constants.RequestIDHeader)
+		if requestID == "" {
+			requestID = logger.NewRequestID()
+			c.Request().Header.Set(constants.RequestIDHeader, requestID)
+		}
+
+		c.Locals("request_id", requestID)
+		c.Set(constants.RequestIDHeader, requestID)
+		return c.Next()
+	}
+}
+
+// AccessLog writes one structured log line per request
+func AccessLog(l *slog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		// Let the app error handler produce the final status before logging it
+		if err != nil {
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				c.Status(fiber.StatusInternalServerError)
+			}
+		}
+
+		requestID, _ := c.Locals("request_id").(string)
+		logger.LogAccess(l, logger.AccessEntry{
+			Method:    c.Method(),
+			Route:     c.Route().Path,
+			Path:      c.Path(),
+			Status:    c.Response().StatusCode(),
+			Latency:   time.Since(start),
+			UserID:    logger.UserIDFrom(c.Locals("user_id")),
+			RequestID: requestID,
+			ClientIP:  c.IP(),
+		})
+
+		return nil
+	}
+}
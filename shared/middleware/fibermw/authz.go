@@ -0,0 +1,47 @@
+package fibermw
+
+import (
+	"dailytrackr/shared/authz"
+	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// permissionsKey holds the authenticated user's permissions
+const permissionsKey = "permissions"
+
+// SetPermissions records the permissions carried by the caller's token
+func SetPermissions(c *fiber.Ctx, perms []string) {
+	c.Locals(permissionsKey, perms)
+}
+
+// Permissions returns the caller's permissions; none when unauthenticated
+func Permissions(c *fiber.Ctx) []string {
+	perms, _ := c.Locals(permissionsKey).([]string)
+	return perms
+}
+
+// HasPermission reports whether the caller holds perm
+func HasPermission(c *fiber.Ctx, perm string) bool {
+	return authz.Has(Permissions(c), perm)
+}
+
+// CanAccessUser reports whether the caller may act on userID's resources,
+// being that user or holding perm
+func CanAccessUser(c *fiber.Ctx, userID int64, perm string) bool {
+	id, _ := c.Locals("user_id").(int64)
+	return id != 0 && authz.CanAccessUser(id, Permissions(c), userID, perm)
+}
+
+// RequirePermission answers 403 unless the caller holds perm. Register it
+// after the auth middleware.
+func RequirePermission(perm string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !HasPermission(c, perm) {
+			problem := apperrors.ToProblem(apperrors.Forbidden(constants.ErrPermissionDenied), c.Path()).Localize(Lang(c))
+			return c.Status(problem.Status).JSON(problem, apperrors.ContentTypeProblem)
+		}
+		return c.Next()
+	}
+}
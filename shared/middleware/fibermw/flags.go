@@ -0,0 +1,28 @@
+package fibermw
+
+import (
+	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/flags"
+	"dailytrackr/shared/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireFlag answers 404 when the flag is off for the caller, so that routes
+// behind it look as if they did not exist. Register it after the auth
+// middleware for per-user flags.
+func RequireFlag(store *flags.Store, key string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !FlagEnabled(c, store, key) {
+			problem := apperrors.ToProblem(apperrors.NotFound(constants.ErrRouteNotFound), c.Path()).Localize(Lang(c))
+			return c.Status(problem.Status).JSON(problem, apperrors.ContentTypeProblem)
+		}
+		return c.Next()
+	}
+}
+
+// FlagEnabled reports whether the flag is on for the authenticated user, if any
+func FlagEnabled(c *fiber.Ctx, store *flags.Store, key string) bool {
+	return store.Enabled(key, logger.UserIDFrom(c.Locals("user_id")))
+}
@@ -0,0 +1,15 @@
+package fibermw
+
+import (
+	"dailytrackr/shared/health"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Health serves the registry's report with a status code that load balancers can act on
+func Health(registry *health.Registry) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report := registry.Check(c.UserContext())
+		return c.Status(health.StatusCode(report.Status)).JSON(report)
+	}
+}
@@ -0,0 +1,31 @@
+package fibermw
+
+import (
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Language negotiates the response language from Accept-Language and records it
+// in the Content-Language header, which the response helpers translate into
+func Language() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(constants.ContentLanguageHeader, i18n.Match(c.Get(constants.AcceptLanguageHeader)))
+		c.Vary(constants.AcceptLanguageHeader)
+		return c.Next()
+	}
+}
+
+// SetLanguagePreference switches the response to the authenticated user's saved language
+func SetLanguagePreference(c *fiber.Ctx, preference string) {
+	c.Set(constants.ContentLanguageHeader, i18n.Resolve(preference, c.Get(constants.AcceptLanguageHeader)))
+}
+
+// Lang returns the language the response is written in
+func Lang(c *fiber.Ctx) string {
+	if lang := c.GetRespHeader(constants.ContentLanguageHeader); i18n.Supported(lang) {
+		return lang
+	}
+	return constants.DefaultLanguage
+}
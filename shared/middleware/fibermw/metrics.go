@@ -0,0 +1,33 @@
+package fibermw
+
+import (
+	"time"
+
+	"dailytrackr/shared/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// Metrics records request count and latency labelled by route template
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		// Let the app error handler produce the final status before recording it
+		if err != nil {
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				c.Status(fiber.StatusInternalServerError)
+			}
+		}
+
+		metrics.ObserveRequest(c.Method(), c.Route().Path, c.Response().StatusCode(), time.Since(start))
+		return nil
+	}
+}
+
+// MetricsHandler serves the Prometheus registry
+func MetricsHandler() fiber.Handler {
+	return adaptor.HTTPHandler(metrics.Handler())
+}
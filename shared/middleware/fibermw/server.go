@@ -0,0 +1,30 @@
+package fibermw
+
+import (
+	"context"
+
+	"dailytrackr/shared/lifecycle"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fiberServer adapts a Fiber app for the lifecycle runner
+type fiberServer struct {
+	app  *fiber.App
+	addr string
+}
+
+// Server adapts a Fiber app listening on addr for the lifecycle runner
+func Server(app *fiber.App, addr string) lifecycle.Server {
+	return &fiberServer{app: app, addr: addr}
+}
+
+// Serve implements lifecycle.Server
+func (s *fiberServer) Serve() error {
+	return s.app.Listen(s.addr)
+}
+
+// Shutdown implements lifecycle.Server
+func (s *fiberServer) Shutdown(ctx context.Context) error {
+	return s.app.ShutdownWithContext(ctx)
+}
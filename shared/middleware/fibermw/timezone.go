@@ -0,0 +1,25 @@
+package fibermw
+
+import (
+	"time"
+
+	"dailytrackr/shared/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// timezoneKey holds the authenticated user's *time.Location
+const timezoneKey = "timezone"
+
+// SetTimezone records the authenticated user's time zone for date arithmetic
+func SetTimezone(c *fiber.Ctx, name string) {
+	c.Locals(timezoneKey, utils.Location(name))
+}
+
+// Location returns the user's time zone, or UTC when none was recorded
+func Location(c *fiber.Ctx) *time.Location {
+	if loc, ok := c.Locals(timezoneKey).(*time.Location); ok {
+		return loc
+	}
+	return time.UTC
+}
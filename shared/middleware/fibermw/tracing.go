@@ -0,0 +1,36 @@
+package fibermw
+
+import (
+	"net/http"
+
+	"dailytrackr/shared/tracing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Tracing starts a server span for each request, continuing any incoming trace context.
+// Handlers reach the span through c.UserContext().
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := http.Header{}
+		c.Request().Header.VisitAll(func(key, value []byte) {
+			header.Add(string(key), string(value))
+		})
+
+		ctx := tracing.Extract(c.UserContext(), header)
+		ctx, span := tracing.StartServer(ctx, c.Method(), c.Path())
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// Let the app error handler produce the final status before recording it
+		if err != nil {
+			if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+				c.Status(fiber.StatusInternalServerError)
+			}
+		}
+
+		tracing.EndServer(span, c.Method(), c.Route().Path, c.Response().StatusCode())
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package ginmw
+
+import (
+	"log/slog"
+	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID propagates the X-Request-ID header, generating one when absent.
+// The ID is written back to the request so proxied calls carry it downstream.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(constants.RequestIDHeader)
+		if requestID == "" {
+			requestID = logger.NewRequestID()
+			c.Request.Header.Set(constants.RequestIDHeader, requestID)
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(constants.RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// AccessLog writes one structured log line per request
+func AccessLog(l *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		userID, _ := c.Get("user_id")
+		logger.LogAccess(l, logger.AccessEntry{
+			Method:    c.Request.Method,
+			Route:     c.FullPath(),
+			Path:      c.Request.URL.Path,
+			Status:    c.Writer.Status(),
+			Latency:   time.Since(start),
+			UserID:    logger.UserIDFrom(userID),
+			RequestID: c.GetString("request_id"),
+			ClientIP:  c.ClientIP(),
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package ginmw
+
+import (
+	"dailytrackr/shared/authz"
+	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// permissionsKey holds the authenticated user's permissions
+const permissionsKey = "permissions"
+
+// SetPermissions records the permissions carried by the caller's token
+func SetPermissions(c *gin.Context, perms []string) {
+	c.Set(permissionsKey, perms)
+}
+
+// Permissions returns the caller's permissions; none when unauthenticated
+func Permissions(c *gin.Context) []string {
+	value, _ := c.Get(permissionsKey)
+	perms, _ := value.([]string)
+	return perms
+}
+
+// HasPermission reports whether the caller holds perm
+func HasPermission(c *gin.Context, perm string) bool {
+	return authz.Has(Permissions(c), perm)
+}
+
+// CanAccessUser reports whether the caller may act on userID's resources,
+// being that user or holding perm
+func CanAccessUser(c *gin.Context, userID int64, perm string) bool {
+	callerID, _ := c.Get("user_id")
+	id, _ := callerID.(int64)
+	return id != 0 && authz.CanAccessUser(id, Permissions(c), userID, perm)
+}
+
+// RequirePermission answers 403 unless the caller holds perm. Register it
+// after the auth middleware.
+func RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasPermission(c, perm) {
+			utils.SendError(c.Writer, c.Request, apperrors.Forbidden(constants.ErrPermissionDenied))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
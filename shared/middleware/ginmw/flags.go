@@ -0,0 +1,31 @@
+package ginmw
+
+import (
+	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/flags"
+	"dailytrackr/shared/logger"
+	"dailytrackr/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireFlag answers 404 when the flag is off for the caller, so that routes
+// behind it look as if they did not exist. Register it after the auth
+// middleware for per-user flags.
+func RequireFlag(store *flags.Store, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !FlagEnabled(c, store, key) {
+			utils.SendError(c.Writer, c.Request, apperrors.NotFound(constants.ErrRouteNotFound))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// FlagEnabled reports whether the flag is on for the authenticated user, if any
+func FlagEnabled(c *gin.Context, store *flags.Store, key string) bool {
+	userID, _ := c.Get("user_id")
+	return store.Enabled(key, logger.UserIDFrom(userID))
+}
@@ -0,0 +1,15 @@
+package ginmw
+
+import (
+	"dailytrackr/shared/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Health serves the registry's report with a status code that load balancers can act on
+func Health(registry *health.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := registry.Check(c.Request.Context())
+		c.JSON(health.StatusCode(report.Status), report)
+	}
+}
@@ -0,0 +1,28 @@
+package ginmw
+
+import (
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Language negotiates the response language from Accept-Language and records it
+// in the Content-Language header, which the response helpers translate into
+func Language() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header(constants.ContentLanguageHeader, i18n.Match(c.GetHeader(constants.AcceptLanguageHeader)))
+		c.Writer.Header().Add("Vary", constants.AcceptLanguageHeader)
+		c.Next()
+	}
+}
+
+// SetLanguagePreference switches the response to the authenticated user's saved language
+func SetLanguagePreference(c *gin.Context, preference string) {
+	c.Header(constants.ContentLanguageHeader, i18n.Resolve(preference, c.GetHeader(constants.AcceptLanguageHeader)))
+}
+
+// Lang returns the language the response is written in
+func Lang(c *gin.Context) string {
+	return i18n.FromHeader(c.Writer.Header())
+}
@@ -0,0 +1,24 @@
+package ginmw
+
+import (
+	"time"
+
+	"dailytrackr/shared/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records request count and latency labelled by route template
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		metrics.ObserveRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
+
+// MetricsHandler serves the Prometheus registry
+func MetricsHandler() gin.HandlerFunc {
+	return gin.WrapH(metrics.Handler())
+}
@@ -0,0 +1,17 @@
+package ginmw
+
+import (
+	"net/http"
+
+	"dailytrackr/shared/lifecycle"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server adapts a Gin engine listening on addr for the lifecycle runner
+func Server(r *gin.Engine, addr string) lifecycle.Server {
+	return lifecycle.HTTPServer(&http.Server{
+		Addr:    addr,
+		Handler: r,
+	})
+}
@@ -0,0 +1,44 @@
+package ginmw
+
+import (
+	"time"
+
+	"dailytrackr/shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// timezoneKey holds the authenticated user's *time.Location and
+// weekStartKey the time.Weekday their weeks begin on
+const (
+	timezoneKey  = "timezone"
+	weekStartKey = "week_start"
+)
+
+// SetTimezone records the authenticated user's time zone for date arithmetic
+func SetTimezone(c *gin.Context, name string) {
+	c.Set(timezoneKey, utils.Location(name))
+}
+
+// Location returns the user's time zone, or UTC when none was recorded
+func Location(c *gin.Context) *time.Location {
+	value, _ := c.Get(timezoneKey)
+	if loc, ok := value.(*time.Location); ok {
+		return loc
+	}
+	return time.UTC
+}
+
+// SetWeekStart records the day the authenticated user's weeks begin on
+func SetWeekStart(c *gin.Context, name string) {
+	c.Set(weekStartKey, utils.WeekStart(name))
+}
+
+// WeekStart returns the day the user's weeks begin on, or Monday when none was recorded
+func WeekStart(c *gin.Context) time.Weekday {
+	value, _ := c.Get(weekStartKey)
+	if day, ok := value.(time.Weekday); ok {
+		return day
+	}
+	return time.Monday
+}
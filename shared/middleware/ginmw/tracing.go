@@ -0,0 +1,21 @@
+package ginmw
+
+import (
+	"dailytrackr/shared/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tracing starts a server span for each request, continuing any incoming trace context.
+// Handlers reach the span through c.Request.Context().
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := tracing.Extract(c.Request.Context(), c.Request.Header)
+		ctx, span := tracing.StartServer(ctx, c.Request.Method, c.Request.URL.Path)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		tracing.EndServer(span, c.Request.Method, c.FullPath(), c.Writer.Status())
+	}
+}
@@ -0,0 +1,95 @@
+// Package money represents amounts of money as integer minor units (cents,
+// sen) together with their ISO 4217 currency code, so amounts in different
+// currencies are never added up by accident.
+package money
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Money is an amount in the currency's minor units, e.g. {1550, "USD"} is $15.50
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New returns amount minor units of currency; the code is upper-cased
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// minorUnits lists the supported ISO 4217 currencies and their number of decimal digits
+var minorUnits = map[string]int{
+	"AED": 2, "AUD": 2, "BDT": 2, "BND": 2, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"CZK": 2, "DKK": 2, "EGP": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "JPY": 0, "KHR": 2, "KRW": 0, "KWD": 3, "LAK": 2, "LKR": 2,
+	"MMK": 2, "MXN": 2, "MYR": 2, "NOK": 2, "NZD": 2, "PHP": 2, "PKR": 2, "PLN": 2,
+	"SAR": 2, "SEK": 2, "SGD": 2, "THB": 2, "TRY": 2, "TWD": 2, "USD": 2, "VND": 0,
+	"ZAR": 2,
+}
+
+// Valid reports whether code is a supported ISO 4217 currency code
+func Valid(code string) bool {
+	_, ok := minorUnits[code]
+	return ok
+}
+
+// MinorUnits returns the number of decimal digits of a currency, 2 when unknown
+func MinorUnits(code string) int {
+	if digits, ok := minorUnits[code]; ok {
+		return digits
+	}
+	return 2
+}
+
+// Major returns the amount in major units, e.g. 15.5 for {1550, "USD"}
+func (m Money) Major() float64 {
+	return float64(m.Amount) / pow10(MinorUnits(m.Currency))
+}
+
+// String formats the amount with its code, e.g. "USD 15.50" or "JPY 1200"
+func (m Money) String() string {
+	digits := MinorUnits(m.Currency)
+	if digits == 0 {
+		return fmt.Sprintf("%s %d", m.Currency, m.Amount)
+	}
+	return fmt.Sprintf("%s %.*f", m.Currency, digits, m.Major())
+}
+
+// Sum adds amounts up per currency, ordered by currency code
+func Sum(amounts ...Money) []Money {
+	totals := make(map[string]int64)
+	for _, m := range amounts {
+		totals[m.Currency] += m.Amount
+	}
+
+	sums := make([]Money, 0, len(totals))
+	for currency, amount := range totals {
+		sums = append(sums, Money{Amount: amount, Currency: currency})
+	}
+	sort.Slice(sums, func(i, j int) bool { return sums[i].Currency < sums[j].Currency })
+	return sums
+}
+
+// Join formats amounts for display, e.g. "IDR 25000.00 + USD 4.50"
+func Join(amounts []Money) string {
+	if len(amounts) == 0 {
+		return "0"
+	}
+
+	parts := make([]string, len(amounts))
+	for i, m := range amounts {
+		parts[i] = m.String()
+	}
+	return strings.Join(parts, " + ")
+}
+
+func pow10(n int) float64 {
+	p := 1.0
+	for i := 0; i < n; i++ {
+		p *= 10
+	}
+	return p
+}
@@ -0,0 +1,72 @@
+package money
+
+import (
+	"context"
+	"database/sql"
+	"math"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/tracing"
+)
+
+// Migrations creates the exchange_rates table. Admins maintain it by hand: each
+// row gives the value of one major unit of a currency in a common base currency
+// (any one will do, as long as every row uses the same), e.g. USD 1, IDR 0.000062.
+var Migrations = []database.Migration{
+	{
+		ID: "money/001_exchange_rates",
+		SQL: `CREATE TABLE IF NOT EXISTS exchange_rates (
+			currency CHAR(3) PRIMARY KEY,
+			rate DECIMAL(24,12) NOT NULL,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+		)`,
+	},
+}
+
+// DB is the subset of *sql.DB the rate loader needs
+type DB interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Rates maps a currency code to the value of one major unit in the base currency
+type Rates map[string]float64
+
+// LoadRates reads the exchange_rates table
+func LoadRates(ctx context.Context, db DB) (Rates, error) {
+	ctx, span := tracing.Start(ctx, "money.LoadRates")
+	defer span.End()
+
+	rows, err := db.QueryContext(ctx, "SELECT currency, rate FROM exchange_rates WHERE rate > 0")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make(Rates)
+	for rows.Next() {
+		var currency string
+		var rate float64
+		if err := rows.Scan(&currency, &rate); err != nil {
+			return nil, err
+		}
+		rates[currency] = rate
+	}
+	return rates, rows.Err()
+}
+
+// Convert expresses m in currency to, rounded to the nearest minor unit.
+// ok is false when either currency has no rate.
+func (r Rates) Convert(m Money, to string) (converted Money, ok bool) {
+	if m.Currency == to {
+		return m, true
+	}
+
+	from, fromOK := r[m.Currency]
+	target, targetOK := r[to]
+	if !fromOK || !targetOK {
+		return Money{Currency: to}, false
+	}
+
+	major := m.Major() * from / target
+	return Money{Amount: int64(math.Round(major * pow10(MinorUnits(to)))), Currency: to}, true
+}
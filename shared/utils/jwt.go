@@ -2,8 +2,11 @@ package utils
 
 import (
 	"errors"
+	"net/http"
 	"time"
 
+	"dailytrackr/shared/constants"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -11,22 +14,32 @@ type Claims struct {
 	UserID   int64  `json:"user_id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	Language string `json:"lang,omitempty"` // saved language preference
+	Timezone string `json:"tz,omitempty"`   // IANA time zone for day boundaries
+	// WeekStart is the day the user's weeks begin on, e.g. "monday"
+	WeekStart string `json:"ws,omitempty"`
+	// Unverified marks accounts whose email is not confirmed yet; tokens
+	// issued before verification existed omit it and count as verified
+	Unverified bool `json:"unverified,omitempty"`
+	// SessionID names the user_sessions row the token belongs to, so the
+	// session can be signed out; older tokens have none
+	SessionID string `json:"sid,omitempty"`
+	// Roles and the permissions they grant, see package authz; ordinary
+	// users have only the user role, which grants none
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"perms,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT generates a new JWT token for a user
-func GenerateJWT(userID int64, username, email, secret string, expireHours int) (string, error) {
-	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		Email:    email,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expireHours))),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "dailytrackr",
-			Subject:   "user-auth",
-		},
+// GenerateJWT generates a new JWT token for the user described by claims;
+// the registered claims (expiry, issuer and so on) are filled in here
+func GenerateJWT(claims Claims, secret string, expireHours int) (string, error) {
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expireHours))),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		NotBefore: jwt.NewNumericDate(time.Now()),
+		Issuer:    "dailytrackr",
+		Subject:   "user-auth",
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -54,6 +67,24 @@ func ValidateJWT(tokenString, secret string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
+// Allows reports whether the unverified-email policy lets these claims make
+// a request with the given HTTP method. read_only, the default for unknown
+// policies, allows only safe methods; verified accounts are always allowed.
+func (c *Claims) Allows(policy, method string) bool {
+	if !c.Unverified {
+		return true
+	}
+
+	switch policy {
+	case constants.UnverifiedPolicyAllow:
+		return true
+	case constants.UnverifiedPolicyBlock:
+		return false
+	default:
+		return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+	}
+}
+
 // ExtractUserIDFromToken extracts user ID from JWT token string
 func ExtractUserIDFromToken(tokenString, secret string) (int64, error) {
 	claims, err := ValidateJWT(tokenString, secret)
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+
+	"dailytrackr/shared/constants"
+)
+
+func TestClaimsAllows(t *testing.T) {
+	tests := []struct {
+		name       string
+		unverified bool
+		policy     string
+		method     string
+		want       bool
+	}{
+		{"verified under block", false, constants.UnverifiedPolicyBlock, http.MethodPost, true},
+		{"verified under read_only", false, constants.UnverifiedPolicyReadOnly, http.MethodDelete, true},
+		{"unverified under allow", true, constants.UnverifiedPolicyAllow, http.MethodPost, true},
+		{"unverified read under block", true, constants.UnverifiedPolicyBlock, http.MethodGet, false},
+		{"unverified write under block", true, constants.UnverifiedPolicyBlock, http.MethodPost, false},
+		{"unverified GET under read_only", true, constants.UnverifiedPolicyReadOnly, http.MethodGet, true},
+		{"unverified HEAD under read_only", true, constants.UnverifiedPolicyReadOnly, http.MethodHead, true},
+		{"unverified OPTIONS under read_only", true, constants.UnverifiedPolicyReadOnly, http.MethodOptions, true},
+		{"unverified POST under read_only", true, constants.UnverifiedPolicyReadOnly, http.MethodPost, false},
+		{"unverified PUT under read_only", true, constants.UnverifiedPolicyReadOnly, http.MethodPut, false},
+		{"unverified PATCH under read_only", true, constants.UnverifiedPolicyReadOnly, http.MethodPatch, false},
+		{"unverified DELETE under read_only", true, constants.UnverifiedPolicyReadOnly, http.MethodDelete, false},
+		{"unknown policy reads", true, "sometimes", http.MethodGet, true},
+		{"unknown policy writes", true, "sometimes", http.MethodPost, false},
+		{"empty policy writes", true, "", http.MethodPost, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &Claims{UserID: 1, Unverified: tt.unverified}
+			if got := claims.Allows(tt.policy, tt.method); got != tt.want {
+				t.Errorf("Allows(%q, %q) = %v, want %v", tt.policy, tt.method, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+)
+
+// ErrInvalidCursor is returned for a cursor token that was not produced by
+// EncodeCursor or names a row key the listing cannot have produced
+var ErrInvalidCursor = errors.New(constants.ErrInvalidCursor)
+
+// Cursor marks the boundary row of a keyset page. Listings are ordered newest
+// first by (Key, ID); Key is the sort column rendered as text (start_time, date).
+type Cursor struct {
+	Key      string `json:"k"`
+	ID       int64  `json:"i"`
+	Backward bool   `json:"b,omitempty"` // true pages toward newer rows
+}
+
+// EncodeCursor renders a cursor as an opaque URL-safe token
+func EncodeCursor(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a token produced by EncodeCursor
+func DecodeCursor(token string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Key == "" || cursor.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// PageParams is a parsed listing request: a keyset cursor, or a page number for older clients
+type PageParams struct {
+	Cursor *Cursor
+	Page   int
+	Limit  int
+}
+
+// ParsePageParams reads the cursor, page and limit query values.
+// Out-of-range page and limit values fall back to their defaults, as before.
+func ParsePageParams(cursor, page, limit string) (PageParams, error) {
+	params := PageParams{Page: 1, Limit: constants.DefaultPageLimit}
+
+	if n, err := strconv.Atoi(limit); err == nil && n >= 1 && n <= constants.MaxPageLimit {
+		params.Limit = n
+	}
+
+	if cursor != "" {
+		decoded, err := DecodeCursor(cursor)
+		if err != nil {
+			return params, err
+		}
+		params.Cursor = decoded
+		params.Page = 0
+		return params, nil
+	}
+
+	if n, err := strconv.Atoi(page); err == nil && n >= 1 {
+		params.Page = n
+	}
+
+	return params, nil
+}
+
+// Offset returns the row offset for page-number requests
+func (p PageParams) Offset() int {
+	if p.Cursor != nil || p.Page < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.Limit
+}
+
+// Backward reports whether the request pages toward newer rows
+func (p PageParams) Backward() bool {
+	return p.Cursor != nil && p.Cursor.Backward
+}
+
+// NewPaginationResponse builds page metadata for one page of rows. more reports
+// whether the query found rows beyond the page in the direction it was read;
+// first and last are the cursors of the page's first and last rows (nil when empty).
+func NewPaginationResponse(params PageParams, total int, more bool, first, last *Cursor) dto.PaginationResponse {
+	response := dto.PaginationResponse{
+		Page:       params.Page,
+		Limit:      params.Limit,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(params.Limit))),
+	}
+
+	hasNext, hasPrev := more, params.Cursor != nil || params.Page > 1
+	if params.Backward() {
+		hasNext, hasPrev = true, more
+	}
+
+	if hasNext && last != nil {
+		next := *last
+		next.Backward = false
+		response.NextCursor = EncodeCursor(next)
+	}
+	if hasPrev && first != nil {
+		prev := *first
+		prev.Backward = true
+		response.PrevCursor = EncodeCursor(prev)
+	}
+
+	return response
+}
@@ -2,39 +2,56 @@ package utils
 
 import (
 	"dailytrackr/shared/dto"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/i18n"
 	"encoding/json"
+	"log/slog"
 	"net/http"
 )
 
-// SendSuccessResponse sends a successful JSON response
+// SendSuccessResponse sends a successful JSON response; message is translated into the response language
 func SendSuccessResponse(w http.ResponseWriter, message string, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 
 	response := dto.Response{
 		Success: true,
-		Message: message,
+		Message: i18n.T(i18n.FromHeader(w.Header()), message),
 		Data:    data,
 	}
 
 	json.NewEncoder(w).Encode(response)
 }
 
-// SendErrorResponse sends an error JSON response
-func SendErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// SendError sends a typed error as an RFC 7807 problem response
+func SendError(w http.ResponseWriter, r *http.Request, err error) {
+	instance := ""
+	if r != nil {
+		instance = r.URL.Path
+	}
+	apperrors.WriteProblem(w, instance, err)
+}
 
-	response := dto.ErrorResponse{
-		Success: false,
-		Message: message,
+// SendErrorResponse sends an RFC 7807 problem response for a status code and message.
+// The cause of an error is never exposed: a client error reports validation
+// failures as field details and logs any other cause, such as a decoder or SQL
+// error, keeping message as the detail; a server error's cause is logged too.
+func SendErrorResponse(w http.ResponseWriter, statusCode int, message string, err error) {
+	if appErr, ok := err.(*apperrors.Error); ok {
+		apperrors.WriteProblem(w, "", appErr)
+		return
 	}
 
-	if err != nil {
-		response.Error = err.Error()
+	appErr := apperrors.FromStatus(statusCode, message, err)
+	if statusCode < http.StatusInternalServerError && err != nil {
+		if fields := GetValidationErrors(err); len(fields) > 0 {
+			appErr.Fields = fields
+		} else {
+			slog.Info("client error", "status", statusCode, "message", message, "error", err)
+		}
 	}
 
-	json.NewEncoder(w).Encode(response)
+	apperrors.WriteProblem(w, "", appErr)
 }
 
 // SendCreatedResponse sends a 201 Created response
@@ -44,7 +61,21 @@ func SendCreatedResponse(w http.ResponseWriter, message string, data interface{}
 
 	response := dto.Response{
 		Success: true,
-		Message: message,
+		Message: i18n.T(i18n.FromHeader(w.Header()), message),
+		Data:    data,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// SendAcceptedResponse sends a 202 Accepted response, for work that finishes later
+func SendAcceptedResponse(w http.ResponseWriter, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+
+	response := dto.Response{
+		Success: true,
+		Message: i18n.T(i18n.FromHeader(w.Header()), message),
 		Data:    data,
 	}
 
@@ -61,11 +92,26 @@ func SendUnauthorizedResponse(w http.ResponseWriter, message string) {
 	SendErrorResponse(w, http.StatusUnauthorized, message, nil)
 }
 
+// SendForbiddenResponse sends a 403 Forbidden response
+func SendForbiddenResponse(w http.ResponseWriter, message string) {
+	SendErrorResponse(w, http.StatusForbidden, message, nil)
+}
+
+// SendTooManyRequestsResponse sends a 429 Too Many Requests response
+func SendTooManyRequestsResponse(w http.ResponseWriter, message string) {
+	SendErrorResponse(w, http.StatusTooManyRequests, message, nil)
+}
+
 // SendNotFoundResponse sends a 404 Not Found response
 func SendNotFoundResponse(w http.ResponseWriter, message string) {
 	SendErrorResponse(w, http.StatusNotFound, message, nil)
 }
 
+// SendConflictResponse sends a 409 Conflict response
+func SendConflictResponse(w http.ResponseWriter, message string) {
+	SendErrorResponse(w, http.StatusConflict, message, nil)
+}
+
 // SendInternalServerErrorResponse sends a 500 Internal Server Error response
 func SendInternalServerErrorResponse(w http.ResponseWriter, message string, err error) {
 	SendErrorResponse(w, http.StatusInternalServerError, message, err)
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dailytrackr/shared/constants"
+)
+
+// Location returns the IANA time zone called name, falling back to UTC when
+// name is empty, unknown or the server-dependent "Local"
+func Location(name string) *time.Location {
+	if name == "" || strings.EqualFold(name, "local") {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// ValidTimezone reports whether name is an IANA time zone users may choose
+func ValidTimezone(name string) bool {
+	if name == "" || strings.EqualFold(name, "local") {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// StartOfDay returns midnight at the start of t's day in loc
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// Today returns midnight at the start of the current day in loc
+func Today(loc *time.Location) time.Time {
+	return StartOfDay(time.Now(), loc)
+}
+
+// WeekStart returns the weekday called name ("monday", "sunday" or
+// "saturday"), falling back to Monday
+func WeekStart(name string) time.Weekday {
+	switch strings.ToLower(name) {
+	case constants.WeekStartSunday:
+		return time.Sunday
+	case constants.WeekStartSaturday:
+		return time.Saturday
+	default:
+		return time.Monday
+	}
+}
+
+// StartOfWeek returns midnight at the start of t's week in loc, for weeks
+// beginning on start
+func StartOfWeek(t time.Time, loc *time.Location, start time.Weekday) time.Time {
+	day := StartOfDay(t, loc)
+	return day.AddDate(0, 0, -(int(day.Weekday())-int(start)+7)%7)
+}
+
+// ParseDate parses a YYYY-MM-DD date as midnight in loc
+func ParseDate(value string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(constants.DateFormat, value, loc)
+}
+
+// LocalDate formats the calendar date of t in loc as YYYY-MM-DD
+func LocalDate(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(constants.DateFormat)
+}
+
+// SQLLocalTime returns a MySQL expression for a UTC DATETIME column's local
+// time in loc, with the arguments its placeholders take. Numeric offsets work
+// without the server's time zone tables; each daylight saving change between
+// from and to switches offset at its exact instant, so rows the query selects
+// from that range land on the right local day.
+func SQLLocalTime(column string, loc *time.Location, from, to time.Time) (string, []interface{}) {
+	_, offset := from.In(loc).Zone()
+	transitions := zoneTransitions(loc, from, to)
+	if len(transitions) == 0 {
+		return "CONVERT_TZ(" + column + ", '+00:00', ?)", []interface{}{sqlOffset(offset)}
+	}
+
+	var expr strings.Builder
+	args := make([]interface{}, 0, 2*len(transitions)+1)
+	expr.WriteString("CONVERT_TZ(" + column + ", '+00:00', CASE")
+	for _, at := range transitions {
+		expr.WriteString(" WHEN " + column + " < ? THEN ?")
+		args = append(args, at, sqlOffset(offset))
+		_, offset = at.In(loc).Zone()
+	}
+	expr.WriteString(" ELSE ? END)")
+	args = append(args, sqlOffset(offset))
+	return expr.String(), args
+}
+
+// zoneTransitions returns the instants between from and to at which loc's UTC
+// offset changes. Zones change offset at most a few times a year, so days are
+// stepped through and each change is narrowed down to the second.
+func zoneTransitions(loc *time.Location, from, to time.Time) []time.Time {
+	var transitions []time.Time
+	for start := from; start.Before(to); {
+		end := start.Add(24 * time.Hour)
+		if end.After(to) {
+			end = to
+		}
+		_, before := start.In(loc).Zone()
+		if _, after := end.In(loc).Zone(); after != before {
+			low, high := start, end
+			for high.Sub(low) > time.Second {
+				mid := low.Add(high.Sub(low) / 2)
+				if _, offset := mid.In(loc).Zone(); offset == before {
+					low = mid
+				} else {
+					high = mid
+				}
+			}
+			transitions = append(transitions, high.Truncate(time.Second).UTC())
+		}
+		start = end
+	}
+	return transitions
+}
+
+// sqlOffset formats a UTC offset in seconds as "+07:00"
+func sqlOffset(offset int) string {
+	sign := '+'
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+}
@@ -2,8 +2,15 @@ package utils
 
 import (
 	"errors"
+	"reflect"
 	"regexp"
 	"strings"
+	"time"
+
+	"dailytrackr/shared/constants"
+	apperrors "dailytrackr/shared/errors"
+	"dailytrackr/shared/i18n"
+	"dailytrackr/shared/money"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -12,6 +19,74 @@ var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+
+	// Report fields by their JSON names so error details match the request body
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	// Domain formats and cross-field rules
+	validate.RegisterValidation("date_format", layoutValidator(constants.DateFormat))
+	validate.RegisterValidation("time_format", layoutValidator(constants.TimeFormat))
+	validate.RegisterValidation("rfc3339", layoutValidator(time.RFC3339))
+	validate.RegisterValidation("date_gtefield", dateGTEField)
+	validate.RegisterValidation("iana_timezone", func(fl validator.FieldLevel) bool {
+		return ValidTimezone(fl.Field().String())
+	})
+	validate.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return money.Valid(fl.Field().String())
+	})
+}
+
+// layoutValidator accepts strings that parse with the given time layout
+func layoutValidator(layout string) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(layout, fl.Field().String())
+		return err == nil
+	}
+}
+
+// dateGTEField checks that a date is on or after the date in the sibling field
+// named by its JSON name, e.g. `validate:"date_gtefield=start_date"`.
+// Unparseable dates pass here and are reported by date_format instead.
+func dateGTEField(fl validator.FieldLevel) bool {
+	other, ok := fieldByJSONName(fl.Parent(), fl.Param())
+	if !ok || other.Kind() != reflect.String {
+		return false
+	}
+
+	date, err := time.Parse(constants.DateFormat, fl.Field().String())
+	if err != nil {
+		return true
+	}
+	otherDate, err := time.Parse(constants.DateFormat, other.String())
+	if err != nil {
+		return true
+	}
+
+	return !date.Before(otherDate)
+}
+
+// fieldByJSONName finds a struct field by its JSON name
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if strings.SplitN(t.Field(i).Tag.Get("json"), ",", 2)[0] == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
 }
 
 // ValidateStruct validates a struct using struct tags
@@ -19,36 +94,90 @@ func ValidateStruct(s interface{}) error {
 	return validate.Struct(s)
 }
 
-// GetValidationErrors returns formatted validation errors
-func GetValidationErrors(err error) []string {
-	var errors []string
+// GetValidationErrors converts struct validation errors into field-level error details
+func GetValidationErrors(err error) []apperrors.FieldError {
+	var fields []apperrors.FieldError
 
-	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
 		for _, fieldError := range validationErrors {
-			errors = append(errors, getFieldErrorMessage(fieldError))
+			key, args := getFieldErrorMessage(fieldError)
+			fields = append(fields, apperrors.FieldError{
+				Field:   strings.ToLower(fieldError.Field()),
+				Code:    fieldError.Tag(),
+				Message: i18n.Tf(constants.DefaultLanguage, key, args...),
+				Key:     key,
+				Args:    args,
+			})
 		}
 	}
 
-	return errors
+	return fields
+}
+
+// ValidationError turns a ValidateStruct error into a typed validation error
+func ValidationError(err error) error {
+	if fields := GetValidationErrors(err); len(fields) > 0 {
+		return apperrors.Validation(constants.ErrValidationFailed, fields...)
+	}
+	return apperrors.Wrap(err, apperrors.CodeValidation, constants.ErrValidationFailed)
+}
+
+// ValidateDateWithinRange checks that date falls within [start, end], inclusive.
+// Only calendar dates are compared, so values loaded in different locations still match.
+func ValidateDateWithinRange(field string, date, start, end time.Time) error {
+	day := date.Format(constants.DateFormat)
+	first := start.Format(constants.DateFormat)
+	last := end.Format(constants.DateFormat)
+
+	if day < first || day > last {
+		args := []interface{}{field, first, last}
+		return apperrors.Validation(constants.ErrValidationFailed, apperrors.FieldError{
+			Field:   field,
+			Code:    "date_within_range",
+			Message: i18n.Tf(constants.DefaultLanguage, i18n.KeyDateWithinRange, args...),
+			Key:     i18n.KeyDateWithinRange,
+			Args:    args,
+		})
+	}
+	return nil
 }
 
-// getFieldErrorMessage returns a user-friendly error message for field validation
-func getFieldErrorMessage(fe validator.FieldError) string {
+// getFieldErrorMessage returns the catalogue key and arguments for a field validation message
+func getFieldErrorMessage(fe validator.FieldError) (string, []interface{}) {
 	field := strings.ToLower(fe.Field())
 
 	switch fe.Tag() {
 	case "required":
-		return field + " is required"
+		return i18n.KeyRequired, []interface{}{field}
 	case "email":
-		return field + " must be a valid email address"
+		return i18n.KeyEmail, []interface{}{field}
 	case "min":
-		return field + " must be at least " + fe.Param() + " characters"
+		if fe.Kind() != reflect.String {
+			return i18n.KeyMin, []interface{}{field, fe.Param()}
+		}
+		return i18n.KeyMinLength, []interface{}{field, fe.Param()}
 	case "max":
-		return field + " must not exceed " + fe.Param() + " characters"
+		if fe.Kind() != reflect.String {
+			return i18n.KeyMax, []interface{}{field, fe.Param()}
+		}
+		return i18n.KeyMaxLength, []interface{}{field, fe.Param()}
 	case "oneof":
-		return field + " must be one of: " + fe.Param()
+		return i18n.KeyOneOf, []interface{}{field, fe.Param()}
+	case "date_format":
+		return i18n.KeyDateFormat, []interface{}{field}
+	case "time_format":
+		return i18n.KeyTimeFormat, []interface{}{field}
+	case "rfc3339":
+		return i18n.KeyRFC3339, []interface{}{field}
+	case "date_gtefield":
+		return i18n.KeyDateGTEField, []interface{}{field, fe.Param()}
+	case "iana_timezone":
+		return i18n.KeyTimezone, []interface{}{field}
+	case "currency":
+		return i18n.KeyCurrency, []interface{}{field}
 	default:
-		return field + " is invalid"
+		return i18n.KeyInvalid, []interface{}{field}
 	}
 }
 
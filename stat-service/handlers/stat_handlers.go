@@ -1,12 +1,16 @@
 package handlers
 
 import (
-	"database/sql"
+	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/money"
 	"dailytrackr/shared/utils"
 	"dailytrackr/stat-service/models"
 
@@ -19,7 +23,7 @@ type StatHandlers struct {
 }
 
 // NewStatHandlers creates a new stat handlers instance
-func NewStatHandlers(db *sql.DB, cfg *config.Config) *StatHandlers {
+func NewStatHandlers(db *database.DB, cfg *config.Config) *StatHandlers {
 	return &StatHandlers{
 		statRepo: models.NewStatRepository(db),
 		config:   cfg,
@@ -34,14 +38,14 @@ func (h *StatHandlers) GetDashboard(c *gin.Context) {
 		return
 	}
 
-	dashboard, err := h.statRepo.GetDashboardStats(userID.(int64))
+	dashboard, err := h.statRepo.GetDashboardStats(c.Request.Context(), userID.(int64), ginmw.Location(c), ginmw.WeekStart(c))
 	if err != nil {
 		// FIXED: Log the actual error for debugging
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get dashboard statistics", err)
 		return
 	}
 
-	utils.SendSuccessResponse(c.Writer, "Dashboard statistics retrieved successfully", dashboard)
+	utils.SendSuccessResponse(c.Writer, constants.MsgDashboardRetrieved, dashboard)
 }
 
 // GetActivitySummary handles getting activity summary statistics
@@ -52,40 +56,41 @@ func (h *StatHandlers) GetActivitySummary(c *gin.Context) {
 		return
 	}
 
-	// Parse date range
+	// Parse date range as days in the user's time zone
+	loc := ginmw.Location(c)
 	startDateStr := c.Query("start_date")
 	endDateStr := c.Query("end_date")
 	var startDate, endDate time.Time
 	var err error
 
 	if startDateStr != "" {
-		startDate, err = time.Parse(constants.DateFormat, startDateStr)
+		startDate, err = utils.ParseDate(startDateStr, loc)
 		if err != nil {
 			utils.SendBadRequestResponse(c.Writer, "Invalid start_date format. Use: 2006-01-02", err)
 			return
 		}
 	} else {
-		startDate = time.Now().AddDate(0, 0, -30)
+		startDate = utils.Today(loc).AddDate(0, 0, -30)
 	}
 
 	if endDateStr != "" {
-		endDate, err = time.Parse(constants.DateFormat, endDateStr)
+		endDate, err = utils.ParseDate(endDateStr, loc)
 		if err != nil {
 			utils.SendBadRequestResponse(c.Writer, "Invalid end_date format. Use: 2006-01-02", err)
 			return
 		}
 	} else {
-		endDate = time.Now()
+		endDate = utils.Today(loc)
 	}
 	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 
-	summary, err := h.statRepo.GetActivitySummary(userID.(int64), startDate, endDate)
+	summary, err := h.statRepo.GetActivitySummary(c.Request.Context(), userID.(int64), startDate, endDate, loc)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get activity summary", err)
 		return
 	}
 
-	utils.SendSuccessResponse(c.Writer, "Activity summary retrieved successfully", summary)
+	utils.SendSuccessResponse(c.Writer, constants.MsgActivitySummaryRetrieved, summary)
 }
 
 // GetHabitProgress handles getting habit progress statistics
@@ -110,9 +115,9 @@ func (h *StatHandlers) GetHabitProgress(c *gin.Context) {
 
 	var progress interface{}
 	if habitID > 0 {
-		progress, err = h.statRepo.GetSpecificHabitProgress(userID.(int64), habitID)
+		progress, err = h.statRepo.GetSpecificHabitProgress(c.Request.Context(), userID.(int64), habitID, ginmw.Location(c))
 	} else {
-		progress, err = h.statRepo.GetAllHabitsProgress(userID.(int64))
+		progress, err = h.statRepo.GetAllHabitsProgress(c.Request.Context(), userID.(int64), ginmw.Location(c))
 	}
 
 	if err != nil {
@@ -120,7 +125,7 @@ func (h *StatHandlers) GetHabitProgress(c *gin.Context) {
 		return
 	}
 
-	utils.SendSuccessResponse(c.Writer, "Habit progress retrieved successfully", progress)
+	utils.SendSuccessResponse(c.Writer, constants.MsgHabitProgressRetrieved, progress)
 }
 
 // GetActivityChart handles getting activity chart data
@@ -145,13 +150,13 @@ func (h *StatHandlers) GetActivityChart(c *gin.Context) {
 		}
 	}
 
-	chartData, err := h.statRepo.GetActivityChartData(userID.(int64), chartType, period)
+	chartData, err := h.statRepo.GetActivityChartData(c.Request.Context(), userID.(int64), chartType, period, ginmw.Location(c), ginmw.WeekStart(c))
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get chart data", err)
 		return
 	}
 
-	utils.SendSuccessResponse(c.Writer, "Activity chart data retrieved successfully", chartData)
+	utils.SendSuccessResponse(c.Writer, constants.MsgChartDataRetrieved, chartData)
 }
 
 // GetExpenseReport handles getting expense report
@@ -162,39 +167,69 @@ func (h *StatHandlers) GetExpenseReport(c *gin.Context) {
 		return
 	}
 
-	// Parse date range
+	// Parse date range as days in the user's time zone
+	loc := ginmw.Location(c)
 	startDateStr := c.Query("start_date")
 	endDateStr := c.Query("end_date")
 	var startDate, endDate time.Time
 	var err error
 
 	if startDateStr != "" {
-		startDate, err = time.Parse(constants.DateFormat, startDateStr)
+		startDate, err = utils.ParseDate(startDateStr, loc)
 		if err != nil {
 			utils.SendBadRequestResponse(c.Writer, "Invalid start_date format. Use: 2006-01-02", err)
 			return
 		}
 	} else {
-		now := time.Now()
-		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		today := utils.Today(loc)
+		startDate = today.AddDate(0, 0, 1-today.Day())
 	}
 
 	if endDateStr != "" {
-		endDate, err = time.Parse(constants.DateFormat, endDateStr)
+		endDate, err = utils.ParseDate(endDateStr, loc)
 		if err != nil {
 			utils.SendBadRequestResponse(c.Writer, "Invalid end_date format. Use: 2006-01-02", err)
 			return
 		}
 	} else {
-		endDate = time.Now()
+		endDate = utils.Today(loc)
 	}
 	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 
-	report, err := h.statRepo.GetExpenseReport(userID.(int64), startDate, endDate)
+	// Optional currency to convert every amount into
+	currency := strings.ToUpper(c.Query("currency"))
+	if currency != "" && !money.Valid(currency) {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidCurrency, nil)
+		return
+	}
+
+	report, err := h.statRepo.GetExpenseReport(c.Request.Context(), userID.(int64), startDate, endDate, loc, currency)
 	if err != nil {
+		if errors.Is(err, models.ErrNoExchangeRate) {
+			utils.SendBadRequestResponse(c.Writer, constants.ErrNoExchangeRate, err)
+			return
+		}
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get expense report", err)
 		return
 	}
 
-	utils.SendSuccessResponse(c.Writer, "Expense report retrieved successfully", report)
+	utils.SendSuccessResponse(c.Writer, constants.MsgExpenseReportRetrieved, report)
+}
+
+// GetUserUsage handles getting how much of the app any user has used; the
+// route requires the usage:read permission
+func (h *StatHandlers) GetUserUsage(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Invalid user ID", err)
+		return
+	}
+
+	usage, err := h.statRepo.GetUserUsage(c.Request.Context(), userID)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get usage", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgUsageRetrieved, usage)
 }
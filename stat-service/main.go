@@ -1,12 +1,21 @@
 package main
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"os"
 	"time"
 
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/database"
+	"dailytrackr/shared/health"
+	"dailytrackr/shared/lifecycle"
+	"dailytrackr/shared/logger"
+	"dailytrackr/shared/metrics"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/money"
+	"dailytrackr/shared/sessions"
+	"dailytrackr/shared/tracing"
 	"dailytrackr/stat-service/handlers"
 	"dailytrackr/stat-service/routes"
 
@@ -17,20 +26,44 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	log := logger.Init(cfg, "stat-service")
+
+	shutdownTracing, err := tracing.Init(cfg, "stat-service")
+	if err != nil {
+		log.Error("failed to initialise tracing", "error", err)
+		os.Exit(1)
+	}
+
+	runner := lifecycle.New(log, cfg.ShutdownTimeout)
+	runner.OnStop("tracing", shutdownTracing)
 
 	// Initialize database connection
-	db, err := database.GetMySQLConnection(cfg)
+	db, err := database.NewDB(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	runner.OnStop("database", lifecycle.Closer(db.Close))
+	metrics.RegisterDBStats(db.Stats)
+
+	// The stat service owns the exchange_rates table used to convert expense reports
+	if err := database.Migrate(context.Background(), db.DB, cfg, money.Migrations); err != nil {
+		log.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
 
 	// Setup Gin router
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(ginmw.RequestID())
+	r.Use(ginmw.Tracing())
+	r.Use(ginmw.Language())
+	r.Use(ginmw.AccessLog(log))
+	r.Use(ginmw.Metrics())
 
 	// ================================================
 	// FIXED CORS MIDDLEWARE - No AllowCredentials with wildcard
@@ -59,24 +92,27 @@ func main() {
 		c.Next()
 	})
 
+	// Prometheus metrics endpoint
+	r.GET("/metrics", ginmw.MetricsHandler())
+
 	// Health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"service": "stat-service",
-			"status":  "healthy",
-			"version": "1.0.0",
-			"cors":    "enabled",
-		})
-	})
+	checks := health.NewRegistry("stat-service", "1.0.0").
+		Critical("database", health.Ping(db.DB)).
+		Optional("database_replicas", health.Replicas(db.UnhealthyReplicas))
+	r.GET("/health", ginmw.Health(checks))
 
 	// Initialize handlers
 	statHandlers := handlers.NewStatHandlers(db, cfg)
 
 	// Setup routes
-	routes.SetupStatRoutes(r, statHandlers)
+	routes.SetupStatRoutes(r, statHandlers, sessions.NewChecker(db.DB))
 
 	// Start server
 	port := ":" + cfg.StatPort
-	log.Printf("🚀 Statistics Service starting on port %s", port)
-	log.Fatal(r.Run(port))
+	log.Info("statistics service starting", "port", cfg.StatPort)
+	runner.AddServer("http", ginmw.Server(r, port))
+	if err := runner.Run(); err != nil {
+		log.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
 }
@@ -1,169 +1,130 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/money"
+	"dailytrackr/shared/tracing"
+	"dailytrackr/shared/utils"
 )
 
-// StatRepository handles database operations for statistics
+// StatRepository handles database operations for statistics.
+// All statistics are aggregate reads, so queries are routed to read replicas.
 type StatRepository struct {
-	db *sql.DB
+	db *database.DB
 }
 
 // NewStatRepository creates a new stat repository
-func NewStatRepository(db *sql.DB) *StatRepository {
+func NewStatRepository(db *database.DB) *StatRepository {
 	return &StatRepository{db: db}
 }
 
-// Dashboard statistics structure
-type DashboardStats struct {
-	TotalActivities int     `json:"total_activities"`
-	TotalHours      float64 `json:"total_hours"`
-	TotalExpenses   int     `json:"total_expenses"`
-	ActiveHabits    int     `json:"active_habits"`
-	CompletedHabits int     `json:"completed_habits"`
-	AvgDailyHours   float64 `json:"avg_daily_hours"`
-	StreakDays      int     `json:"streak_days"`
-	ThisWeekHours   float64 `json:"this_week_hours"`
-	LastWeekHours   float64 `json:"last_week_hours"`
-	HoursGrowth     float64 `json:"hours_growth_percent"`
-}
-
-// Activity summary structure
-type ActivitySummary struct {
-	Period            string          `json:"period"`
-	TotalActivities   int             `json:"total_activities"`
-	TotalHours        float64         `json:"total_hours"`
-	TotalExpenses     int             `json:"total_expenses"`
-	AvgDuration       float64         `json:"avg_duration_mins"`
-	MostProductiveDay string          `json:"most_productive_day"`
-	TopCategories     []CategoryStats `json:"top_categories"`
-}
-
-type CategoryStats struct {
-	Category   string  `json:"category"`
-	Count      int     `json:"count"`
-	TotalHours float64 `json:"total_hours"`
-	Percentage float64 `json:"percentage"`
-}
-
-// Habit progress structures
-type HabitProgressSummary struct {
-	TotalHabits     int                   `json:"total_habits"`
-	ActiveHabits    int                   `json:"active_habits"`
-	CompletedHabits int                   `json:"completed_habits"`
-	OverallSuccess  float64               `json:"overall_success_rate"`
-	HabitDetails    []HabitProgressDetail `json:"habit_details"`
-}
-
-type HabitProgressDetail struct {
-	HabitID       int64   `json:"habit_id"`
-	Title         string  `json:"title"`
-	StartDate     string  `json:"start_date"`
-	EndDate       string  `json:"end_date"`
-	TotalDays     int     `json:"total_days"`
-	CompletedDays int     `json:"completed_days"`
-	SuccessRate   float64 `json:"success_rate"`
-	CurrentStreak int     `json:"current_streak"`
-	Status        string  `json:"status"` // active, completed, upcoming
-}
-
-// Chart data structures
-type ChartData struct {
-	Labels []string     `json:"labels"`
-	Data   []ChartPoint `json:"data"`
-}
+// Statistics shapes live in dto so service clients can decode them
+type (
+	DashboardStats       = dto.DashboardStats
+	ActivitySummary      = dto.ActivitySummary
+	CategoryStats        = dto.CategoryStats
+	HabitProgressSummary = dto.HabitProgressSummary
+	HabitProgressDetail  = dto.HabitProgressDetail
+	ChartData            = dto.ChartData
+	ChartPoint           = dto.ChartPoint
+	ExpenseReport        = dto.ExpenseReport
+	CurrencyExpenses     = dto.CurrencyExpenses
+	ExpenseDay           = dto.ExpenseDay
+	ExpenseCategory      = dto.ExpenseCategory
+	UserUsage            = dto.UserUsage
+)
 
-type ChartPoint struct {
-	Date       string  `json:"date"`
-	Hours      float64 `json:"hours"`
-	Activities int     `json:"activities"`
-	Expenses   int     `json:"expenses"`
-}
+// ErrNoExchangeRate is returned when an expense report cannot be converted
+// because a currency has no row in the exchange_rates table
+var ErrNoExchangeRate = errors.New("no exchange rate")
 
-// Expense report structure
-type ExpenseReport struct {
-	Period             string            `json:"period"`
-	TotalExpenses      int               `json:"total_expenses"`
-	AverageDaily       float64           `json:"average_daily"`
-	HighestDay         ExpenseDay        `json:"highest_day"`
-	ExpensesByCategory []ExpenseCategory `json:"expenses_by_category"`
-	DailyBreakdown     []ExpenseDay      `json:"daily_breakdown"`
-}
+// GetDashboardStats retrieves dashboard statistics for a user, with days and
+// weeks (starting on weekStart) taken in the user's time zone
+func (r *StatRepository) GetDashboardStats(ctx context.Context, userID int64, loc *time.Location, weekStart time.Weekday) (*DashboardStats, error) {
+	ctx, span := tracing.Start(ctx, "StatRepository.GetDashboardStats")
+	defer span.End()
 
-type ExpenseDay struct {
-	Date   string `json:"date"`
-	Amount int    `json:"amount"`
-	Count  int    `json:"count"`
-}
+	stats := &DashboardStats{}
 
-type ExpenseCategory struct {
-	Category   string  `json:"category"`
-	Amount     int     `json:"amount"`
-	Count      int     `json:"count"`
-	Percentage float64 `json:"percentage"`
-}
+	currency, err := r.userCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	stats.Currency = currency
 
-// GetDashboardStats retrieves dashboard statistics for a user
-func (r *StatRepository) GetDashboardStats(userID int64) (*DashboardStats, error) {
-	stats := &DashboardStats{}
+	now := time.Now()
+	today := utils.StartOfDay(now, loc)
+	todayDate := utils.LocalDate(now, loc)
+	thisWeek := utils.StartOfWeek(now, loc, weekStart)
 
-	// Total activities
-	err := r.db.QueryRow(`
+	// Total activities; expenses count costs in the user's currency only
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT COUNT(*), 
 		       COALESCE(SUM(duration_mins), 0) / 60.0,
-		       COALESCE(SUM(cost), 0)
+		       COALESCE(SUM(CASE WHEN cost_currency = ? THEN cost_amount END), 0)
 		FROM activities 
 		WHERE user_id = ?
-	`, userID).Scan(&stats.TotalActivities, &stats.TotalHours, &stats.TotalExpenses)
+	`, currency, userID).Scan(&stats.TotalActivities, &stats.TotalHours, &stats.TotalExpenses)
 	if err != nil {
 		return nil, err
 	}
 
 	// Active and completed habits
-	err = r.db.QueryRow(`
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT 
-			SUM(CASE WHEN start_date <= CURDATE() AND end_date >= CURDATE() THEN 1 ELSE 0 END) as active,
-			SUM(CASE WHEN end_date < CURDATE() THEN 1 ELSE 0 END) as completed
+			SUM(CASE WHEN start_date <= ? AND end_date >= ? THEN 1 ELSE 0 END) as active,
+			SUM(CASE WHEN end_date < ? THEN 1 ELSE 0 END) as completed
 		FROM habits 
 		WHERE user_id = ?
-	`, userID).Scan(&stats.ActiveHabits, &stats.CompletedHabits)
+	`, todayDate, todayDate, todayDate, userID).Scan(&stats.ActiveHabits, &stats.CompletedHabits)
 	if err != nil {
 		return nil, err
 	}
 
 	// Average daily hours (last 30 days)
-	err = r.db.QueryRow(`
+	since := today.AddDate(0, 0, -30)
+	localTime, args := utils.SQLLocalTime("start_time", loc, since, now)
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT COALESCE(AVG(daily_hours), 0)
 		FROM (
-			SELECT DATE(start_time) as activity_date, SUM(duration_mins) / 60.0 as daily_hours
+			SELECT DATE(`+localTime+`) as activity_date, SUM(duration_mins) / 60.0 as daily_hours
 			FROM activities 
-			WHERE user_id = ? AND start_time >= DATE_SUB(CURDATE(), INTERVAL 30 DAY)
-			GROUP BY DATE(start_time)
+			WHERE user_id = ? AND start_time >= ?
+			GROUP BY activity_date
 		) daily_stats
-	`, userID).Scan(&stats.AvgDailyHours)
+	`, append(args, userID, since)...).Scan(&stats.AvgDailyHours)
 	if err != nil {
 		return nil, err
 	}
 
 	// This week hours
-	err = r.db.QueryRow(`
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT COALESCE(SUM(duration_mins), 0) / 60.0
 		FROM activities 
-		WHERE user_id = ? AND start_time >= DATE_SUB(CURDATE(), INTERVAL WEEKDAY(CURDATE()) DAY)
-	`, userID).Scan(&stats.ThisWeekHours)
+		WHERE user_id = ? AND start_time >= ?
+	`, userID, thisWeek).Scan(&stats.ThisWeekHours)
 	if err != nil {
 		return nil, err
 	}
 
 	// Last week hours
-	err = r.db.QueryRow(`
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT COALESCE(SUM(duration_mins), 0) / 60.0
 		FROM activities 
 		WHERE user_id = ? 
-		AND start_time >= DATE_SUB(CURDATE(), INTERVAL WEEKDAY(CURDATE()) + 7 DAY)
-		AND start_time < DATE_SUB(CURDATE(), INTERVAL WEEKDAY(CURDATE()) DAY)
-	`, userID).Scan(&stats.LastWeekHours)
+		AND start_time >= ?
+		AND start_time < ?
+	`, userID, thisWeek.AddDate(0, 0, -7), thisWeek).Scan(&stats.LastWeekHours)
 	if err != nil {
 		return nil, err
 	}
@@ -173,35 +134,35 @@ func (r *StatRepository) GetDashboardStats(userID int64) (*DashboardStats, error
 		stats.HoursGrowth = ((stats.ThisWeekHours - stats.LastWeekHours) / stats.LastWeekHours) * 100
 	}
 
-	// Calculate streak days (simplified - consecutive days with activities)
-	rows, err := r.db.Query(`
-		SELECT DATE(start_time) as activity_date
+	// Calculate streak days (simplified - consecutive days with activities),
+	// which the 30-day limit keeps within the same range as the average
+	rows, err := r.db.Reader().QueryContext(ctx, `
+		SELECT DATE(`+localTime+`) as activity_date
 		FROM activities 
-		WHERE user_id = ? 
-		GROUP BY DATE(start_time)
+		WHERE user_id = ? AND start_time >= ?
+		GROUP BY activity_date
 		ORDER BY activity_date DESC
 		LIMIT 30
-	`, userID)
+	`, append(args, userID, since)...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var dates []time.Time
+	var dates []string
 	for rows.Next() {
 		var date time.Time
 		if err := rows.Scan(&date); err != nil {
 			continue
 		}
-		dates = append(dates, date)
+		dates = append(dates, date.Format(constants.DateFormat))
 	}
 
-	// Calculate streak
+	// Calculate streak, comparing calendar dates in the user's zone
 	if len(dates) > 0 {
-		today := time.Now().Truncate(24 * time.Hour)
 		for i, date := range dates {
-			expectedDate := today.AddDate(0, 0, -i)
-			if date.Truncate(24 * time.Hour).Equal(expectedDate) {
+			expectedDate := today.AddDate(0, 0, -i).Format(constants.DateFormat)
+			if date == expectedDate {
 				stats.StreakDays++
 			} else {
 				break
@@ -212,21 +173,31 @@ func (r *StatRepository) GetDashboardStats(userID int64) (*DashboardStats, error
 	return stats, nil
 }
 
-// GetActivitySummary retrieves activity summary for a date range
-func (r *StatRepository) GetActivitySummary(userID int64, startDate, endDate time.Time) (*ActivitySummary, error) {
+// GetActivitySummary retrieves activity summary for a date range, naming the
+// most productive day as seen in loc
+func (r *StatRepository) GetActivitySummary(ctx context.Context, userID int64, startDate, endDate time.Time, loc *time.Location) (*ActivitySummary, error) {
+	ctx, span := tracing.Start(ctx, "StatRepository.GetActivitySummary")
+	defer span.End()
+
+	currency, err := r.userCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	summary := &ActivitySummary{
-		Period: startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
+		Period:   startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
+		Currency: currency,
 	}
 
-	// Basic stats
-	err := r.db.QueryRow(`
+	// Basic stats; expenses count costs in the user's currency only
+	err = r.db.Reader().QueryRowContext(ctx, `
 		SELECT COUNT(*), 
 		       COALESCE(SUM(duration_mins), 0) / 60.0,
-		       COALESCE(SUM(cost), 0),
+		       COALESCE(SUM(CASE WHEN cost_currency = ? THEN cost_amount END), 0),
 		       COALESCE(AVG(duration_mins), 0)
 		FROM activities 
 		WHERE user_id = ? AND start_time BETWEEN ? AND ?
-	`, userID, startDate, endDate).Scan(
+	`, currency, userID, startDate, endDate).Scan(
 		&summary.TotalActivities,
 		&summary.TotalHours,
 		&summary.TotalExpenses,
@@ -237,14 +208,15 @@ func (r *StatRepository) GetActivitySummary(userID int64, startDate, endDate tim
 	}
 
 	// Most productive day
-	err = r.db.QueryRow(`
-		SELECT DAYNAME(start_time), SUM(duration_mins) as total_mins
+	localTime, args := utils.SQLLocalTime("start_time", loc, startDate, endDate)
+	err = r.db.Reader().QueryRowContext(ctx, `
+		SELECT DAYNAME(`+localTime+`) as day_name, SUM(duration_mins) as total_mins
 		FROM activities 
 		WHERE user_id = ? AND start_time BETWEEN ? AND ?
-		GROUP BY DAYNAME(start_time), WEEKDAY(start_time)
+		GROUP BY day_name
 		ORDER BY total_mins DESC
 		LIMIT 1
-	`, userID, startDate, endDate).Scan(&summary.MostProductiveDay, new(int))
+	`, append(args, userID, startDate, endDate)...).Scan(&summary.MostProductiveDay, new(int))
 	if err != nil && err != sql.ErrNoRows {
 		return nil, err
 	}
@@ -252,31 +224,36 @@ func (r *StatRepository) GetActivitySummary(userID int64, startDate, endDate tim
 	return summary, nil
 }
 
-// GetAllHabitsProgress retrieves progress for all user habits
-func (r *StatRepository) GetAllHabitsProgress(userID int64) (*HabitProgressSummary, error) {
+// GetAllHabitsProgress retrieves progress for all user habits, judging which
+// are active against the current date in loc
+func (r *StatRepository) GetAllHabitsProgress(ctx context.Context, userID int64, loc *time.Location) (*HabitProgressSummary, error) {
+	ctx, span := tracing.Start(ctx, "StatRepository.GetAllHabitsProgress")
+	defer span.End()
+
 	summary := &HabitProgressSummary{}
+	today := utils.LocalDate(time.Now(), loc)
 
 	// Get habit counts
-	err := r.db.QueryRow(`
+	err := r.db.Reader().QueryRowContext(ctx, `
 		SELECT 
 			COUNT(*) as total,
-			SUM(CASE WHEN start_date <= CURDATE() AND end_date >= CURDATE() THEN 1 ELSE 0 END) as active,
-			SUM(CASE WHEN end_date < CURDATE() THEN 1 ELSE 0 END) as completed
+			SUM(CASE WHEN start_date <= ? AND end_date >= ? THEN 1 ELSE 0 END) as active,
+			SUM(CASE WHEN end_date < ? THEN 1 ELSE 0 END) as completed
 		FROM habits 
 		WHERE user_id = ?
-	`, userID).Scan(&summary.TotalHabits, &summary.ActiveHabits, &summary.CompletedHabits)
+	`, today, today, today, userID).Scan(&summary.TotalHabits, &summary.ActiveHabits, &summary.CompletedHabits)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get habit details
-	rows, err := r.db.Query(`
+	rows, err := r.db.Reader().QueryContext(ctx, `
 		SELECT h.id, h.title, h.start_date, h.end_date,
 		       COALESCE(stats.total_days, 0) as total_days,
 		       COALESCE(stats.completed_days, 0) as completed_days,
 		       CASE 
-		           WHEN h.start_date > CURDATE() THEN 'upcoming'
-		           WHEN h.end_date < CURDATE() THEN 'completed'
+		           WHEN h.start_date > ? THEN 'upcoming'
+		           WHEN h.end_date < ? THEN 'completed'
 		           ELSE 'active'
 		       END as status
 		FROM habits h
@@ -289,7 +266,7 @@ func (r *StatRepository) GetAllHabitsProgress(userID int64) (*HabitProgressSumma
 		) stats ON h.id = stats.habit_id
 		WHERE h.user_id = ?
 		ORDER BY h.created_at DESC
-	`, userID)
+	`, today, today, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -325,7 +302,7 @@ func (r *StatRepository) GetAllHabitsProgress(userID int64) (*HabitProgressSumma
 		}
 
 		// Calculate current streak (simplified)
-		detail.CurrentStreak = r.calculateCurrentStreak(detail.HabitID)
+		detail.CurrentStreak = r.calculateCurrentStreak(ctx, detail.HabitID, utils.Today(loc))
 
 		summary.HabitDetails = append(summary.HabitDetails, detail)
 	}
@@ -338,18 +315,23 @@ func (r *StatRepository) GetAllHabitsProgress(userID int64) (*HabitProgressSumma
 	return summary, nil
 }
 
-// GetSpecificHabitProgress retrieves progress for a specific habit
-func (r *StatRepository) GetSpecificHabitProgress(userID, habitID int64) (*HabitProgressDetail, error) {
+// GetSpecificHabitProgress retrieves progress for a specific habit, judging its
+// status against the current date in loc
+func (r *StatRepository) GetSpecificHabitProgress(ctx context.Context, userID, habitID int64, loc *time.Location) (*HabitProgressDetail, error) {
+	ctx, span := tracing.Start(ctx, "StatRepository.GetSpecificHabitProgress")
+	defer span.End()
+
 	detail := &HabitProgressDetail{}
 	var startDate, endDate time.Time
+	today := utils.LocalDate(time.Now(), loc)
 
-	err := r.db.QueryRow(`
+	err := r.db.Reader().QueryRowContext(ctx, `
 		SELECT h.id, h.title, h.start_date, h.end_date,
 		       COALESCE(stats.total_days, 0) as total_days,
 		       COALESCE(stats.completed_days, 0) as completed_days,
 		       CASE 
-		           WHEN h.start_date > CURDATE() THEN 'upcoming'
-		           WHEN h.end_date < CURDATE() THEN 'completed'
+		           WHEN h.start_date > ? THEN 'upcoming'
+		           WHEN h.end_date < ? THEN 'completed'
 		           ELSE 'active'
 		       END as status
 		FROM habits h
@@ -362,7 +344,7 @@ func (r *StatRepository) GetSpecificHabitProgress(userID, habitID int64) (*Habit
 			GROUP BY habit_id
 		) stats ON h.id = stats.habit_id
 		WHERE h.user_id = ? AND h.id = ?
-	`, habitID, userID, habitID).Scan(
+	`, today, today, habitID, userID, habitID).Scan(
 		&detail.HabitID,
 		&detail.Title,
 		&startDate,
@@ -382,66 +364,88 @@ func (r *StatRepository) GetSpecificHabitProgress(userID, habitID int64) (*Habit
 		detail.SuccessRate = float64(detail.CompletedDays) / float64(detail.TotalDays) * 100
 	}
 
-	detail.CurrentStreak = r.calculateCurrentStreak(detail.HabitID)
+	detail.CurrentStreak = r.calculateCurrentStreak(ctx, detail.HabitID, utils.Today(loc))
 
 	return detail, nil
 }
 
-// GetActivityChartData retrieves chart data for activities
-func (r *StatRepository) GetActivityChartData(userID int64, chartType string, period int) (*ChartData, error) {
-	chart := &ChartData{}
+// GetActivityChartData retrieves chart data for activities, bucketed by the
+// day, week (starting on weekStart) or month in the user's time zone
+func (r *StatRepository) GetActivityChartData(ctx context.Context, userID int64, chartType string, period int, loc *time.Location, weekStart time.Weekday) (*ChartData, error) {
+	ctx, span := tracing.Start(ctx, "StatRepository.GetActivityChartData")
+	defer span.End()
+
+	currency, err := r.userCurrency(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	chart := &ChartData{Currency: currency}
+
+	now := time.Now()
+	today := utils.StartOfDay(now, loc)
 
 	var query string
 	var args []interface{}
 
 	switch chartType {
 	case "daily":
+		since := today.AddDate(0, 0, -period)
+		localTime, zoneArgs := utils.SQLLocalTime("start_time", loc, since, now)
 		query = `
-			SELECT DATE(start_time) as chart_date,
+			SELECT DATE(` + localTime + `) as chart_date,
 			       SUM(duration_mins) / 60.0 as hours,
 			       COUNT(*) as activities,
-			       COALESCE(SUM(cost), 0) as expenses
+			       COALESCE(SUM(CASE WHEN cost_currency = ? THEN cost_amount END), 0) as expenses
 			FROM activities 
-			WHERE user_id = ? AND start_time >= DATE_SUB(CURDATE(), INTERVAL ? DAY)
-			GROUP BY DATE(start_time)
+			WHERE user_id = ? AND start_time >= ?
+			GROUP BY chart_date
 			ORDER BY chart_date DESC
 			LIMIT ?
 		`
-		args = []interface{}{userID, period, period}
+		args = append(zoneArgs, currency, userID, since, period)
 
 	case "weekly":
+		since := today.AddDate(0, 0, -7*period)
+		localTime, zoneArgs := utils.SQLLocalTime("start_time", loc, since, now)
 		query = `
-			SELECT DATE_SUB(DATE(start_time), INTERVAL WEEKDAY(start_time) DAY) as week_start,
+			SELECT DATE_SUB(DATE(local_time), INTERVAL (WEEKDAY(local_time) - ? + 7) % 7 DAY) as week_start,
 			       SUM(duration_mins) / 60.0 as hours,
 			       COUNT(*) as activities,
-			       COALESCE(SUM(cost), 0) as expenses
-			FROM activities 
-			WHERE user_id = ? AND start_time >= DATE_SUB(CURDATE(), INTERVAL ? WEEK)
+			       COALESCE(SUM(CASE WHEN cost_currency = ? THEN cost_amount END), 0) as expenses
+			FROM (
+				SELECT ` + localTime + ` as local_time, duration_mins, cost_amount, cost_currency
+				FROM activities 
+				WHERE user_id = ? AND start_time >= ?
+			) local_activities
 			GROUP BY week_start
 			ORDER BY week_start DESC
 			LIMIT ?
 		`
-		args = []interface{}{userID, period, period}
+		// WEEKDAY counts from Monday = 0
+		weekStartDay := (int(weekStart) + 6) % 7
+		args = append(append([]interface{}{weekStartDay, currency}, zoneArgs...), userID, since, period)
 
 	case "monthly":
+		since := today.AddDate(0, -period, 0)
+		localTime, zoneArgs := utils.SQLLocalTime("start_time", loc, since, now)
 		query = `
-			SELECT DATE_FORMAT(start_time, '%Y-%m-01') as month_start,
+			SELECT DATE_FORMAT(` + localTime + `, '%Y-%m-01') as month_start,
 			       SUM(duration_mins) / 60.0 as hours,
 			       COUNT(*) as activities,
-			       COALESCE(SUM(cost), 0) as expenses
+			       COALESCE(SUM(CASE WHEN cost_currency = ? THEN cost_amount END), 0) as expenses
 			FROM activities 
-			WHERE user_id = ? AND start_time >= DATE_SUB(CURDATE(), INTERVAL ? MONTH)
+			WHERE user_id = ? AND start_time >= ?
 			GROUP BY month_start
 			ORDER BY month_start DESC
 			LIMIT ?
 		`
-		args = []interface{}{userID, period, period}
+		args = append(zoneArgs, currency, userID, since, period)
 
 	default:
 		return nil, sql.ErrNoRows
 	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.Reader().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -464,80 +468,174 @@ func (r *StatRepository) GetActivityChartData(userID int64, chartType string, pe
 	return chart, nil
 }
 
-// GetExpenseReport retrieves expense report for a date range
-func (r *StatRepository) GetExpenseReport(userID int64, startDate, endDate time.Time) (*ExpenseReport, error) {
-	report := &ExpenseReport{
-		Period: startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
-	}
+// GetExpenseReport retrieves expense report for a date range, with daily
+// totals grouped by the calendar day in loc. Spending is reported per currency;
+// when currency is set, every amount is converted into it using the
+// exchange_rates table instead, failing with ErrNoExchangeRate if a rate is missing.
+func (r *StatRepository) GetExpenseReport(ctx context.Context, userID int64, startDate, endDate time.Time, loc *time.Location, currency string) (*ExpenseReport, error) {
+	ctx, span := tracing.Start(ctx, "StatRepository.GetExpenseReport")
+	defer span.End()
 
-	// Total expenses
-	err := r.db.QueryRow(`
-		SELECT COALESCE(SUM(cost), 0), COUNT(*)
-		FROM activities 
-		WHERE user_id = ? AND start_time BETWEEN ? AND ? AND cost IS NOT NULL
-	`, userID, startDate, endDate).Scan(&report.TotalExpenses, new(int))
-	if err != nil {
-		return nil, err
-	}
-
-	// Calculate average daily
-	days := int(endDate.Sub(startDate).Hours()/24) + 1
-	if days > 0 {
-		report.AverageDaily = float64(report.TotalExpenses) / float64(days)
+	report := &ExpenseReport{
+		Period:     startDate.Format("2006-01-02") + " to " + endDate.Format("2006-01-02"),
+		Converted:  currency != "",
+		Currencies: []CurrencyExpenses{},
 	}
 
-	// Highest expense day
-	err = r.db.QueryRow(`
-		SELECT DATE(start_time), SUM(cost), COUNT(*)
-		FROM activities 
-		WHERE user_id = ? AND start_time BETWEEN ? AND ? AND cost IS NOT NULL
-		GROUP BY DATE(start_time)
-		ORDER BY SUM(cost) DESC
-		LIMIT 1
-	`, userID, startDate, endDate).Scan(
-		new(time.Time),
-		&report.HighestDay.Amount,
-		&report.HighestDay.Count,
-	)
-	if err != nil && err != sql.ErrNoRows {
-		return nil, err
+	var rates money.Rates
+	if currency != "" {
+		var err error
+		if rates, err = money.LoadRates(ctx, r.db.Reader()); err != nil {
+			return nil, err
+		}
 	}
 
-	// Daily breakdown
-	rows, err := r.db.Query(`
-		SELECT DATE(start_time) as expense_date, 
-		       COALESCE(SUM(cost), 0) as amount,
+	// Daily breakdown per currency
+	localTime, args := utils.SQLLocalTime("start_time", loc, startDate, endDate)
+	rows, err := r.db.Reader().QueryContext(ctx, `
+		SELECT DATE(`+localTime+`) as expense_date, 
+		       cost_currency,
+		       SUM(cost_amount) as amount,
 		       COUNT(*) as count
 		FROM activities 
-		WHERE user_id = ? AND start_time BETWEEN ? AND ? AND cost IS NOT NULL
-		GROUP BY DATE(start_time)
+		WHERE user_id = ? AND start_time BETWEEN ? AND ? AND cost_amount IS NOT NULL
+		GROUP BY expense_date, cost_currency
 		ORDER BY expense_date DESC
-	`, userID, startDate, endDate)
+	`, append(args, userID, startDate, endDate)...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	groups := make(map[string]*CurrencyExpenses)
+	missing := make(map[string]bool)
 	for rows.Next() {
-		var day ExpenseDay
 		var date time.Time
+		var amount money.Money
+		var count int
 
-		err := rows.Scan(&date, &day.Amount, &day.Count)
-		if err != nil {
+		if err := rows.Scan(&date, &amount.Currency, &amount.Amount, &count); err != nil {
 			continue
 		}
 
-		day.Date = date.Format("2006-01-02")
-		report.DailyBreakdown = append(report.DailyBreakdown, day)
+		if currency != "" {
+			converted, ok := rates.Convert(amount, currency)
+			if !ok {
+				missing[amount.Currency] = true
+				continue
+			}
+			amount = converted
+		}
+
+		group, ok := groups[amount.Currency]
+		if !ok {
+			group = &CurrencyExpenses{Currency: amount.Currency}
+			groups[amount.Currency] = group
+		}
+
+		// Rows come newest first, so converted amounts of one day are adjacent
+		day := date.Format("2006-01-02")
+		if n := len(group.DailyBreakdown); n > 0 && group.DailyBreakdown[n-1].Date == day {
+			group.DailyBreakdown[n-1].Amount += int(amount.Amount)
+			group.DailyBreakdown[n-1].Count += count
+		} else {
+			group.DailyBreakdown = append(group.DailyBreakdown, ExpenseDay{Date: day, Amount: int(amount.Amount), Count: count})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
+	if len(missing) > 0 {
+		codes := make([]string, 0, len(missing))
+		for code := range missing {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return nil, fmt.Errorf("%w from %s to %s", ErrNoExchangeRate, strings.Join(codes, ", "), currency)
+	}
+	if currency != "" && len(groups) == 0 {
+		groups[currency] = &CurrencyExpenses{Currency: currency}
+	}
+
+	// Totals, average daily and highest day per currency
+	days := int(endDate.Sub(startDate).Hours()/24) + 1
+	for _, group := range groups {
+		for _, day := range group.DailyBreakdown {
+			group.TotalExpenses += day.Amount
+			if day.Amount > group.HighestDay.Amount {
+				group.HighestDay = day
+			}
+		}
+		if days > 0 {
+			group.AverageDaily = float64(group.TotalExpenses) / float64(days)
+		}
+		report.Currencies = append(report.Currencies, *group)
+	}
+	sort.Slice(report.Currencies, func(i, j int) bool {
+		return report.Currencies[i].Currency < report.Currencies[j].Currency
+	})
+
 	return report, nil
 }
 
-// calculateCurrentStreak calculates the current streak for a habit
-func (r *StatRepository) calculateCurrentStreak(habitID int64) int {
-	rows, err := r.db.Query(`
-		SELECT status FROM habit_logs 
+// GetUserUsage counts what a user has recorded across the services, for administrators
+func (r *StatRepository) GetUserUsage(ctx context.Context, userID int64) (*UserUsage, error) {
+	ctx, span := tracing.Start(ctx, "StatRepository.GetUserUsage")
+	defer span.End()
+
+	usage := &UserUsage{UserID: userID}
+
+	var lastActivity, lastHabitLog sql.NullTime
+	err := r.db.Reader().QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(duration_mins), 0), MAX(start_time)
+		FROM activities 
+		WHERE user_id = ?
+	`, userID).Scan(&usage.Activities, &usage.ActivityMinutes, &lastActivity)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Reader().QueryRowContext(ctx, `
+		SELECT COUNT(DISTINCT h.id), COUNT(hl.id), MAX(hl.created_at)
+		FROM habits h
+		LEFT JOIN habit_logs hl ON hl.habit_id = h.id
+		WHERE h.user_id = ?
+	`, userID).Scan(&usage.Habits, &usage.HabitLogs, &lastHabitLog)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Reader().QueryRowContext(ctx, "SELECT COUNT(*) FROM daily_summary WHERE user_id = ?", userID).Scan(&usage.DailySummaries)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastActivity.Valid {
+		usage.LastActivityAt = &lastActivity.Time
+	}
+	if lastHabitLog.Valid {
+		usage.LastHabitLogAt = &lastHabitLog.Time
+	}
+
+	return usage, nil
+}
+
+// userCurrency returns the default currency saved in a user's profile
+func (r *StatRepository) userCurrency(ctx context.Context, userID int64) (string, error) {
+	var currency string
+	err := r.db.Reader().QueryRowContext(ctx, "SELECT currency FROM users WHERE id = ?", userID).Scan(&currency)
+	if err == sql.ErrNoRows {
+		return constants.DefaultCurrency, nil
+	}
+	return currency, err
+}
+
+// calculateCurrentStreak calculates the current streak for a habit: consecutive
+// DONE days ending today, or yesterday while today has not been logged yet
+func (r *StatRepository) calculateCurrentStreak(ctx context.Context, habitID int64, today time.Time) int {
+	rows, err := r.db.Reader().QueryContext(ctx, `
+		SELECT date, status FROM habit_logs 
 		WHERE habit_id = ? 
 		ORDER BY date DESC 
 		LIMIT 30
@@ -548,17 +646,24 @@ func (r *StatRepository) calculateCurrentStreak(habitID int64) int {
 	defer rows.Close()
 
 	streak := 0
+	expected := today
 	for rows.Next() {
+		var date time.Time
 		var status string
-		if err := rows.Scan(&status); err != nil {
+		if err := rows.Scan(&date, &status); err != nil {
 			break
 		}
 
-		if status == "DONE" {
-			streak++
-		} else {
+		day := date.Format(constants.DateFormat)
+		if streak == 0 && day != expected.Format(constants.DateFormat) {
+			expected = expected.AddDate(0, 0, -1)
+		}
+		if status != "DONE" || day != expected.Format(constants.DateFormat) {
 			break
 		}
+
+		streak++
+		expected = expected.AddDate(0, 0, -1)
 	}
 
 	return streak
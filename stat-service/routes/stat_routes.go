@@ -1,8 +1,11 @@
 package routes
 
 import (
+	"dailytrackr/shared/authz"
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/sessions"
 	"dailytrackr/shared/utils"
 	"dailytrackr/stat-service/handlers"
 	"strings"
@@ -11,7 +14,7 @@ import (
 )
 
 // AuthMiddleware validates JWT tokens for Gin (stat service specific)
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(checker *sessions.Checker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get authorization header
 		authHeader := c.GetHeader(constants.AuthorizationHeader)
@@ -45,20 +48,44 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens signed out since they were issued, e.g. by a password reset
+		active, err := checker.Active(c.Request.Context(), claims)
+		if err != nil {
+			utils.SendInternalServerErrorResponse(c.Writer, "Failed to check session", err)
+			c.Abort()
+			return
+		}
+		if !active {
+			utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+			c.Abort()
+			return
+		}
+
+		// Unverified accounts may only do what UNVERIFIED_POLICY allows
+		if !claims.Allows(cfg.UnverifiedPolicy, c.Request.Method) {
+			utils.SendForbiddenResponse(c.Writer, constants.ErrEmailNotVerified)
+			c.Abort()
+			return
+		}
+
 		// Set user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
+		ginmw.SetLanguagePreference(c, claims.Language)
+		ginmw.SetTimezone(c, claims.Timezone)
+		ginmw.SetWeekStart(c, claims.WeekStart)
+		ginmw.SetPermissions(c, claims.Permissions)
 
 		c.Next()
 	}
 }
 
 // SetupStatRoutes sets up all statistics-related routes
-func SetupStatRoutes(r *gin.Engine, statHandlers *handlers.StatHandlers) {
+func SetupStatRoutes(r *gin.Engine, statHandlers *handlers.StatHandlers, checker *sessions.Checker) {
 	// API v1 routes with authentication
 	api := r.Group("/api/v1")
-	api.Use(AuthMiddleware())
+	api.Use(AuthMiddleware(checker))
 
 	// Statistics routes
 	stats := api.Group("/stats")
@@ -84,5 +111,8 @@ func SetupStatRoutes(r *gin.Engine, statHandlers *handlers.StatHandlers) {
 		{
 			expenses.GET("/report", statHandlers.GetExpenseReport)
 		}
+
+		// Any user's usage, for support staff and administrators
+		stats.GET("/users/:id/usage", ginmw.RequirePermission(authz.PermUsageRead), statHandlers.GetUserUsage)
 	}
 }
@@ -0,0 +1,41 @@
+package consumers
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"dailytrackr/shared/events"
+	"dailytrackr/user-service/models"
+)
+
+// ConsumerName identifies the user service's offset on the event stream
+const ConsumerName = "user-service"
+
+// PurgeEvents completes account deletions as the other services confirm them
+type PurgeEvents struct {
+	deletionRepo *models.AccountDeletionRepository
+}
+
+// NewPurgeEvents creates a new purge events consumer
+func NewPurgeEvents(db *sql.DB) *PurgeEvents {
+	return &PurgeEvents{deletionRepo: models.NewAccountDeletionRepository(db)}
+}
+
+// Handler routes the events this service consumes
+func (h *PurgeEvents) Handler() events.Handler {
+	return events.Route(map[string]events.Handler{
+		events.UserPurged: h.userPurged,
+	})
+}
+
+// userPurged records a service's confirmation on the user's deletion record
+func (h *PurgeEvents) userPurged(ctx context.Context, event events.Event) error {
+	var payload events.PurgePayload
+	if err := event.Decode(&payload); err != nil {
+		slog.Warn("skipping malformed purge event", "event_id", event.ID, "error", err)
+		return nil
+	}
+
+	return h.deletionRepo.Confirm(ctx, event.UserID, payload.Service, payload.Records)
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"dailytrackr/shared/authz"
+	"dailytrackr/shared/client"
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/utils"
+	"dailytrackr/user-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Administration. Each route requires a permission (see shared/authz), which
+// the caller's token carries from the roles they had when signing in.
+
+// AdminListUsers lists users, newest first, optionally searching username and email with q
+func (h *UserHandlers) AdminListUsers(c *gin.Context) {
+	params, _ := utils.ParsePageParams("", c.Query("page"), c.Query("limit"))
+	ctx := c.Request.Context()
+
+	users, total, err := h.userRepo.Search(ctx, strings.TrimSpace(c.Query("q")), params.Offset(), params.Limit)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to list users", err)
+		return
+	}
+
+	ids := make([]int64, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	roles, err := h.roleRepo.ListByUsers(ctx, ids)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to list roles", err)
+		return
+	}
+
+	responses := make([]dto.AdminUserResponse, len(users))
+	for i := range users {
+		responses[i] = h.adminUserResponse(&users[i], roles[users[i].ID])
+	}
+
+	more := params.Offset()+len(users) < total
+	utils.SendSuccessResponse(c.Writer, constants.MsgUsersRetrieved, dto.AdminUserListResponse{
+		Users:              responses,
+		PaginationResponse: utils.NewPaginationResponse(params, total, more, nil, nil),
+	})
+}
+
+// AdminGetUser returns any user's account with their roles
+func (h *UserHandlers) AdminGetUser(c *gin.Context) {
+	user, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.adminUser(c.Request.Context(), user)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to list roles", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgUserRetrieved, response)
+}
+
+// AdminDisableUser stops a user signing in and signs them out everywhere
+func (h *UserHandlers) AdminDisableUser(c *gin.Context) {
+	user, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+	if user.ID == c.GetInt64("user_id") {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrOwnAccount, nil)
+		return
+	}
+
+	if err := h.userRepo.Disable(c.Request.Context(), user.ID); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to disable account", err)
+		return
+	}
+	h.auditAdmin(c, user.ID, models.AuditAccountDisabled, "")
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgAccountDisabled, nil)
+}
+
+// AdminEnableUser lets a disabled user sign in again
+func (h *UserHandlers) AdminEnableUser(c *gin.Context) {
+	user, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userRepo.Enable(c.Request.Context(), user.ID); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to enable account", err)
+		return
+	}
+	h.auditAdmin(c, user.ID, models.AuditAccountEnabled, "")
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgAccountEnabled, nil)
+}
+
+// AdminUnlockUser lifts a login lockout on behalf of a user who cannot use
+// the emailed link
+func (h *UserHandlers) AdminUnlockUser(c *gin.Context) {
+	user, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.unlockAccount(c.Request.Context(), user.ID, c.GetInt64("user_id"), c.ClientIP()); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to unlock account", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgAccountUnlocked, nil)
+}
+
+// AdminResetTwoFactor turns two-factor authentication off for a user who
+// lost both their authenticator and recovery codes
+func (h *UserHandlers) AdminResetTwoFactor(c *gin.Context) {
+	user, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.mfaRepo.Disable(c.Request.Context(), user.ID); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to reset two-factor authentication", err)
+		return
+	}
+	h.auditAdmin(c, user.ID, models.AuditTwoFactorReset, "")
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgTwoFactorReset, nil)
+}
+
+// AdminSetRoles replaces a user's roles and signs them out, so their next
+// token carries the new permissions
+func (h *UserHandlers) AdminSetRoles(c *gin.Context) {
+	var req dto.UpdateRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Invalid request body", err)
+		return
+	}
+
+	// Everyone has the user role, so it is never stored
+	roles := []string{}
+	for _, role := range req.Roles {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if !authz.ValidRole(role) {
+			utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidRole, nil)
+			return
+		}
+		if role != authz.RoleUser {
+			roles = append(roles, role)
+		}
+	}
+
+	user, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+	if user.ID == c.GetInt64("user_id") {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrOwnAccount, nil)
+		return
+	}
+
+	if err := h.roleRepo.Set(c.Request.Context(), user.ID, roles, c.GetInt64("user_id")); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to update roles", err)
+		return
+	}
+	h.auditAdmin(c, user.ID, models.AuditRolesChanged, strings.Join(roles, ","))
+
+	response, err := h.adminUser(c.Request.Context(), user)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to list roles", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgRolesUpdated, response)
+}
+
+// AdminGetUsage returns a user's account, active sessions and what they have
+// recorded, counted by the stat service
+func (h *UserHandlers) AdminGetUsage(c *gin.Context) {
+	user, ok := h.adminTarget(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+
+	response, err := h.adminUser(ctx, user)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to list roles", err)
+		return
+	}
+
+	sessions, err := h.sessionRepo.ListActive(ctx, user.ID)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to list sessions", err)
+		return
+	}
+
+	// The stat service checks the caller's own usage:read permission
+	usage, err := h.stats.UserUsage(client.Propagate(ctx, c.Request.Header), user.ID)
+	if err != nil {
+		utils.SendError(c.Writer, c.Request, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgUsageRetrieved, dto.AdminUsageResponse{
+		User:     response,
+		Sessions: sessionResponses(sessions, ""),
+		Usage:    usage,
+	})
+}
+
+// adminTarget loads the user named by the :id parameter, answering 400 or
+// 404 and reporting false when there is none
+func (h *UserHandlers) adminTarget(c *gin.Context) (*models.User, bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Invalid user ID", err)
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
+			return nil, false
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return nil, false
+	}
+	return user, true
+}
+
+// adminUser loads a user's roles and converts them to the admin DTO
+func (h *UserHandlers) adminUser(ctx context.Context, user *models.User) (dto.AdminUserResponse, error) {
+	roles, err := h.roleRepo.List(ctx, user.ID)
+	if err != nil {
+		return dto.AdminUserResponse{}, err
+	}
+	return h.adminUserResponse(user, roles), nil
+}
+
+// adminUserResponse converts a user with their stored roles to the admin DTO
+func (h *UserHandlers) adminUserResponse(user *models.User, roles []string) dto.AdminUserResponse {
+	return dto.AdminUserResponse{
+		UserResponse: h.convertToUserResponse(user),
+		Roles:        append([]string{authz.RoleUser}, roles...),
+		Disabled:     user.Disabled(),
+		DisabledAt:   user.DisabledAt,
+	}
+}
+
+// auditAdmin records an administrator's action on userID
+func (h *UserHandlers) auditAdmin(c *gin.Context, userID int64, event, detail string) {
+	h.audit(c.Request.Context(), models.AuditEntry{
+		UserID:    userID,
+		ActorID:   c.GetInt64("user_id"),
+		Event:     event,
+		IPAddress: c.ClientIP(),
+		Detail:    detail,
+	})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/utils"
+	"dailytrackr/user-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAccountDeletion shows a deletion record by the ID returned when the
+// deletion was requested, including which services have confirmed purging
+// the account's data. The unguessable ID is the credential, as the account
+// may no longer exist to sign in with.
+func (h *UserHandlers) GetAccountDeletion(c *gin.Context) {
+	deletion, err := h.deletionRepo.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrDeletionNotFound)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get deletion record", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgDeletionRetrieved, deletionResponse(deletion))
+}
+
+// cancelDeletion cancels the user's scheduled deletion, if any, as they signed in
+func (h *UserHandlers) cancelDeletion(c *gin.Context, userID int64) {
+	cancelled, err := h.deletionRepo.Cancel(c.Request.Context(), userID)
+	if err != nil {
+		slog.Warn("failed to cancel account deletion", "user_id", userID, "error", err)
+		return
+	}
+
+	if cancelled {
+		h.audit(c.Request.Context(), models.AuditEntry{
+			UserID:    userID,
+			Event:     models.AuditDeletionCancelled,
+			IPAddress: c.ClientIP(),
+		})
+	}
+}
+
+// deletionResponse converts a deletion record to its DTO
+func deletionResponse(deletion *models.AccountDeletion) dto.AccountDeletionResponse {
+	confirmations := make([]dto.DeletionConfirmation, 0, len(deletion.Confirmations))
+	for _, confirmation := range deletion.Confirmations {
+		confirmations = append(confirmations, dto.DeletionConfirmation{
+			Service:     confirmation.Service,
+			Records:     confirmation.Records,
+			ConfirmedAt: confirmation.ConfirmedAt,
+		})
+	}
+
+	return dto.AccountDeletionResponse{
+		ID:            deletion.ID,
+		Status:        deletion.Status,
+		EmailHash:     deletion.EmailHash,
+		RequestedAt:   deletion.RequestedAt,
+		ScheduledFor:  deletion.ScheduledFor,
+		CancelledAt:   deletion.CancelledAt,
+		PurgedAt:      deletion.PurgedAt,
+		CompletedAt:   deletion.CompletedAt,
+		Confirmations: confirmations,
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/url"
+	"os"
+	"time"
+
+	"dailytrackr/shared/client"
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/utils"
+	"dailytrackr/user-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// exportLinkPurpose signs data export download links
+	exportLinkPurpose = "data_export"
+	// exportTimeout bounds how long building one export may take
+	exportTimeout = 15 * time.Minute
+)
+
+// ExportData starts an export of everything the services hold about the
+// user, or reports the one already under way. Once ready it comes with a
+// download link valid for EXPORT_LINK_TTL; refresh=true replaces a ready or
+// failed export with a new one.
+func (h *UserHandlers) ExportData(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+	ctx := c.Request.Context()
+
+	latest, err := h.exportRepo.Latest(ctx, userID.(int64))
+	if err != nil && err != sql.ErrNoRows {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get data export", err)
+		return
+	}
+
+	if latest != nil {
+		switch {
+		case latest.Status == models.ExportPending:
+			utils.SendAcceptedResponse(c.Writer, constants.MsgExportPending, h.exportResponse(latest))
+			return
+		case c.Query("refresh") == "true":
+			// Start over below
+		case latest.Status == models.ExportFailed:
+			utils.SendSuccessResponse(c.Writer, constants.MsgExportFailed, h.exportResponse(latest))
+			return
+		case latest.Available(time.Now()):
+			utils.SendSuccessResponse(c.Writer, constants.MsgExportReady, h.exportResponse(latest))
+			return
+		}
+	}
+
+	user, err := h.userRepo.GetByID(ctx, userID.(int64))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
+	}
+
+	sessions, err := h.sessionRepo.ListActive(ctx, user.ID)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to list sessions", err)
+		return
+	}
+
+	settings, err := h.settingsRepo.Get(ctx, user.ID)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get settings", err)
+		return
+	}
+
+	export, err := h.exportRepo.Create(ctx, user.ID)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to start data export", err)
+		return
+	}
+
+	account := map[string]any{
+		"sessions": sessionResponses(sessions, ""),
+		"settings": settingsResponse(settings),
+	}
+
+	// The other services are called as the user, with the request's token
+	jobCtx := client.Propagate(context.WithoutCancel(ctx), c.Request.Header)
+	go h.buildExport(jobCtx, export, h.convertToUserResponse(user), account)
+
+	utils.SendAcceptedResponse(c.Writer, constants.MsgExportPending, h.exportResponse(export))
+}
+
+// DownloadExport serves a ready export archive to whoever holds its link
+func (h *UserHandlers) DownloadExport(c *gin.Context) {
+	id, ok := h.tokens.Unbind(exportLinkPurpose, c.Query("token"))
+	if !ok {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidExportLink, nil)
+		return
+	}
+
+	export, err := h.exportRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidExportLink, nil)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get data export", err)
+		return
+	}
+
+	if export.Status != models.ExportReady || !export.Available(time.Now()) {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidExportLink, nil)
+		return
+	}
+	if _, err := os.Stat(export.FilePath); err != nil {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidExportLink, nil)
+		return
+	}
+
+	c.FileAttachment(export.FilePath, "dailytrackr-export-"+export.CreatedAt.Format(constants.DateFormat)+".zip")
+}
+
+// buildExport builds the archive for export and records the outcome
+func (h *UserHandlers) buildExport(ctx context.Context, export *models.Export, profile dto.UserResponse, account map[string]any) {
+	buildCtx, cancel := context.WithTimeout(ctx, exportTimeout)
+	defer cancel()
+
+	path, err := h.exporter.Build(buildCtx, export.ID, profile, account)
+	if err != nil {
+		slog.Warn("data export failed", "export_id", export.ID, "user_id", export.UserID, "error", err)
+		if err := h.exportRepo.Fail(ctx, export.ID, err.Error()); err != nil {
+			slog.Warn("failed to mark data export failed", "export_id", export.ID, "error", err)
+		}
+		return
+	}
+
+	if err := h.exportRepo.Complete(ctx, export.ID, path, time.Now().Add(h.config.ExportLinkTTL)); err != nil {
+		slog.Warn("failed to mark data export ready", "export_id", export.ID, "error", err)
+		os.Remove(path)
+	}
+}
+
+// exportResponse converts an export to its DTO, with a download link when ready
+func (h *UserHandlers) exportResponse(export *models.Export) dto.DataExportResponse {
+	response := dto.DataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+	if export.Status == models.ExportReady {
+		token := h.tokens.Bind(exportLinkPurpose, export.ID)
+		response.DownloadURL = h.config.PublicURL + "/exports/download?token=" + url.QueryEscape(token)
+	}
+	return response
+}
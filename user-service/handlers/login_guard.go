@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/mail"
+	"dailytrackr/shared/utils"
+	"dailytrackr/user-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Brute-force protection for Login and LoginMFA. Wrong passwords and wrong
+// two-factor codes are counted per account and per IP, and an account's count
+// is only cleared once a sign-in completes: after LOGIN_DELAY_AFTER failures
+// in a row an account has to wait a doubling delay between attempts, after
+// LOGIN_MAX_FAILURES it is locked for LOGIN_LOCKOUT_DURATION and its owner is
+// emailed an unlock link, and an IP with LOGIN_IP_MAX_FAILURES failures within
+// LOGIN_IP_WINDOW is refused.
+
+// ipBlocked answers 429 and reports true when ip has failed too many logins recently
+func (h *UserHandlers) ipBlocked(c *gin.Context, ip string) bool {
+	window := h.config.LoginIPWindow
+	failures, oldest, err := h.loginAttempts.IPFailures(c.Request.Context(), ip, time.Now().Add(-window))
+	if err != nil {
+		// Failing open keeps logins working when the check itself breaks
+		slog.Warn("failed to check login failures for IP", "ip", ip, "error", err)
+		return false
+	}
+
+	if failures < h.config.LoginIPMaxFailures || oldest == nil {
+		return false
+	}
+
+	// The block lifts once the oldest failure leaves the window
+	setRetryAfter(c, time.Until(oldest.Add(window)))
+	utils.SendTooManyRequestsResponse(c.Writer, constants.ErrLoginThrottled)
+	return true
+}
+
+// accountBlocked answers 429 and reports true when the account is locked or
+// has to wait longer after its last failed password
+func (h *UserHandlers) accountBlocked(c *gin.Context, user *models.User) bool {
+	state, err := h.loginAttempts.GetState(c.Request.Context(), user.ID)
+	if err != nil {
+		slog.Warn("failed to check login failures", "user_id", user.ID, "error", err)
+		return false
+	}
+
+	now := time.Now()
+	if state.Locked(now) {
+		setRetryAfter(c, state.LockedUntil.Sub(now))
+		utils.SendTooManyRequestsResponse(c.Writer, constants.ErrAccountLocked)
+		return true
+	}
+
+	if state.LastFailedAt == nil {
+		return false
+	}
+	if wait := h.loginDelay(state.Failures) - now.Sub(*state.LastFailedAt); wait > 0 {
+		setRetryAfter(c, wait)
+		utils.SendTooManyRequestsResponse(c.Writer, constants.ErrLoginThrottled)
+		return true
+	}
+	return false
+}
+
+// loginFailed records a wrong password from ip for email, whose account is
+// user (nil when there is none). When this failure locks the account, or
+// blocks the IP, it is audited, and the owner of a locked account is emailed.
+func (h *UserHandlers) loginFailed(c *gin.Context, ip, email string, user *models.User) {
+	ctx := c.Request.Context()
+
+	if err := h.loginAttempts.RecordIPFailure(ctx, ip, email); err != nil {
+		slog.Warn("failed to record login failure", "ip", ip, "error", err)
+	}
+
+	failures, _, err := h.loginAttempts.IPFailures(ctx, ip, time.Now().Add(-h.config.LoginIPWindow))
+	if err == nil && failures == h.config.LoginIPMaxFailures {
+		h.audit(ctx, models.AuditEntry{
+			Event:     models.AuditLoginIPBlocked,
+			IPAddress: ip,
+			Detail:    fmt.Sprintf("%d failed logins within %s", failures, h.config.LoginIPWindow),
+		})
+	}
+
+	if user == nil {
+		return
+	}
+
+	state, err := h.loginAttempts.RecordFailure(ctx, user.ID, h.config.LoginMaxFailures, h.config.LoginLockoutDuration)
+	if err != nil {
+		slog.Warn("failed to record login failure", "user_id", user.ID, "error", err)
+		return
+	}
+	if !state.Locked(time.Now()) {
+		return
+	}
+
+	h.audit(ctx, models.AuditEntry{
+		UserID:    user.ID,
+		Event:     models.AuditAccountLocked,
+		IPAddress: ip,
+		Detail:    fmt.Sprintf("locked after %d failed logins", state.Failures),
+	})
+
+	// Sent after the response, like password reset emails
+	go h.sendLockoutNotice(context.WithoutCancel(ctx), user, ip, state.Failures)
+}
+
+// loginSucceeded clears the account's failed logins
+func (h *UserHandlers) loginSucceeded(ctx context.Context, userID int64) {
+	if err := h.loginAttempts.Reset(ctx, userID); err != nil {
+		slog.Warn("failed to reset login failures", "user_id", userID, "error", err)
+	}
+}
+
+// unlockAccount lifts a lockout and audits who did it; actorID is zero when
+// the owner used the emailed link
+func (h *UserHandlers) unlockAccount(ctx context.Context, userID, actorID int64, ip string) error {
+	if err := h.loginAttempts.Reset(ctx, userID); err != nil {
+		return err
+	}
+
+	h.audit(ctx, models.AuditEntry{
+		UserID:    userID,
+		ActorID:   actorID,
+		Event:     models.AuditAccountUnlocked,
+		IPAddress: ip,
+	})
+	return nil
+}
+
+// sendLockoutNotice emails the owner of a just-locked account a link to unlock it
+func (h *UserHandlers) sendLockoutNotice(ctx context.Context, user *models.User, ip string, failures int) {
+	lockout := h.config.LoginLockoutDuration
+	link, err := h.issueLink(ctx, user.ID, models.TokenAccountUnlock, "/auth/unlock-account", lockout)
+	if err != nil {
+		slog.Warn("failed to issue unlock token", "user_id", user.ID, "error", err)
+		return
+	}
+
+	if err := h.mailer.Send(ctx, mail.AccountLocked(user.Language, user.Email, user.Username, link, ip, failures, lockout)); err != nil {
+		slog.Warn("failed to send lockout email", "user_id", user.ID, "error", err)
+	}
+}
+
+// loginDelay is how long an account with failures failed passwords in a row
+// has to wait before the next attempt
+func (h *UserHandlers) loginDelay(failures int) time.Duration {
+	extra := failures - h.config.LoginDelayAfter
+	if extra < 0 {
+		return 0
+	}
+	if extra > 30 {
+		return h.config.LoginMaxDelay
+	}
+	return min(h.config.LoginDelayBase<<extra, h.config.LoginMaxDelay)
+}
+
+// audit records entry, logging rather than failing the request when it cannot
+func (h *UserHandlers) audit(ctx context.Context, entry models.AuditEntry) {
+	if err := h.auditRepo.Record(ctx, entry); err != nil {
+		slog.Warn("failed to write audit log", "event", entry.Event, "user_id", entry.UserID, "error", err)
+	}
+}
+
+// setRetryAfter tells the client how many seconds to wait, rounding up
+func setRetryAfter(c *gin.Context, wait time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/utils"
+	"dailytrackr/user-service/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// EnrollTwoFactor starts TOTP enrollment with a new secret, returned as an
+// otpauth URI and a QR code for authenticator apps. Calling it again before
+// confirming replaces the secret.
+func (h *UserHandlers) EnrollTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(int64))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
+	}
+
+	if user.TwoFactorEnabled() {
+		utils.SendConflictResponse(c.Writer, constants.ErrTwoFactorEnabled)
+		return
+	}
+
+	secret, err := h.totp.NewSecret()
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate secret", err)
+		return
+	}
+
+	sealed, err := h.totp.Seal(secret)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to seal secret", err)
+		return
+	}
+
+	if err := h.mfaRepo.SetPendingSecret(c.Request.Context(), user.ID, sealed); err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendConflictResponse(c.Writer, constants.ErrTwoFactorEnabled)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to start enrollment", err)
+		return
+	}
+
+	uri := h.totp.URI(user.Email, secret)
+	png, err := h.totp.QRCode(uri)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to render QR code", err)
+		return
+	}
+
+	response := dto.TwoFactorEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgTwoFactorEnrollment, response)
+}
+
+// ConfirmTwoFactor enables two-factor authentication once a code from the
+// pending secret checks out, and returns the recovery codes; they are only
+// ever shown here
+func (h *UserHandlers) ConfirmTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Invalid request body", err)
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Validation failed", err)
+		return
+	}
+
+	totp, err := h.mfaRepo.GetTOTP(c.Request.Context(), userID.(int64))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
+	}
+
+	if totp.Enabled {
+		utils.SendConflictResponse(c.Writer, constants.ErrTwoFactorEnabled)
+		return
+	}
+	if totp.Secret == "" {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrTwoFactorNotEnrolled, nil)
+		return
+	}
+
+	secret, err := h.totp.Open(totp.Secret)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to open secret", err)
+		return
+	}
+
+	step, ok := h.totp.Validate(secret, req.Code, time.Now())
+	if !ok {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidTwoFactorCode, nil)
+		return
+	}
+
+	codes, hashes, err := h.totp.NewRecoveryCodes()
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate recovery codes", err)
+		return
+	}
+
+	if err := h.mfaRepo.Enable(c.Request.Context(), userID.(int64), step, hashes); err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendConflictResponse(c.Writer, constants.ErrTwoFactorEnabled)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to enable two-factor authentication", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgTwoFactorEnabled, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableTwoFactor turns two-factor authentication off; it needs both the
+// password and a current authenticator or recovery code
+func (h *UserHandlers) DisableTwoFactor(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	var req dto.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Invalid request body", err)
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Validation failed", err)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(int64))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
+	}
+
+	if !user.TwoFactorEnabled() {
+		utils.SendConflictResponse(c.Writer, constants.ErrTwoFactorNotEnabled)
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidCurrentPassword)
+		return
+	}
+
+	ok, err := h.checkSecondFactor(c.Request.Context(), user.ID, req.Code)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to check two-factor code", err)
+		return
+	}
+	if !ok {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidTwoFactorCode)
+		return
+	}
+
+	if err := h.mfaRepo.Disable(c.Request.Context(), user.ID); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to disable two-factor authentication", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgTwoFactorDisabled, nil)
+}
+
+// LoginMFA completes a sign-in started by Login. Each challenge allows a
+// single attempt: after a wrong code the user signs in with their password
+// again. Wrong codes count as failed logins, so guessing them runs into the
+// same lockout as guessing passwords.
+func (h *UserHandlers) LoginMFA(c *gin.Context) {
+	var req dto.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Invalid request body", err)
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Validation failed", err)
+		return
+	}
+
+	// Brute-force protection, see login_guard.go
+	ip := c.ClientIP()
+	if h.ipBlocked(c, ip) {
+		return
+	}
+
+	hash, ok := h.tokens.Verify(models.TokenMFAChallenge, req.ChallengeToken)
+	if !ok {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidMFAChallenge)
+		return
+	}
+
+	userID, err := h.tokenRepo.Consume(c.Request.Context(), models.TokenMFAChallenge, hash, nil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidMFAChallenge)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
+	}
+
+	// The account may have been locked or disabled since the password step
+	if h.accountBlocked(c, user) || h.accountDisabled(c, user) {
+		return
+	}
+
+	ok, err = h.checkSecondFactor(c.Request.Context(), user.ID, req.Code)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to check two-factor code", err)
+		return
+	}
+	if !ok {
+		h.loginFailed(c, ip, user.Email, user)
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidTwoFactorCode)
+		return
+	}
+	h.loginSucceeded(c.Request.Context(), user.ID)
+
+	// Generate JWT token
+	token, err := h.generateToken(c, user)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate token", err)
+		return
+	}
+
+	authResponse := dto.AuthResponse{
+		Token: token,
+		User:  h.convertToUserResponse(user),
+	}
+
+	ginmw.SetLanguagePreference(c, user.Language)
+	utils.SendSuccessResponse(c.Writer, constants.MsgLoginSuccess, authResponse)
+}
+
+// sendMFAChallenge answers a correct password for an account with two-factor
+// authentication with a short-lived challenge token instead of a JWT
+func (h *UserHandlers) sendMFAChallenge(c *gin.Context, user *models.User) {
+	token, hash, err := h.tokens.Issue(models.TokenMFAChallenge)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to issue challenge", err)
+		return
+	}
+
+	ttl := h.config.MFAChallengeTTL
+	if err := h.tokenRepo.Create(c.Request.Context(), user.ID, models.TokenMFAChallenge, hash, time.Now().Add(ttl)); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to issue challenge", err)
+		return
+	}
+
+	response := dto.MFAChallengeResponse{
+		MFARequired:    true,
+		ChallengeToken: token,
+		ExpiresIn:      int(ttl.Seconds()),
+	}
+
+	ginmw.SetLanguagePreference(c, user.Language)
+	utils.SendSuccessResponse(c.Writer, constants.MsgMFARequired, response)
+}
+
+// checkSecondFactor accepts an unused authenticator code for the current time
+// or an unused recovery code, marking it used
+func (h *UserHandlers) checkSecondFactor(ctx context.Context, userID int64, code string) (bool, error) {
+	if !h.totp.IsTOTPCode(code) {
+		return h.mfaRepo.UseRecoveryCode(ctx, userID, h.totp.RecoveryCodeHash(code))
+	}
+
+	totp, err := h.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !totp.Enabled {
+		return false, nil
+	}
+
+	secret, err := h.totp.Open(totp.Secret)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := h.totp.Validate(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return h.mfaRepo.UseStep(ctx, userID, step)
+}
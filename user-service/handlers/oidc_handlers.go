@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/utils"
+	"dailytrackr/user-service/models"
+	"dailytrackr/user-service/services"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// oidcCookie keeps the state of a sign-in between the redirect and the callback
+	oidcCookie     = "dailytrackr_oidc"
+	oidcCookiePath = "/auth/oidc"
+
+	// maxUsernameBase leaves room for a numeric suffix within the 50 character limit
+	maxUsernameBase = 40
+)
+
+// OIDCLogin sends the user to an OpenID Connect provider to sign in
+func (h *UserHandlers) OIDCLogin(c *gin.Context) {
+	provider := c.Param("provider")
+	if !h.oidc.Has(provider) {
+		utils.SendNotFoundResponse(c.Writer, constants.ErrOIDCProviderNotFound)
+		return
+	}
+
+	authURL, login, err := h.oidc.Start(c.Request.Context(), provider)
+	if err != nil {
+		slog.Warn("failed to start OIDC login", "provider", provider, "error", err)
+		utils.SendErrorResponse(c.Writer, http.StatusBadGateway, constants.ErrOIDCProviderUnavailable, err)
+		return
+	}
+
+	cookie, err := h.oidc.EncodeLogin(login)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to start sign-in", err)
+		return
+	}
+
+	h.setOIDCCookie(c, cookie, int(time.Until(time.Unix(login.ExpiresAt, 0)).Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OIDCCallback finishes a sign-in at an OpenID Connect provider. The identity
+// signs in the user it is linked to; otherwise it is linked to the account
+// with the same email, if the provider verified that email, or a new account
+// is created for it.
+func (h *UserHandlers) OIDCCallback(c *gin.Context) {
+	provider := c.Param("provider")
+	if !h.oidc.Has(provider) {
+		utils.SendNotFoundResponse(c.Writer, constants.ErrOIDCProviderNotFound)
+		return
+	}
+
+	cookie, err := c.Cookie(oidcCookie)
+	h.setOIDCCookie(c, "", -1) // each login state is good for one callback
+	if err != nil || c.Query("error") != "" {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrOIDCLoginFailed)
+		return
+	}
+
+	login, err := h.oidc.DecodeLogin(cookie)
+	if err != nil || login.Provider != provider {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrOIDCLoginFailed)
+		return
+	}
+
+	identity, err := h.oidc.Finish(c.Request.Context(), login, c.Query("state"), c.Query("code"))
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCLogin) {
+			slog.Info("OIDC login rejected", "provider", provider, "error", err)
+			utils.SendUnauthorizedResponse(c.Writer, constants.ErrOIDCLoginFailed)
+			return
+		}
+		utils.SendErrorResponse(c.Writer, http.StatusBadGateway, constants.ErrOIDCProviderUnavailable, err)
+		return
+	}
+
+	user, created, ok := h.oidcUser(c, identity)
+	if !ok {
+		return
+	}
+	if h.accountDisabled(c, user) {
+		return
+	}
+
+	if user.TwoFactorEnabled() {
+		h.sendMFAChallenge(c, user)
+		return
+	}
+
+	token, err := h.generateToken(c, user)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate token", err)
+		return
+	}
+
+	authResponse := dto.AuthResponse{
+		Token: token,
+		User:  h.convertToUserResponse(user),
+	}
+
+	ginmw.SetLanguagePreference(c, user.Language)
+	if created {
+		utils.SendCreatedResponse(c.Writer, constants.MsgUserCreated, authResponse)
+		return
+	}
+	utils.SendSuccessResponse(c.Writer, constants.MsgLoginSuccess, authResponse)
+}
+
+// oidcUser finds or creates the user for identity. It writes the error
+// response itself and reports ok=false when there is none.
+func (h *UserHandlers) oidcUser(c *gin.Context, identity *services.OIDCIdentity) (user *models.User, created, ok bool) {
+	ctx := c.Request.Context()
+
+	userID, err := h.identityRepo.FindUserID(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		user, err = h.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+			return nil, false, false
+		}
+		return user, false, true
+	}
+	if err != sql.ErrNoRows {
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return nil, false, false
+	}
+
+	if identity.Email == "" {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrOIDCEmailRequired, nil)
+		return nil, false, false
+	}
+
+	user, err = h.userRepo.GetByEmail(ctx, identity.Email)
+	switch {
+	case err == nil:
+		// Without the provider's word for the email, anyone could claim the account
+		if !identity.EmailVerified {
+			utils.SendConflictResponse(c.Writer, constants.ErrOIDCEmailUnverified)
+			return nil, false, false
+		}
+		// Nor without the account's own: whoever registered it unverified may
+		// not own the address, and would keep their password after the link
+		if !user.EmailVerified() {
+			utils.SendConflictResponse(c.Writer, constants.ErrOIDCAccountUnverified)
+			return nil, false, false
+		}
+	case err == sql.ErrNoRows:
+		user, err = h.createOIDCUser(c, identity)
+		if err != nil {
+			utils.SendInternalServerErrorResponse(c.Writer, "Failed to create user", err)
+			return nil, false, false
+		}
+		created = true
+	default:
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return nil, false, false
+	}
+
+	if err := h.identityRepo.Link(ctx, user.ID, identity.Provider, identity.Subject, identity.Email, identity.EmailVerified); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to link account", err)
+		return nil, false, false
+	}
+
+	// Pick up the verification the link may have recorded
+	user, err = h.userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return nil, false, false
+	}
+
+	if created && !user.EmailVerified() {
+		if err := h.sendVerificationEmail(ctx, user); err != nil {
+			slog.Warn("failed to send verification email", "user_id", user.ID, "error", err)
+		}
+	}
+
+	return user, created, true
+}
+
+// createOIDCUser creates an account for a new identity. It gets a random
+// password nobody knows; the user can set one through a password reset.
+func (h *UserHandlers) createOIDCUser(c *gin.Context, identity *services.OIDCIdentity) (*models.User, error) {
+	ctx := c.Request.Context()
+
+	username, err := h.generateUsername(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword(password, bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username:     username,
+		Email:        identity.Email,
+		PasswordHash: string(hashedPassword),
+		Language:     ginmw.Lang(c),
+		Timezone:     constants.DefaultTimezone,
+		Currency:     constants.DefaultCurrency,
+	}
+	if err := h.userRepo.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// generateUsername derives a free username that passes validation from the
+// identity's preferred username, email or name, adding a number if needed
+func (h *UserHandlers) generateUsername(ctx context.Context, identity *services.OIDCIdentity) (string, error) {
+	base := "member"
+	localPart, _, _ := strings.Cut(identity.Email, "@")
+	for _, candidate := range []string{identity.PreferredUsername, localPart, identity.Name} {
+		if candidate = usernameBase(candidate); len(candidate) >= 3 {
+			base = candidate
+			break
+		}
+	}
+
+	username := base
+	for attempt := 0; attempt < 10; attempt++ {
+		if h.validator.ValidateUsername(username) == nil {
+			exists, err := h.userRepo.UsernameExists(ctx, username)
+			if err != nil {
+				return "", err
+			}
+			if !exists {
+				return username, nil
+			}
+		}
+
+		n, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", err
+		}
+		username = fmt.Sprintf("%s_%04d", base, n)
+	}
+
+	return "", errors.New("could not find a free username")
+}
+
+// usernameBase reduces s to the characters usernames allow: letters and
+// digits, with single underscores between words
+func usernameBase(s string) string {
+	var b strings.Builder
+	separator := false
+	for _, r := range s {
+		switch {
+		case r < 128 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'):
+			if separator && b.Len() > 0 {
+				b.WriteByte('_')
+			}
+			separator = false
+			b.WriteRune(r)
+		default:
+			separator = true
+		}
+	}
+
+	base := b.String()
+	if len(base) > maxUsernameBase {
+		base = strings.TrimRight(base[:maxUsernameBase], "_")
+	}
+	return base
+}
+
+// setOIDCCookie stores (or with maxAge < 0, clears) the sign-in state cookie.
+// It must survive the top-level redirect back from the provider, hence Lax.
+func (h *UserHandlers) setOIDCCookie(c *gin.Context, value string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcCookie, value, maxAge, oidcCookiePath, "", strings.HasPrefix(h.config.PublicURL, "https://"), true)
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/utils"
+	"dailytrackr/user-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxUserAgent matches user_sessions.user_agent
+const maxUserAgent = 512
+
+// ListSessions lists the devices the user is signed in on
+func (h *UserHandlers) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	sessions, err := h.sessionRepo.ListActive(c.Request.Context(), userID.(int64))
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to list sessions", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgSessionsRetrieved, sessionResponses(sessions, c.GetString("session_id")))
+}
+
+// RevokeSession signs out one session, such as a lost phone. Revoking the
+// current session signs the caller out.
+func (h *UserHandlers) RevokeSession(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	if err := h.sessionRepo.Revoke(c.Request.Context(), userID.(int64), c.Param("sessionId")); err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrSessionNotFound)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to revoke session", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgSessionRevoked, nil)
+}
+
+// RevokeOtherSessions signs out every session except the current one
+func (h *UserHandlers) RevokeOtherSessions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	revoked, err := h.sessionRepo.RevokeOthers(c.Request.Context(), userID.(int64), c.GetString("session_id"))
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to revoke sessions", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgOtherSessionsRevoked, dto.RevokeSessionsResponse{Revoked: revoked})
+}
+
+// sessionResponses converts sessions to DTOs, marking the one with ID current
+func sessionResponses(sessions []models.Session, current string) []dto.SessionResponse {
+	response := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, dto.SessionResponse{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			Current:    session.ID == current,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+		})
+	}
+	return response
+}
+
+// truncate cuts s to at most max bytes
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"database/sql"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/utils"
+	"dailytrackr/user-service/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSettings returns the user's settings, with defaults for those never changed
+func (h *UserHandlers) GetSettings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	settings, err := h.settingsRepo.Get(c.Request.Context(), userID.(int64))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get settings", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgSettingsRetrieved, settingsResponse(settings))
+}
+
+// UpdateSettings changes the settings included in the request. Tokens carry
+// the language, time zone and week start, so the other services pick changes
+// to those up on the next sign-in.
+func (h *UserHandlers) UpdateSettings(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	var req dto.UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Invalid request body", err)
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Validation failed", err)
+		return
+	}
+
+	settings, err := h.settingsRepo.Get(c.Request.Context(), userID.(int64))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get settings", err)
+		return
+	}
+
+	if req.Timezone != nil {
+		settings.Timezone = *req.Timezone
+	}
+	if req.WeekStart != nil {
+		settings.WeekStart = *req.WeekStart
+	}
+	if req.Currency != nil {
+		settings.Currency = *req.Currency
+	}
+	if req.Language != nil {
+		settings.Language = *req.Language
+	}
+	if req.Theme != nil {
+		settings.Theme = *req.Theme
+	}
+	if n := req.Notifications; n != nil {
+		if n.HabitReminders != nil {
+			settings.HabitReminders = *n.HabitReminders
+		}
+		if n.WeeklySummary != nil {
+			settings.WeeklySummary = *n.WeeklySummary
+		}
+		if n.ProductUpdates != nil {
+			settings.ProductUpdates = *n.ProductUpdates
+		}
+	}
+
+	if err := h.settingsRepo.Save(c.Request.Context(), settings); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to update settings", err)
+		return
+	}
+
+	// Answer in the newly saved language
+	ginmw.SetLanguagePreference(c, settings.Language)
+	utils.SendSuccessResponse(c.Writer, constants.MsgSettingsUpdated, settingsResponse(settings))
+}
+
+// settingsResponse converts settings to their DTO
+func settingsResponse(settings *models.Settings) dto.UserSettings {
+	return dto.UserSettings{
+		Timezone:  settings.Timezone,
+		WeekStart: settings.WeekStart,
+		Currency:  settings.Currency,
+		Language:  settings.Language,
+		Theme:     settings.Theme,
+		Notifications: dto.NotificationSettings{
+			HabitReminders: settings.HabitReminders,
+			WeeklySummary:  settings.WeeklySummary,
+			ProductUpdates: settings.ProductUpdates,
+		},
+	}
+}
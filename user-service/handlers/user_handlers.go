@@ -1,12 +1,24 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"dailytrackr/shared/authz"
+	"dailytrackr/shared/client"
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
 	"dailytrackr/shared/dto"
+	"dailytrackr/shared/mail"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/sessions"
 	"dailytrackr/shared/utils"
 	"dailytrackr/user-service/models"
 	"dailytrackr/user-service/services"
@@ -18,18 +30,53 @@ import (
 
 type UserHandlers struct {
 	userRepo     *models.UserRepository
-	photoService *services.PhotoService
-	validator    *validators.UserValidator
-	config       *config.Config
+	tokenRepo    *models.TokenRepository
+	mfaRepo      *models.MFARepository
+	identityRepo *models.IdentityRepository
+	sessionRepo  *models.SessionRepository
+	auditRepo    *models.AuditRepository
+	deletionRepo *models.AccountDeletionRepository
+	exportRepo   *models.ExportRepository
+	roleRepo     *models.RoleRepository
+	settingsRepo *models.SettingsRepository
+	// loginAttempts tracks failed logins for brute-force protection
+	loginAttempts *models.LoginAttemptRepository
+	photoService  *services.PhotoService
+	tokens        *services.TokenService
+	totp          *services.TOTPService
+	oidc          *services.OIDCService
+	exporter      *services.ExportService
+	stats         client.StatClient
+	checker       *sessions.Checker
+	mailer        mail.Sender
+	validator     *validators.UserValidator
+	config        *config.Config
 }
 
 // NewUserHandlers creates a new user handlers instance
 func NewUserHandlers(db *sql.DB, cfg *config.Config) *UserHandlers {
 	return &UserHandlers{
-		userRepo:     models.NewUserRepository(db),
-		photoService: services.NewPhotoService(cfg),
-		validator:    validators.NewUserValidator(),
-		config:       cfg,
+		userRepo:      models.NewUserRepository(db),
+		tokenRepo:     models.NewTokenRepository(db),
+		mfaRepo:       models.NewMFARepository(db),
+		identityRepo:  models.NewIdentityRepository(db),
+		sessionRepo:   models.NewSessionRepository(db),
+		auditRepo:     models.NewAuditRepository(db),
+		deletionRepo:  models.NewAccountDeletionRepository(db),
+		exportRepo:    models.NewExportRepository(db),
+		roleRepo:      models.NewRoleRepository(db),
+		settingsRepo:  models.NewSettingsRepository(db),
+		loginAttempts: models.NewLoginAttemptRepository(db),
+		photoService:  services.NewPhotoService(cfg),
+		tokens:        services.NewTokenService(cfg.JWTSecret),
+		totp:          services.NewTOTPService(cfg),
+		oidc:          services.NewOIDCService(cfg),
+		exporter:      services.NewExportService(cfg),
+		stats:         client.New(cfg).Stat,
+		checker:       sessions.NewChecker(db),
+		mailer:        mail.NewSMTPSender(cfg),
+		validator:     validators.NewUserValidator(),
+		config:        cfg,
 	}
 }
 
@@ -49,24 +96,24 @@ func (h *UserHandlers) Register(c *gin.Context) {
 	}
 
 	// Check if email already exists
-	emailExists, err := h.userRepo.EmailExists(req.Email)
+	emailExists, err := h.userRepo.EmailExists(c.Request.Context(), req.Email)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
 		return
 	}
 	if emailExists {
-		utils.SendBadRequestResponse(c.Writer, constants.ErrEmailAlreadyExists, nil)
+		utils.SendConflictResponse(c.Writer, constants.ErrEmailAlreadyExists)
 		return
 	}
 
 	// Check if username already exists
-	usernameExists, err := h.userRepo.UsernameExists(req.Username)
+	usernameExists, err := h.userRepo.UsernameExists(c.Request.Context(), req.Username)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
 		return
 	}
 	if usernameExists {
-		utils.SendBadRequestResponse(c.Writer, constants.ErrUsernameExists, nil)
+		utils.SendConflictResponse(c.Writer, constants.ErrUsernameExists)
 		return
 	}
 
@@ -82,21 +129,29 @@ func (h *UserHandlers) Register(c *gin.Context) {
 		Username:     h.validator.SanitizeInput(req.Username),
 		Email:        h.validator.SanitizeInput(req.Email),
 		PasswordHash: string(hashedPassword),
+		Language:     ginmw.Lang(c),
+		Timezone:     constants.DefaultTimezone,
+		Currency:     constants.DefaultCurrency,
+	}
+	if req.Timezone != "" {
+		user.Timezone = req.Timezone
+	}
+	if req.Currency != "" {
+		user.Currency = req.Currency
 	}
 
-	if err := h.userRepo.Create(user); err != nil {
+	if err := h.userRepo.Create(c.Request.Context(), user); err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to create user", err)
 		return
 	}
 
+	// The account works without it; the user can ask for another link
+	if err := h.sendVerificationEmail(c.Request.Context(), user); err != nil {
+		slog.Warn("failed to send verification email", "user_id", user.ID, "error", err)
+	}
+
 	// Generate JWT token
-	token, err := utils.GenerateJWT(
-		user.ID,
-		user.Username,
-		user.Email,
-		h.config.JWTSecret,
-		h.config.JWTExpireHours,
-	)
+	token, err := h.generateToken(c, user)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate token", err)
 		return
@@ -130,10 +185,17 @@ func (h *UserHandlers) Login(c *gin.Context) {
 	// Sanitize input
 	email := h.validator.SanitizeInput(req.Email)
 
+	// Brute-force protection, see login_guard.go
+	ip := c.ClientIP()
+	if h.ipBlocked(c, ip) {
+		return
+	}
+
 	// Get user by email
-	user, err := h.userRepo.GetByEmail(email)
+	user, err := h.userRepo.GetByEmail(c.Request.Context(), email)
 	if err != nil {
 		if err == sql.ErrNoRows {
+			h.loginFailed(c, ip, email, nil)
 			utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidCredentials)
 			return
 		}
@@ -141,20 +203,31 @@ func (h *UserHandlers) Login(c *gin.Context) {
 		return
 	}
 
+	if h.accountBlocked(c, user) {
+		return
+	}
+
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		h.loginFailed(c, ip, email, user)
 		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidCredentials)
 		return
 	}
 
+	if h.accountDisabled(c, user) {
+		return
+	}
+
+	// With two-factor authentication on, the JWT is only issued by LoginMFA,
+	// and the failure count is only cleared once the code is right too
+	if user.TwoFactorEnabled() {
+		h.sendMFAChallenge(c, user)
+		return
+	}
+	h.loginSucceeded(c.Request.Context(), user.ID)
+
 	// Generate JWT token
-	token, err := utils.GenerateJWT(
-		user.ID,
-		user.Username,
-		user.Email,
-		h.config.JWTSecret,
-		h.config.JWTExpireHours,
-	)
+	token, err := h.generateToken(c, user)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate token", err)
 		return
@@ -167,9 +240,240 @@ func (h *UserHandlers) Login(c *gin.Context) {
 		User:  userResponse,
 	}
 
+	ginmw.SetLanguagePreference(c, user.Language)
 	utils.SendSuccessResponse(c.Writer, constants.MsgLoginSuccess, authResponse)
 }
 
+// VerifyEmail confirms the address a verification link was sent to. The token
+// comes from the link's query string (GET) or a JSON body (POST).
+func (h *UserHandlers) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if c.Request.Method == http.MethodPost {
+		var req dto.VerifyEmailRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendBadRequestResponse(c.Writer, "Invalid request body", err)
+			return
+		}
+		token = req.Token
+	}
+
+	hash, ok := h.tokens.Verify(models.TokenEmailVerification, token)
+	if !ok {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidVerifyToken, nil)
+		return
+	}
+
+	userID, err := h.tokenRepo.Consume(c.Request.Context(), models.TokenEmailVerification, hash, func(tx *sql.Tx, userID int64) error {
+		return h.userRepo.MarkEmailVerified(c.Request.Context(), tx, userID)
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidVerifyToken, nil)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to verify email", err)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
+	}
+
+	ginmw.SetLanguagePreference(c, user.Language)
+
+	// A token issued before verification still carries the unverified flag, so
+	// a signed-in caller gets a fresh one in place of the session it came with
+	claims := h.signedIn(c)
+	if claims == nil || claims.UserID != user.ID {
+		utils.SendSuccessResponse(c.Writer, constants.MsgEmailVerifiedSignIn, h.convertToUserResponse(user))
+		return
+	}
+
+	newToken, err := h.generateToken(c, user)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to generate token", err)
+		return
+	}
+	if claims.SessionID != "" {
+		if err := h.sessionRepo.Revoke(c.Request.Context(), user.ID, claims.SessionID); err != nil && err != sql.ErrNoRows {
+			utils.SendInternalServerErrorResponse(c.Writer, "Failed to revoke session", err)
+			return
+		}
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgEmailVerified, dto.AuthResponse{
+		Token: newToken,
+		User:  h.convertToUserResponse(user),
+	})
+}
+
+// signedIn returns the claims of the request's bearer token on routes that
+// do not require one, or nil when it has none that is valid and signed in
+func (h *UserHandlers) signedIn(c *gin.Context) *utils.Claims {
+	token, ok := strings.CutPrefix(c.GetHeader(constants.AuthorizationHeader), constants.BearerPrefix)
+	if !ok || token == "" {
+		return nil
+	}
+
+	claims, err := utils.ValidateJWT(token, h.config.JWTSecret)
+	if err != nil {
+		return nil
+	}
+
+	active, err := h.checker.Active(c.Request.Context(), claims)
+	if err != nil || !active {
+		return nil
+	}
+	return claims
+}
+
+// UnlockAccount lifts a login lockout with the link emailed when it started.
+// The token comes from the link's query string (GET) or a JSON body (POST).
+func (h *UserHandlers) UnlockAccount(c *gin.Context) {
+	token := c.Query("token")
+	if c.Request.Method == http.MethodPost {
+		var req dto.UnlockAccountRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.SendBadRequestResponse(c.Writer, "Invalid request body", err)
+			return
+		}
+		token = req.Token
+	}
+
+	hash, ok := h.tokens.Verify(models.TokenAccountUnlock, token)
+	if !ok {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidUnlockToken, nil)
+		return
+	}
+
+	userID, err := h.tokenRepo.Consume(c.Request.Context(), models.TokenAccountUnlock, hash, nil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidUnlockToken, nil)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to unlock account", err)
+		return
+	}
+
+	if err := h.unlockAccount(c.Request.Context(), userID, 0, c.ClientIP()); err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to unlock account", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgAccountUnlocked, nil)
+}
+
+// ResendVerification emails the signed-in user a new verification link,
+// at most once per VERIFICATION_RESEND_PERIOD
+func (h *UserHandlers) ResendVerification(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(int64))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
+	}
+
+	if user.EmailVerified() {
+		utils.SendConflictResponse(c.Writer, constants.ErrEmailAlreadyVerified)
+		return
+	}
+
+	lastSent, sent, err := h.tokenRepo.LastIssued(c.Request.Context(), user.ID, models.TokenEmailVerification)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
+	}
+	if wait := h.config.VerificationResendPeriod - time.Since(lastSent); sent && wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Round(time.Second).Seconds())))
+		utils.SendTooManyRequestsResponse(c.Writer, constants.ErrVerificationThrottled)
+		return
+	}
+
+	if err := h.sendVerificationEmail(c.Request.Context(), user); err != nil {
+		utils.SendErrorResponse(c.Writer, http.StatusBadGateway, constants.ErrEmailDeliveryFailed, err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgVerificationSent, nil)
+}
+
+// ForgotPassword starts a password reset. The answer is the same whether or
+// not the email belongs to an account, and the email is sent in the
+// background so the response time does not tell either.
+func (h *UserHandlers) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Invalid request body", err)
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Validation failed", err)
+		return
+	}
+
+	go h.sendPasswordReset(context.WithoutCancel(c.Request.Context()), h.validator.SanitizeInput(req.Email))
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgPasswordResetSent, nil)
+}
+
+// ResetPassword sets a new password using the token from a reset email and
+// signs the user out everywhere
+func (h *UserHandlers) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Invalid request body", err)
+		return
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Validation failed", err)
+		return
+	}
+	if err := h.validator.ValidatePassword(req.NewPassword); err != nil {
+		utils.SendBadRequestResponse(c.Writer, "Validation failed", err)
+		return
+	}
+
+	hash, ok := h.tokens.Verify(models.TokenPasswordReset, req.Token)
+	if !ok {
+		utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidResetToken, nil)
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to hash password", err)
+		return
+	}
+
+	_, err = h.tokenRepo.Consume(c.Request.Context(), models.TokenPasswordReset, hash, func(tx *sql.Tx, userID int64) error {
+		return h.userRepo.ResetPassword(c.Request.Context(), tx, userID, string(hashedPassword))
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.SendBadRequestResponse(c.Writer, constants.ErrInvalidResetToken, nil)
+			return
+		}
+		utils.SendInternalServerErrorResponse(c.Writer, "Failed to reset password", err)
+		return
+	}
+
+	utils.SendSuccessResponse(c.Writer, constants.MsgPasswordReset, nil)
+}
+
 // GetProfile handles getting user profile
 func (h *UserHandlers) GetProfile(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -179,7 +483,7 @@ func (h *UserHandlers) GetProfile(c *gin.Context) {
 	}
 
 	// Get user from database
-	user, err := h.userRepo.GetByID(userID.(int64))
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(int64))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
@@ -215,7 +519,7 @@ func (h *UserHandlers) UpdateProfile(c *gin.Context) {
 	}
 
 	// Get existing user
-	user, err := h.userRepo.GetByID(userID.(int64))
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(int64))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
@@ -227,30 +531,33 @@ func (h *UserHandlers) UpdateProfile(c *gin.Context) {
 
 	// Check if new username already exists (if changed)
 	if req.Username != "" && req.Username != user.Username {
-		usernameExists, err := h.userRepo.UsernameExists(req.Username)
+		usernameExists, err := h.userRepo.UsernameExists(c.Request.Context(), req.Username)
 		if err != nil {
 			utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
 			return
 		}
 		if usernameExists {
-			utils.SendBadRequestResponse(c.Writer, constants.ErrUsernameExists, nil)
+			utils.SendConflictResponse(c.Writer, constants.ErrUsernameExists)
 			return
 		}
 		user.Username = h.validator.SanitizeInput(req.Username)
 	}
 
-	// Check if new email already exists (if changed)
+	// Check if new email already exists (if changed); a new address must be verified again
+	emailChanged := false
 	if req.Email != "" && req.Email != user.Email {
-		emailExists, err := h.userRepo.EmailExists(req.Email)
+		emailExists, err := h.userRepo.EmailExists(c.Request.Context(), req.Email)
 		if err != nil {
 			utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
 			return
 		}
 		if emailExists {
-			utils.SendBadRequestResponse(c.Writer, constants.ErrEmailAlreadyExists, nil)
+			utils.SendConflictResponse(c.Writer, constants.ErrEmailAlreadyExists)
 			return
 		}
 		user.Email = h.validator.SanitizeInput(req.Email)
+		user.EmailVerifiedAt = nil
+		emailChanged = true
 	}
 
 	// Update bio if provided
@@ -258,19 +565,43 @@ func (h *UserHandlers) UpdateProfile(c *gin.Context) {
 		user.Bio = h.validator.SanitizeInput(*req.Bio)
 	}
 
+	// Update language preference if provided
+	if req.Language != nil {
+		user.Language = *req.Language
+	}
+
+	// Update time zone if provided; existing tokens pick it up on next login
+	if req.Timezone != nil {
+		user.Timezone = *req.Timezone
+	}
+
+	// Update default currency if provided; it applies to costs added from now on
+	if req.Currency != nil {
+		user.Currency = *req.Currency
+	}
+
 	// Update user in database
-	if err := h.userRepo.Update(user); err != nil {
+	if err := h.userRepo.Update(c.Request.Context(), user); err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to update profile", err)
 		return
 	}
 
 	// Get updated user
-	updatedUser, err := h.userRepo.GetByID(userID.(int64))
+	updatedUser, err := h.userRepo.GetByID(c.Request.Context(), userID.(int64))
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get updated profile", err)
 		return
 	}
 
+	if emailChanged {
+		if err := h.sendVerificationEmail(c.Request.Context(), updatedUser); err != nil {
+			slog.Warn("failed to send verification email", "user_id", updatedUser.ID, "error", err)
+		}
+	}
+
+	// Answer in the newly saved language; existing tokens pick it up on next login
+	ginmw.SetLanguagePreference(c, updatedUser.Language)
+
 	userResponse := h.convertToUserResponse(updatedUser)
 	utils.SendSuccessResponse(c.Writer, constants.MsgProfileUpdated, userResponse)
 }
@@ -296,7 +627,7 @@ func (h *UserHandlers) ChangePassword(c *gin.Context) {
 	}
 
 	// Get user from database
-	user, err := h.userRepo.GetByID(userID.(int64))
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(int64))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
@@ -320,7 +651,7 @@ func (h *UserHandlers) ChangePassword(c *gin.Context) {
 	}
 
 	// Update password
-	if err := h.userRepo.UpdatePassword(user.ID, string(hashedPassword)); err != nil {
+	if err := h.userRepo.UpdatePassword(c.Request.Context(), user.ID, string(hashedPassword)); err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to update password", err)
 		return
 	}
@@ -350,23 +681,23 @@ func (h *UserHandlers) UploadProfilePhoto(c *gin.Context) {
 	}
 
 	// Get current user to check if there's an existing photo
-	user, err := h.userRepo.GetByID(userID.(int64))
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(int64))
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to get user info", err)
 		return
 	}
 
 	// Upload new photo
-	photoURL, err := h.photoService.UploadPhoto(file)
+	photoURL, err := h.photoService.UploadPhoto(c.Request.Context(), file)
 	if err != nil {
 		utils.SendInternalServerErrorResponse(c.Writer, "Failed to upload photo", err)
 		return
 	}
 
 	// Update user profile photo in database
-	if err := h.userRepo.UpdateProfilePhoto(userID.(int64), photoURL); err != nil {
+	if err := h.userRepo.UpdateProfilePhoto(c.Request.Context(), userID.(int64), photoURL); err != nil {
 		// If database update fails, try to delete the uploaded photo
-		if deleteErr := h.photoService.DeletePhoto(photoURL); deleteErr != nil {
+		if deleteErr := h.photoService.DeletePhoto(c.Request.Context(), photoURL); deleteErr != nil {
 			// Log the error but don't fail the request
 			utils.SendInternalServerErrorResponse(c.Writer, "Failed to update profile photo", err)
 			return
@@ -377,9 +708,9 @@ func (h *UserHandlers) UploadProfilePhoto(c *gin.Context) {
 
 	// Optionally delete old photo if it exists
 	if user.ProfilePhoto != "" && user.ProfilePhoto != photoURL {
-		if deleteErr := h.photoService.DeletePhoto(user.ProfilePhoto); deleteErr != nil {
+		if deleteErr := h.photoService.DeletePhoto(c.Request.Context(), user.ProfilePhoto); deleteErr != nil {
 			// Log but don't fail - old photo cleanup is not critical
-			// log.Printf("Warning: Failed to delete old profile photo: %v", deleteErr)
+			slog.Warn("failed to delete old profile photo", "user_id", userID, "error", deleteErr)
 		}
 	}
 
@@ -391,7 +722,8 @@ func (h *UserHandlers) UploadProfilePhoto(c *gin.Context) {
 	utils.SendSuccessResponse(c.Writer, constants.MsgProfilePhotoUploaded, response)
 }
 
-// DeleteAccount handles account deletion
+// DeleteAccount schedules the account for deletion after the grace period;
+// signing in before then cancels it
 func (h *UserHandlers) DeleteAccount(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -406,7 +738,7 @@ func (h *UserHandlers) DeleteAccount(c *gin.Context) {
 	}
 
 	// Get user from database
-	user, err := h.userRepo.GetByID(userID.(int64))
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID.(int64))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
@@ -422,24 +754,35 @@ func (h *UserHandlers) DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	// Delete profile photo if exists
-	if user.ProfilePhoto != "" {
-		if deleteErr := h.photoService.DeletePhoto(user.ProfilePhoto); deleteErr != nil {
-			// Log but don't fail - photo cleanup is not critical for account deletion
-			// log.Printf("Warning: Failed to delete profile photo during account deletion: %v", deleteErr)
-		}
+	// Asking again while one is scheduled returns it unchanged
+	deletion, err := h.deletionRepo.Scheduled(c.Request.Context(), user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		utils.SendInternalServerErrorResponse(c.Writer, "Database error", err)
+		return
 	}
 
-	// Delete user account
-	if err := h.userRepo.Delete(userID.(int64)); err != nil {
-		utils.SendInternalServerErrorResponse(c.Writer, "Failed to delete account", err)
-		return
+	if deletion == nil {
+		// The account and, through the purge events, everything the other
+		// services hold about it go once the grace period ends
+		deletion, err = h.deletionRepo.Schedule(c.Request.Context(), user, time.Now().Add(h.config.AccountDeletionGracePeriod))
+		if err != nil {
+			utils.SendInternalServerErrorResponse(c.Writer, "Failed to schedule account deletion", err)
+			return
+		}
+
+		h.audit(c.Request.Context(), models.AuditEntry{
+			UserID:    user.ID,
+			Event:     models.AuditDeletionScheduled,
+			IPAddress: c.ClientIP(),
+			Detail:    "deletion " + deletion.ID,
+		})
 	}
 
-	utils.SendSuccessResponse(c.Writer, constants.MsgAccountDeleted, nil)
+	utils.SendAcceptedResponse(c.Writer, constants.MsgDeletionScheduled, deletionResponse(deletion))
 }
 
-// GetUserByID handles getting user by ID (for other services)
+// GetUserByID handles getting user by ID (for other services). Users may only
+// get their own account unless they have the users:read permission.
 func (h *UserHandlers) GetUserByID(c *gin.Context) {
 	idParam := c.Param("id")
 	userID, err := strconv.ParseInt(idParam, 10, 64)
@@ -448,7 +791,12 @@ func (h *UserHandlers) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userRepo.GetByID(userID)
+	if !ginmw.CanAccessUser(c, userID, authz.PermUsersRead) {
+		utils.SendForbiddenResponse(c.Writer, constants.ErrPermissionDenied)
+		return
+	}
+
+	user, err := h.userRepo.GetByID(c.Request.Context(), userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			utils.SendNotFoundResponse(c.Writer, constants.ErrUserNotFound)
@@ -459,18 +807,155 @@ func (h *UserHandlers) GetUserByID(c *gin.Context) {
 	}
 
 	userResponse := h.convertToUserResponse(user)
-	utils.SendSuccessResponse(c.Writer, "User retrieved successfully", userResponse)
+	utils.SendSuccessResponse(c.Writer, constants.MsgUserRetrieved, userResponse)
 }
 
 // convertToUserResponse converts User model to UserResponse DTO
 func (h *UserHandlers) convertToUserResponse(user *models.User) dto.UserResponse {
 	return dto.UserResponse{
-		ID:           user.ID,
-		Username:     user.Username,
-		Email:        user.Email,
-		Bio:          user.Bio,
-		ProfilePhoto: user.ProfilePhoto,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
+		ID:               user.ID,
+		Username:         user.Username,
+		Email:            user.Email,
+		EmailVerified:    user.EmailVerified(),
+		TwoFactorEnabled: user.TwoFactorEnabled(),
+		Bio:              user.Bio,
+		ProfilePhoto:     user.ProfilePhoto,
+		Language:         user.Language,
+		Timezone:         user.Timezone,
+		Currency:         user.Currency,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+	}
+}
+
+// generateToken starts a session for the device making the request and
+// issues a JWT for it, carrying the user's language, time zone, week start,
+// roles and permissions and whether their email is still unverified. Signing
+// in cancels a scheduled deletion.
+func (h *UserHandlers) generateToken(c *gin.Context, user *models.User) (string, error) {
+	h.cancelDeletion(c, user.ID)
+
+	roles, err := h.userRoles(c.Request.Context(), user)
+	if err != nil {
+		return "", err
+	}
+
+	settings, err := h.settingsRepo.Get(c.Request.Context(), user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	expiresIn := time.Duration(h.config.JWTExpireHours) * time.Hour
+	session := &models.Session{
+		UserID:     user.ID,
+		DeviceName: services.DeviceName(c.GetHeader(constants.DeviceNameHeader), c.Request.UserAgent()),
+		UserAgent:  truncate(c.Request.UserAgent(), maxUserAgent),
+		IPAddress:  c.ClientIP(),
+		ExpiresAt:  time.Now().Add(expiresIn),
+	}
+	if err := h.sessionRepo.Create(c.Request.Context(), session); err != nil {
+		return "", err
+	}
+
+	return utils.GenerateJWT(utils.Claims{
+		UserID:      user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		Language:    user.Language,
+		Timezone:    user.Timezone,
+		WeekStart:   settings.WeekStart,
+		Unverified:  !user.EmailVerified(),
+		SessionID:   session.ID,
+		Roles:       roles,
+		Permissions: authz.Permissions(roles),
+	}, h.config.JWTSecret, h.config.JWTExpireHours)
+}
+
+// userRoles returns every role the user has, starting with the user role.
+// Verified accounts listed in ADMIN_EMAILS are granted the admin role, so a
+// new deployment has someone to grant roles to others.
+func (h *UserHandlers) userRoles(ctx context.Context, user *models.User) ([]string, error) {
+	roles, err := h.roleRepo.List(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// An unverified address could belong to anyone who registered it first
+	if user.EmailVerified() && !slices.Contains(roles, authz.RoleAdmin) &&
+		slices.ContainsFunc(h.config.AdminEmails, func(email string) bool { return strings.EqualFold(email, user.Email) }) {
+		if err := h.roleRepo.Grant(ctx, user.ID, authz.RoleAdmin, 0); err != nil {
+			return nil, err
+		}
+		roles = append(roles, authz.RoleAdmin)
+	}
+
+	return append([]string{authz.RoleUser}, roles...), nil
+}
+
+// accountDisabled answers 403 and reports true when an administrator has
+// disabled the account
+func (h *UserHandlers) accountDisabled(c *gin.Context, user *models.User) bool {
+	if !user.Disabled() {
+		return false
+	}
+	utils.SendForbiddenResponse(c.Writer, constants.ErrAccountDisabled)
+	return true
+}
+
+// sendVerificationEmail stores a new verification token for the user's
+// current address and emails them the link, in their saved language
+func (h *UserHandlers) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	ttl := h.config.EmailVerificationTTL
+	link, err := h.issueLink(ctx, user.ID, models.TokenEmailVerification, "/auth/verify-email", ttl)
+	if err != nil {
+		return err
+	}
+	return h.mailer.Send(ctx, mail.VerifyEmail(user.Language, user.Email, user.Username, link, ttl))
+}
+
+// sendPasswordReset emails the account registered with email a reset link,
+// at most once per PASSWORD_RESET_RESEND_PERIOD. It runs after the response
+// has been sent, so unknown addresses and failures are only logged.
+func (h *UserHandlers) sendPasswordReset(ctx context.Context, email string) {
+	user, err := h.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			slog.Warn("failed to look up password reset email", "error", err)
+		}
+		return
 	}
+
+	lastSent, sent, err := h.tokenRepo.LastIssued(ctx, user.ID, models.TokenPasswordReset)
+	if err != nil {
+		slog.Warn("failed to check password reset throttle", "user_id", user.ID, "error", err)
+		return
+	}
+	if sent && time.Since(lastSent) < h.config.PasswordResetResendPeriod {
+		slog.Info("password reset throttled", "user_id", user.ID)
+		return
+	}
+
+	ttl := h.config.PasswordResetTTL
+	link, err := h.issueLink(ctx, user.ID, models.TokenPasswordReset, "/auth/reset-password", ttl)
+	if err != nil {
+		slog.Warn("failed to issue password reset token", "user_id", user.ID, "error", err)
+		return
+	}
+	if err := h.mailer.Send(ctx, mail.ResetPassword(user.Language, user.Email, user.Username, link, ttl)); err != nil {
+		slog.Warn("failed to send password reset email", "user_id", user.ID, "error", err)
+	}
+}
+
+// issueLink stores a new token for purpose and returns the public link carrying it
+func (h *UserHandlers) issueLink(ctx context.Context, userID int64, purpose, path string, ttl time.Duration) (string, error) {
+	token, hash, err := h.tokens.Issue(purpose)
+	if err != nil {
+		return "", err
+	}
+
+	if err := h.tokenRepo.Create(ctx, userID, purpose, hash, time.Now().Add(ttl)); err != nil {
+		return "", err
+	}
+
+	return h.config.PublicURL + path + "?token=" + url.QueryEscape(token), nil
 }
@@ -1,14 +1,28 @@
 package main
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"os"
 	"time"
 
 	"dailytrackr/shared/config"
+	"dailytrackr/shared/constants"
 	"dailytrackr/shared/database"
+	"dailytrackr/shared/events"
+	"dailytrackr/shared/flags"
+	"dailytrackr/shared/health"
+	"dailytrackr/shared/lifecycle"
+	"dailytrackr/shared/logger"
+	"dailytrackr/shared/metrics"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/sessions"
+	"dailytrackr/shared/tracing"
+	"dailytrackr/user-service/consumers"
 	"dailytrackr/user-service/handlers"
+	"dailytrackr/user-service/models"
 	"dailytrackr/user-service/routes"
+	"dailytrackr/user-service/services"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -17,19 +31,52 @@ import (
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
+	log := logger.Init(cfg, "user-service")
+
+	shutdownTracing, err := tracing.Init(cfg, "user-service")
+	if err != nil {
+		log.Error("failed to initialise tracing", "error", err)
+		os.Exit(1)
+	}
+
+	runner := lifecycle.New(log, cfg.ShutdownTimeout)
+	runner.OnStop("tracing", shutdownTracing)
 
 	// Initialize database connection
 	db, err := database.GetMySQLConnection(cfg)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Error("failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	runner.OnStop("database", lifecycle.Closer(db.Close))
+	metrics.RegisterDBStats(metrics.PoolStats(db))
+
+	// The user service also owns the feature_flags table read by FLAGS_SOURCE=db
+	migrations := append(append(events.Migrations, flags.Migrations...), models.Migrations...)
+	if err := database.Migrate(context.Background(), db, cfg, migrations); err != nil {
+		log.Error("failed to apply migrations", "error", err)
+		os.Exit(1)
 	}
-	defer db.Close()
 
-	// Test database connection
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Database ping failed: %v", err)
+	// Domain events: relay the outbox so other services see user changes
+	transport, err := events.NewTransport(cfg)
+	if err != nil {
+		log.Error("failed to connect event transport", "error", err)
+		os.Exit(1)
 	}
-	log.Println("✅ Database connection established")
+	runner.OnStop("events", lifecycle.Closer(transport.Close))
+	relay := events.NewRelay(db, constants.UserService, transport, log, cfg.EventRelayInterval, cfg.EventRelayBatchSize)
+	runner.Go("outbox-relay", relay.Run)
+	runner.Go("purge-events", events.Consume(transport, consumers.ConsumerName, consumers.NewPurgeEvents(db).Handler(), log))
+
+	// Accounts are deleted once their deletion grace period ends
+	runner.Go("account-purger", models.NewAccountPurger(db, log, services.NewPhotoService(cfg).DeleteStoredPhoto, 10*time.Minute).Run)
+
+	// Brute-force protection only needs failed logins from within the per-IP window
+	runner.Go("login-failure-pruner", models.NewLoginFailurePruner(db, log, cfg.LoginIPWindow, time.Minute).Run)
+
+	// Export archives are deleted once their download links expire
+	runner.Go("export-cleaner", models.NewExportCleaner(db, log, cfg.ExportDir, cfg.ExportLinkTTL, 30*time.Minute, time.Hour).Run)
 
 	// Initialize handlers with database
 	userHandlers := handlers.NewUserHandlers(db, cfg)
@@ -39,13 +86,19 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(ginmw.RequestID())
+	r.Use(ginmw.Tracing())
+	r.Use(ginmw.Language())
+	r.Use(ginmw.AccessLog(log))
+	r.Use(ginmw.Metrics())
 
 	// CORS middleware
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // Untuk development
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Accept", "X-Device-Name"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: false, // Set to false when using wildcard
 		MaxAge:           12 * time.Hour,
@@ -66,51 +119,35 @@ func main() {
 		c.Next()
 	})
 
+	// Prometheus metrics endpoint
+	r.GET("/metrics", ginmw.MetricsHandler())
+
 	// Health check endpoint
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"service":  "user-service",
-			"status":   "healthy",
-			"version":  "1.0.0",
-			"features": []string{"authentication", "profile_management", "photo_upload"},
-			"cors":     "enabled",
-		})
-	})
+	checks := health.NewRegistry("user-service", "1.0.0", "authentication", "profile_management", "photo_upload").
+		Critical("database", health.Ping(db)).
+		Optional("cloudinary", health.Cloudinary(cfg)).
+		Optional("event_outbox", relay.Lag())
+	r.GET("/health", ginmw.Health(checks))
 
 	// Setup routes
-	routes.SetupUserRoutes(r, userHandlers)
+	routes.SetupUserRoutes(r, userHandlers, sessions.NewChecker(db))
 
 	// Start server
 	port := ":" + cfg.UserServicePort
-	log.Printf("🚀 User Service starting on port %s", port)
-	log.Printf("📋 Available endpoints:")
-	log.Printf("   Authentication:")
-	log.Printf("   - POST /auth/register")
-	log.Printf("   - POST /auth/login")
-	log.Printf("   Profile Management:")
-	log.Printf("   - GET  /api/v1/users/profile")
-	log.Printf("   - PUT  /api/v1/users/profile")
-	log.Printf("   - PATCH /api/v1/users/profile")
-	log.Printf("   Security:")
-	log.Printf("   - PUT  /api/v1/users/password")
-	log.Printf("   - PATCH /api/v1/users/password")
-	log.Printf("   Photo:")
-	log.Printf("   - POST /api/v1/users/profile/photo")
-	log.Printf("   - PUT  /api/v1/users/profile/photo")
-	log.Printf("   Account:")
-	log.Printf("   - DELETE /api/v1/users/account")
-	log.Printf("   Service:")
-	log.Printf("   - GET  /api/v1/users/:id")
-	log.Printf("   - GET  /health")
-	log.Printf("🌐 Service URL: http://localhost:%s", cfg.UserServicePort)
+	for _, route := range r.Routes() {
+		log.Debug("route registered", "method", route.Method, "path", route.Path)
+	}
 
 	// Warn if Cloudinary not configured
 	if cfg.CloudinaryCloudName == "" || cfg.CloudinaryAPIKey == "" || cfg.CloudinaryAPISecret == "" {
-		log.Printf("⚠️  Warning: Cloudinary not configured. Photo upload will be disabled.")
-		log.Printf("   To enable photo upload, set CLOUDINARY_CLOUD_NAME, CLOUDINARY_API_KEY, and CLOUDINARY_API_SECRET in .env")
-	} else {
-		log.Printf("📸 Photo upload service enabled with Cloudinary")
+		log.Warn("cloudinary not configured, photo upload disabled",
+			"hint", "set CLOUDINARY_CLOUD_NAME, CLOUDINARY_API_KEY and CLOUDINARY_API_SECRET")
 	}
 
-	log.Fatal(r.Run(port))
+	log.Info("user service starting", "port", cfg.UserServicePort)
+	runner.AddServer("http", ginmw.Server(r, port))
+	if err := runner.Run(); err != nil {
+		log.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
 }
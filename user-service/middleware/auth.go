@@ -5,13 +5,27 @@ import (
 
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/sessions"
 	"dailytrackr/shared/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// unverifiedRoutes stay open to accounts with an unverified email whatever
+// UNVERIFIED_POLICY says, so their owners can get another link, correct the
+// address, sign out or leave
+var unverifiedRoutes = map[string]bool{
+	"POST /auth/resend-verification":           true,
+	"PUT /api/v1/users/profile":                true,
+	"PATCH /api/v1/users/profile":              true,
+	"DELETE /api/v1/users/sessions":            true,
+	"DELETE /api/v1/users/sessions/:sessionId": true,
+	"DELETE /api/v1/users/account":             true,
+}
+
 // AuthMiddleware validates JWT tokens
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(checker *sessions.Checker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get authorization header
 		authHeader := c.GetHeader(constants.AuthorizationHeader)
@@ -45,10 +59,34 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens signed out since they were issued, e.g. by a password reset
+		active, err := checker.Active(c.Request.Context(), claims)
+		if err != nil {
+			utils.SendInternalServerErrorResponse(c.Writer, "Failed to check session", err)
+			c.Abort()
+			return
+		}
+		if !active {
+			utils.SendUnauthorizedResponse(c.Writer, constants.ErrInvalidToken)
+			c.Abort()
+			return
+		}
+
+		// Hold unverified accounts to the same policy as the other services
+		if !unverifiedRoutes[c.Request.Method+" "+c.FullPath()] && !claims.Allows(cfg.UnverifiedPolicy, c.Request.Method) {
+			utils.SendForbiddenResponse(c.Writer, constants.ErrEmailNotVerified)
+			c.Abort()
+			return
+		}
+
 		// Set user information in context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
+		c.Set("session_id", claims.SessionID)
+		ginmw.SetLanguagePreference(c, claims.Language)
+		ginmw.SetTimezone(c, claims.Timezone)
+		ginmw.SetPermissions(c, claims.Permissions)
 
 		c.Next()
 	}
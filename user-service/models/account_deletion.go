@@ -0,0 +1,387 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/events"
+	"dailytrackr/shared/tracing"
+)
+
+// Account deletion statuses. A deletion is scheduled until its grace period
+// ends, purging once the user is gone from this service, and completed when
+// every service in PurgeServices has confirmed removing the user's data.
+const (
+	DeletionScheduled = "scheduled"
+	DeletionCancelled = "cancelled"
+	DeletionPurging   = "purging"
+	DeletionCompleted = "completed"
+)
+
+// PurgeServices are the services holding user data that confirm its removal.
+// Statistics are computed from activities and habits, so they go with them.
+var PurgeServices = []string{constants.ActivityService, constants.HabitService, constants.AIService}
+
+// AccountDeletion is the record of a deletion request. It outlives the
+// account, identifying it only by ID and a hash of its email, so the owner
+// can check that the deletion went through.
+type AccountDeletion struct {
+	ID            string
+	UserID        int64
+	EmailHash     string
+	Status        string
+	RequestedAt   time.Time
+	ScheduledFor  time.Time
+	CancelledAt   *time.Time
+	PurgedAt      *time.Time
+	CompletedAt   *time.Time
+	Confirmations []DeletionConfirmation
+}
+
+// DeletionConfirmation is a service's confirmation that it removed a deleted
+// user's data, with how many records it removed
+type DeletionConfirmation struct {
+	Service     string
+	Records     int64
+	ConfirmedAt time.Time
+}
+
+// HashEmail is the form emails are kept in on deletion records
+func HashEmail(email string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return hex.EncodeToString(sum[:])
+}
+
+// AccountDeletionRepository handles scheduled account deletions
+type AccountDeletionRepository struct {
+	db *sql.DB
+}
+
+// NewAccountDeletionRepository creates a new account deletion repository
+func NewAccountDeletionRepository(db *sql.DB) *AccountDeletionRepository {
+	return &AccountDeletionRepository{db: db}
+}
+
+// Schedule records that the user's account is to be deleted at scheduledFor
+// and signs them out everywhere
+func (r *AccountDeletionRepository) Schedule(ctx context.Context, user *User, scheduledFor time.Time) (*AccountDeletion, error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletionRepository.Schedule")
+	defer span.End()
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	deletion := &AccountDeletion{
+		ID:           hex.EncodeToString(id),
+		UserID:       user.ID,
+		EmailHash:    HashEmail(user.Email),
+		Status:       DeletionScheduled,
+		RequestedAt:  time.Now(),
+		ScheduledFor: scheduledFor,
+	}
+
+	query := `
+		INSERT INTO account_deletions (id, user_id, email_hash, status, scheduled_for)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, deletion.ID, deletion.UserID, deletion.EmailHash, deletion.Status, scheduledFor); err != nil {
+			return err
+		}
+
+		// Tokens without a session are covered by sessions_revoked_at
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET sessions_revoked_at = CURRENT_TIMESTAMP WHERE id = ?", user.ID); err != nil {
+			return err
+		}
+		_, err := revokeSessions(ctx, tx, user.ID, "")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deletion, nil
+}
+
+// Scheduled returns the user's scheduled deletion, or sql.ErrNoRows
+func (r *AccountDeletionRepository) Scheduled(ctx context.Context, userID int64) (*AccountDeletion, error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletionRepository.Scheduled")
+	defer span.End()
+
+	query := `
+		SELECT id, user_id, email_hash, status, requested_at, scheduled_for, cancelled_at, purged_at, completed_at
+		FROM account_deletions
+		WHERE user_id = ? AND status = ?
+		ORDER BY requested_at DESC
+		LIMIT 1
+	`
+	return scanDeletion(r.db.QueryRowContext(ctx, query, userID, DeletionScheduled))
+}
+
+// Cancel cancels the user's scheduled deletion, reporting whether there was one
+func (r *AccountDeletionRepository) Cancel(ctx context.Context, userID int64) (bool, error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletionRepository.Cancel")
+	defer span.End()
+
+	query := `
+		UPDATE account_deletions
+		SET status = ?, cancelled_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND status = ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, DeletionCancelled, userID, DeletionScheduled)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected > 0, err
+}
+
+// GetByID returns a deletion record with its confirmations, or sql.ErrNoRows
+func (r *AccountDeletionRepository) GetByID(ctx context.Context, id string) (*AccountDeletion, error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletionRepository.GetByID")
+	defer span.End()
+
+	query := `
+		SELECT id, user_id, email_hash, status, requested_at, scheduled_for, cancelled_at, purged_at, completed_at
+		FROM account_deletions
+		WHERE id = ?
+	`
+	deletion, err := scanDeletion(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT service, records, confirmed_at FROM account_deletion_confirmations WHERE deletion_id = ? ORDER BY confirmed_at", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var confirmation DeletionConfirmation
+		if err := rows.Scan(&confirmation.Service, &confirmation.Records, &confirmation.ConfirmedAt); err != nil {
+			return nil, err
+		}
+		deletion.Confirmations = append(deletion.Confirmations, confirmation)
+	}
+	return deletion, rows.Err()
+}
+
+// Due returns up to limit scheduled deletions whose grace period ended by now
+func (r *AccountDeletionRepository) Due(ctx context.Context, now time.Time, limit int) ([]AccountDeletion, error) {
+	ctx, span := tracing.Start(ctx, "AccountDeletionRepository.Due")
+	defer span.End()
+
+	query := `
+		SELECT id, user_id, email_hash, status, requested_at, scheduled_for, cancelled_at, purged_at, completed_at
+		FROM account_deletions
+		WHERE status = ? AND scheduled_for <= ?
+		ORDER BY scheduled_for
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, DeletionScheduled, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deletions []AccountDeletion
+	for rows.Next() {
+		deletion, err := scanDeletion(rows)
+		if err != nil {
+			return nil, err
+		}
+		deletions = append(deletions, *deletion)
+	}
+	return deletions, rows.Err()
+}
+
+// Purge deletes the account of a scheduled deletion; its other rows here go
+// with it through their foreign keys, and the user.deleted event has the
+// other services purge theirs. It returns sql.ErrNoRows when the deletion
+// was cancelled meanwhile.
+func (r *AccountDeletionRepository) Purge(ctx context.Context, deletion *AccountDeletion) error {
+	ctx, span := tracing.Start(ctx, "AccountDeletionRepository.Purge")
+	defer span.End()
+
+	query := `
+		UPDATE account_deletions
+		SET status = ?, purged_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = ?
+	`
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, DeletionPurging, deletion.ID, DeletionScheduled)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return sql.ErrNoRows
+		}
+
+		// A user already gone still has the other services purge
+		if _, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = ?", deletion.UserID); err != nil {
+			return err
+		}
+		return recordUserEvent(ctx, tx, events.UserDeleted, deletion.UserID, events.UserPayload{})
+	})
+}
+
+// Confirm records that service purged the data of a deleted user and
+// completes the deletion once every service has. Confirmations for users
+// without a purged deletion record, such as redeliveries after completion,
+// are ignored.
+func (r *AccountDeletionRepository) Confirm(ctx context.Context, userID int64, service string, records int64) error {
+	ctx, span := tracing.Start(ctx, "AccountDeletionRepository.Confirm")
+	defer span.End()
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		var id string
+		query := "SELECT id FROM account_deletions WHERE user_id = ? AND status = ? FOR UPDATE"
+		if err := tx.QueryRowContext(ctx, query, userID, DeletionPurging).Scan(&id); err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+
+		// The first confirmation stands; redelivered events find nothing more to count
+		query = "INSERT IGNORE INTO account_deletion_confirmations (deletion_id, service, records) VALUES (?, ?, ?)"
+		if _, err := tx.ExecContext(ctx, query, id, service, records); err != nil {
+			return err
+		}
+
+		args := []interface{}{id}
+		for _, service := range PurgeServices {
+			args = append(args, service)
+		}
+
+		var confirmed int
+		query = "SELECT COUNT(*) FROM account_deletion_confirmations WHERE deletion_id = ? AND service IN (?" + strings.Repeat(", ?", len(PurgeServices)-1) + ")"
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(&confirmed); err != nil {
+			return err
+		}
+		if confirmed < len(PurgeServices) {
+			return nil
+		}
+
+		_, err := tx.ExecContext(ctx, "UPDATE account_deletions SET status = ?, completed_at = CURRENT_TIMESTAMP WHERE id = ?", DeletionCompleted, id)
+		return err
+	})
+}
+
+// scanDeletion reads one deletion row without its confirmations
+func scanDeletion(row interface{ Scan(...interface{}) error }) (*AccountDeletion, error) {
+	deletion := &AccountDeletion{}
+	var cancelledAt, purgedAt, completedAt sql.NullTime
+
+	err := row.Scan(&deletion.ID, &deletion.UserID, &deletion.EmailHash, &deletion.Status, &deletion.RequestedAt,
+		&deletion.ScheduledFor, &cancelledAt, &purgedAt, &completedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if cancelledAt.Valid {
+		deletion.CancelledAt = &cancelledAt.Time
+	}
+	if purgedAt.Valid {
+		deletion.PurgedAt = &purgedAt.Time
+	}
+	if completedAt.Valid {
+		deletion.CompletedAt = &completedAt.Time
+	}
+	return deletion, nil
+}
+
+// AccountPurger periodically deletes the accounts whose deletion grace period
+// has ended, along with their profile photos
+type AccountPurger struct {
+	repo        *AccountDeletionRepository
+	users       *UserRepository
+	deletePhoto func(ctx context.Context, url string) error
+	log         *slog.Logger
+	interval    time.Duration
+}
+
+// NewAccountPurger creates a purger that removes profile photos with deletePhoto
+func NewAccountPurger(db *sql.DB, log *slog.Logger, deletePhoto func(ctx context.Context, url string) error, interval time.Duration) *AccountPurger {
+	return &AccountPurger{
+		repo:        NewAccountDeletionRepository(db),
+		users:       NewUserRepository(db),
+		deletePhoto: deletePhoto,
+		log:         log,
+		interval:    interval,
+	}
+}
+
+// Run purges until ctx is cancelled; register it with lifecycle.Runner.Go
+func (p *AccountPurger) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		deletions, err := p.repo.Due(ctx, time.Now(), 100)
+		if err != nil {
+			if ctx.Err() == nil {
+				p.log.Warn("failed to list due account deletions", "error", err)
+			}
+			continue
+		}
+
+		for i := range deletions {
+			p.purge(ctx, &deletions[i])
+		}
+	}
+}
+
+// purge deletes one account's profile photo, then the account. When the
+// photo cannot be deleted the account is left for the next run, so it is
+// never purged with its photo still stored.
+func (p *AccountPurger) purge(ctx context.Context, deletion *AccountDeletion) {
+	user, err := p.users.GetByID(ctx, deletion.UserID)
+	if err != nil && err != sql.ErrNoRows {
+		p.log.Warn("failed to load user to purge", "deletion_id", deletion.ID, "user_id", deletion.UserID, "error", err)
+		return
+	}
+
+	if user != nil && user.ProfilePhoto != "" {
+		if err := p.deletePhoto(ctx, user.ProfilePhoto); err != nil {
+			p.log.Warn("failed to delete profile photo of account to purge, will retry", "deletion_id", deletion.ID, "user_id", deletion.UserID, "error", err)
+			return
+		}
+	}
+
+	if err := p.repo.Purge(ctx, deletion); err != nil {
+		if err != sql.ErrNoRows {
+			p.log.Warn("failed to purge account", "deletion_id", deletion.ID, "user_id", deletion.UserID, "error", err)
+		}
+		return
+	}
+
+	p.log.Info("account purged", "deletion_id", deletion.ID, "user_id", deletion.UserID)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"dailytrackr/shared/tracing"
+)
+
+// Audit events
+const (
+	AuditLoginIPBlocked    = "login_ip_blocked"
+	AuditAccountLocked     = "account_locked"
+	AuditAccountUnlocked   = "account_unlocked"
+	AuditDeletionScheduled = "deletion_scheduled"
+	AuditDeletionCancelled = "deletion_cancelled"
+	AuditAccountDisabled   = "account_disabled"
+	AuditAccountEnabled    = "account_enabled"
+	AuditTwoFactorReset    = "two_factor_reset"
+	AuditRolesChanged      = "roles_changed"
+)
+
+// AuditEntry records a security-relevant event. UserID is the account it
+// concerns and ActorID whoever caused it when that is someone else, such as
+// an administrator; zero means none.
+type AuditEntry struct {
+	UserID    int64
+	ActorID   int64
+	Event     string
+	IPAddress string
+	Detail    string
+}
+
+// AuditRepository appends to the audit log
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Record appends entry to the audit log
+func (r *AuditRepository) Record(ctx context.Context, entry AuditEntry) error {
+	ctx, span := tracing.Start(ctx, "AuditRepository.Record")
+	defer span.End()
+
+	query := `
+		INSERT INTO audit_log (user_id, actor_id, event, ip_address, detail)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, nullableID(entry.UserID), nullableID(entry.ActorID), entry.Event, entry.IPAddress, entry.Detail)
+	return err
+}
+
+// nullableID stores a zero ID as NULL
+func nullableID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
@@ -0,0 +1,258 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"dailytrackr/shared/tracing"
+)
+
+// Export statuses
+const (
+	ExportPending = "pending"
+	ExportReady   = "ready"
+	ExportFailed  = "failed"
+)
+
+// Export is a personal data export job. FilePath and ExpiresAt are set once
+// the archive is ready.
+type Export struct {
+	ID          string
+	UserID      int64
+	Status      string
+	FilePath    string
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+// Available reports whether the export is pending, or ready and not expired,
+// at now; otherwise a new one has to be started
+func (e *Export) Available(now time.Time) bool {
+	switch e.Status {
+	case ExportPending:
+		return true
+	case ExportReady:
+		return e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+	default:
+		return false
+	}
+}
+
+// ExportRepository handles personal data export jobs
+type ExportRepository struct {
+	db *sql.DB
+}
+
+// NewExportRepository creates a new export repository
+func NewExportRepository(db *sql.DB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+// Create starts a pending export for the user
+func (r *ExportRepository) Create(ctx context.Context, userID int64) (*Export, error) {
+	ctx, span := tracing.Start(ctx, "ExportRepository.Create")
+	defer span.End()
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	export := &Export{
+		ID:        hex.EncodeToString(id),
+		UserID:    userID,
+		Status:    ExportPending,
+		CreatedAt: time.Now(),
+	}
+
+	_, err := r.db.ExecContext(ctx, "INSERT INTO data_exports (id, user_id, status) VALUES (?, ?, ?)", export.ID, userID, export.Status)
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// Latest returns the user's most recent export, or sql.ErrNoRows
+func (r *ExportRepository) Latest(ctx context.Context, userID int64) (*Export, error) {
+	ctx, span := tracing.Start(ctx, "ExportRepository.Latest")
+	defer span.End()
+
+	query := `
+		SELECT id, user_id, status, file_path, error, created_at, completed_at, expires_at
+		FROM data_exports
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	return r.scan(r.db.QueryRowContext(ctx, query, userID))
+}
+
+// GetByID returns an export, or sql.ErrNoRows
+func (r *ExportRepository) GetByID(ctx context.Context, id string) (*Export, error) {
+	ctx, span := tracing.Start(ctx, "ExportRepository.GetByID")
+	defer span.End()
+
+	query := `
+		SELECT id, user_id, status, file_path, error, created_at, completed_at, expires_at
+		FROM data_exports
+		WHERE id = ?
+	`
+	return r.scan(r.db.QueryRowContext(ctx, query, id))
+}
+
+// Complete marks an export ready, with its archive at filePath until expiresAt
+func (r *ExportRepository) Complete(ctx context.Context, id, filePath string, expiresAt time.Time) error {
+	ctx, span := tracing.Start(ctx, "ExportRepository.Complete")
+	defer span.End()
+
+	query := `
+		UPDATE data_exports
+		SET status = ?, file_path = ?, completed_at = CURRENT_TIMESTAMP, expires_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query, ExportReady, filePath, expiresAt, id)
+	return err
+}
+
+// Fail marks an export failed with a short reason
+func (r *ExportRepository) Fail(ctx context.Context, id, reason string) error {
+	ctx, span := tracing.Start(ctx, "ExportRepository.Fail")
+	defer span.End()
+
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+
+	query := `
+		UPDATE data_exports
+		SET status = ?, error = ?, completed_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query, ExportFailed, reason, id)
+	return err
+}
+
+// FailStale fails exports still pending since before before, such as those
+// a restart interrupted
+func (r *ExportRepository) FailStale(ctx context.Context, before time.Time) error {
+	ctx, span := tracing.Start(ctx, "ExportRepository.FailStale")
+	defer span.End()
+
+	query := `
+		UPDATE data_exports
+		SET status = ?, error = 'interrupted', completed_at = CURRENT_TIMESTAMP
+		WHERE status = ? AND created_at < ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query, ExportFailed, ExportPending, before)
+	return err
+}
+
+// DeleteFinishedBefore deletes exports that expired, or failed, before before
+func (r *ExportRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) error {
+	ctx, span := tracing.Start(ctx, "ExportRepository.DeleteFinishedBefore")
+	defer span.End()
+
+	query := `
+		DELETE FROM data_exports
+		WHERE expires_at < ? OR (status = ? AND completed_at < ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, before, ExportFailed, before)
+	return err
+}
+
+// scan reads one export row
+func (r *ExportRepository) scan(row *sql.Row) (*Export, error) {
+	export := &Export{}
+	var completedAt, expiresAt sql.NullTime
+
+	err := row.Scan(&export.ID, &export.UserID, &export.Status, &export.FilePath, &export.Error, &export.CreatedAt, &completedAt, &expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+	}
+	return export, nil
+}
+
+// ExportCleaner periodically removes expired export archives and their rows,
+// and fails exports left pending by a restart
+type ExportCleaner struct {
+	repo       *ExportRepository
+	log        *slog.Logger
+	dir        string
+	ttl        time.Duration
+	staleAfter time.Duration
+	interval   time.Duration
+}
+
+// NewExportCleaner creates a cleaner for archives in dir that are downloadable for ttl
+func NewExportCleaner(db *sql.DB, log *slog.Logger, dir string, ttl, staleAfter, interval time.Duration) *ExportCleaner {
+	return &ExportCleaner{
+		repo:       NewExportRepository(db),
+		log:        log,
+		dir:        dir,
+		ttl:        ttl,
+		staleAfter: staleAfter,
+		interval:   interval,
+	}
+}
+
+// Run cleans up until ctx is cancelled; register it with lifecycle.Runner.Go
+func (c *ExportCleaner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		c.clean(ctx)
+	}
+}
+
+// clean does one round of cleanup. Archives are removed by age rather than
+// through their rows, so those of deleted accounts go too.
+func (c *ExportCleaner) clean(ctx context.Context) {
+	now := time.Now()
+
+	if err := c.repo.FailStale(ctx, now.Add(-c.staleAfter)); err != nil && ctx.Err() == nil {
+		c.log.Warn("failed to fail stale exports", "error", err)
+	}
+	if err := c.repo.DeleteFinishedBefore(ctx, now.Add(-c.ttl)); err != nil && ctx.Err() == nil {
+		c.log.Warn("failed to delete old exports", "error", err)
+	}
+
+	archives, err := filepath.Glob(filepath.Join(c.dir, "*.zip"))
+	if err != nil {
+		return
+	}
+	for _, archive := range archives {
+		info, err := os.Stat(archive)
+		if err != nil || now.Sub(info.ModTime()) < c.ttl {
+			continue
+		}
+		if err := os.Remove(archive); err != nil {
+			c.log.Warn("failed to remove export archive", "path", archive, "error", err)
+		}
+	}
+}
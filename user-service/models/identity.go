@@ -0,0 +1,54 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/tracing"
+)
+
+// IdentityRepository links users to their accounts at OpenID Connect providers
+type IdentityRepository struct {
+	db *sql.DB
+}
+
+// NewIdentityRepository creates a new identity repository
+func NewIdentityRepository(db *sql.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// FindUserID returns the user linked to a provider's subject, or sql.ErrNoRows
+func (r *IdentityRepository) FindUserID(ctx context.Context, provider, subject string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "IdentityRepository.FindUserID")
+	defer span.End()
+
+	var userID int64
+	query := "SELECT user_id FROM user_identities WHERE provider = ? AND subject = ?"
+	err := r.db.QueryRowContext(ctx, query, provider, subject).Scan(&userID)
+	return userID, err
+}
+
+// Link attaches a provider's subject to userID. When the provider vouches for
+// the email, the user's address counts as verified from now on.
+func (r *IdentityRepository) Link(ctx context.Context, userID int64, provider, subject, email string, emailVerified bool) error {
+	ctx, span := tracing.Start(ctx, "IdentityRepository.Link")
+	defer span.End()
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO user_identities (user_id, provider, subject, email)
+			VALUES (?, ?, ?, ?)
+		`, userID, provider, subject, email)
+		if err != nil || !emailVerified {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE users
+			SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP)
+			WHERE id = ? AND email = ?
+		`, userID, email)
+		return err
+	})
+}
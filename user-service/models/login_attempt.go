@@ -0,0 +1,185 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/tracing"
+)
+
+// LoginState is an account's run of failed passwords
+type LoginState struct {
+	Failures     int
+	LastFailedAt *time.Time
+	LockedUntil  *time.Time
+}
+
+// Locked reports whether the account is locked at now
+func (s *LoginState) Locked(now time.Time) bool {
+	return s.LockedUntil != nil && now.Before(*s.LockedUntil)
+}
+
+// LoginAttemptRepository tracks failed logins per account and per IP
+type LoginAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+// GetState returns the user's failed login state
+func (r *LoginAttemptRepository) GetState(ctx context.Context, userID int64) (*LoginState, error) {
+	ctx, span := tracing.Start(ctx, "LoginAttemptRepository.GetState")
+	defer span.End()
+
+	return getLoginState(ctx, r.db, userID)
+}
+
+// RecordFailure counts a wrong password for the user, locking the account
+// for lockout once lockAfter failures in a row are reached, and returns the
+// new state
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, userID int64, lockAfter int, lockout time.Duration) (*LoginState, error) {
+	ctx, span := tracing.Start(ctx, "LoginAttemptRepository.RecordFailure")
+	defer span.End()
+
+	// MySQL applies assignments left to right, so locked_until sees the old count
+	query := `
+		UPDATE users
+		SET locked_until = IF(failed_login_count + 1 >= ?, CURRENT_TIMESTAMP + INTERVAL ? SECOND, locked_until),
+			failed_login_count = failed_login_count + 1,
+			last_failed_login_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	var state *LoginState
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, lockAfter, int(lockout.Seconds()), userID); err != nil {
+			return err
+		}
+
+		var err error
+		state, err = getLoginState(ctx, tx, userID)
+		return err
+	})
+	return state, err
+}
+
+// Reset clears the user's failed logins and any lock
+func (r *LoginAttemptRepository) Reset(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "LoginAttemptRepository.Reset")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL
+		WHERE id = ? AND (failed_login_count > 0 OR locked_until IS NOT NULL)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
+
+// RecordIPFailure counts a failed login from ip, whether or not email belongs to an account
+func (r *LoginAttemptRepository) RecordIPFailure(ctx context.Context, ip, email string) error {
+	ctx, span := tracing.Start(ctx, "LoginAttemptRepository.RecordIPFailure")
+	defer span.End()
+
+	_, err := r.db.ExecContext(ctx, "INSERT INTO login_failures (ip_address, email) VALUES (?, ?)", ip, email)
+	return err
+}
+
+// IPFailures returns how many logins from ip failed since since, and when the oldest of them was
+func (r *LoginAttemptRepository) IPFailures(ctx context.Context, ip string, since time.Time) (int, *time.Time, error) {
+	ctx, span := tracing.Start(ctx, "LoginAttemptRepository.IPFailures")
+	defer span.End()
+
+	var count int
+	var oldest sql.NullTime
+	query := "SELECT COUNT(*), MIN(created_at) FROM login_failures WHERE ip_address = ? AND created_at >= ?"
+	if err := r.db.QueryRowContext(ctx, query, ip, since).Scan(&count, &oldest); err != nil {
+		return 0, nil, err
+	}
+
+	if !oldest.Valid {
+		return count, nil, nil
+	}
+	return count, &oldest.Time, nil
+}
+
+// PruneIPFailures deletes failed logins recorded before before
+func (r *LoginAttemptRepository) PruneIPFailures(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "LoginAttemptRepository.PruneIPFailures")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM login_failures WHERE created_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// LoginFailurePruner periodically deletes failed logins that have left the
+// per-IP window, so the table only holds what the limit needs
+type LoginFailurePruner struct {
+	repo     *LoginAttemptRepository
+	log      *slog.Logger
+	window   time.Duration
+	interval time.Duration
+}
+
+// NewLoginFailurePruner creates a pruner keeping the last window of failures
+func NewLoginFailurePruner(db *sql.DB, log *slog.Logger, window, interval time.Duration) *LoginFailurePruner {
+	return &LoginFailurePruner{
+		repo:     NewLoginAttemptRepository(db),
+		log:      log,
+		window:   window,
+		interval: interval,
+	}
+}
+
+// Run prunes until ctx is cancelled; register it with lifecycle.Runner.Go
+func (p *LoginFailurePruner) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if _, err := p.repo.PruneIPFailures(ctx, time.Now().Add(-p.window)); err != nil && ctx.Err() == nil {
+			p.log.Warn("failed to prune login failures", "error", err)
+		}
+	}
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// getLoginState reads the user's failed login state through q
+func getLoginState(ctx context.Context, q rowQuerier, userID int64) (*LoginState, error) {
+	var lastFailedAt, lockedUntil sql.NullTime
+	state := &LoginState{}
+
+	query := "SELECT failed_login_count, last_failed_login_at, locked_until FROM users WHERE id = ?"
+	if err := q.QueryRowContext(ctx, query, userID).Scan(&state.Failures, &lastFailedAt, &lockedUntil); err != nil {
+		return nil, err
+	}
+
+	if lastFailedAt.Valid {
+		state.LastFailedAt = &lastFailedAt.Time
+	}
+	if lockedUntil.Valid {
+		state.LockedUntil = &lockedUntil.Time
+	}
+	return state, nil
+}
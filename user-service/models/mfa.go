@@ -0,0 +1,175 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/tracing"
+)
+
+// TOTP is a user's two-factor state. Secret is sealed; it is set while
+// enrollment is pending and stays set once Enabled.
+type TOTP struct {
+	Secret  string
+	Enabled bool
+}
+
+// MFARepository handles TOTP secrets and recovery codes
+type MFARepository struct {
+	db *sql.DB
+}
+
+// NewMFARepository creates a new MFA repository
+func NewMFARepository(db *sql.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// GetTOTP returns the user's two-factor state
+func (r *MFARepository) GetTOTP(ctx context.Context, userID int64) (*TOTP, error) {
+	ctx, span := tracing.Start(ctx, "MFARepository.GetTOTP")
+	defer span.End()
+
+	totp := &TOTP{}
+	query := "SELECT COALESCE(totp_secret, ''), totp_enabled_at IS NOT NULL FROM users WHERE id = ?"
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&totp.Secret, &totp.Enabled); err != nil {
+		return nil, err
+	}
+	return totp, nil
+}
+
+// SetPendingSecret starts (or restarts) enrollment with a sealed secret.
+// It returns sql.ErrNoRows if two-factor authentication is already enabled.
+func (r *MFARepository) SetPendingSecret(ctx context.Context, userID int64, secret string) error {
+	ctx, span := tracing.Start(ctx, "MFARepository.SetPendingSecret")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET totp_secret = ?, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND totp_enabled_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, secret, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// Enable turns two-factor authentication on after the code for step confirmed
+// the pending secret, replacing any recovery codes with codeHashes
+func (r *MFARepository) Enable(ctx context.Context, userID, step int64, codeHashes []string) error {
+	ctx, span := tracing.Start(ctx, "MFARepository.Enable")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET totp_enabled_at = CURRENT_TIMESTAMP, totp_last_step = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL
+	`
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, step, userID)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return sql.ErrNoRows
+		}
+
+		return replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+// Disable turns two-factor authentication off and drops the secret and recovery codes
+func (r *MFARepository) Disable(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "MFARepository.Disable")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return err
+		}
+		return replaceRecoveryCodes(ctx, tx, userID, nil)
+	})
+}
+
+// UseStep records that the code for step was accepted. It reports false when
+// that step or a later one was already used, so each code works only once.
+func (r *MFARepository) UseStep(ctx context.Context, userID, step int64) (bool, error) {
+	ctx, span := tracing.Start(ctx, "MFARepository.UseStep")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET totp_last_step = ?
+		WHERE id = ? AND (totp_last_step IS NULL OR totp_last_step < ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected == 1, err
+}
+
+// UseRecoveryCode marks an unused recovery code as used, reporting whether there was one
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "MFARepository.UseRecoveryCode")
+	defer span.End()
+
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+		LIMIT 1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return rowsAffected == 1, err
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes within tx and stores codeHashes instead
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_recovery_codes WHERE user_id = ?", userID); err != nil {
+		return err
+	}
+
+	for _, hash := range codeHashes {
+		_, err := tx.ExecContext(ctx, "INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)", userID, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package models
+
+import "dailytrackr/shared/database"
+
+// Migrations lists the schema changes owned by the user service, oldest first
+var Migrations = []database.Migration{
+	{
+		ID:  "user-service/001_users_language",
+		SQL: "ALTER TABLE users ADD COLUMN language VARCHAR(5) NOT NULL DEFAULT 'en'",
+	},
+	{
+		ID:  "user-service/002_users_timezone",
+		SQL: "ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC'",
+	},
+	{
+		ID:  "user-service/003_users_currency",
+		SQL: "ALTER TABLE users ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR'",
+	},
+	{
+		ID:  "user-service/004_users_email_verified_at",
+		SQL: "ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP NULL",
+	},
+	{
+		// Accounts created before verification existed are trusted as they are
+		ID:  "user-service/005_backfill_email_verified_at",
+		SQL: "UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL",
+	},
+	{
+		ID: "user-service/006_user_tokens",
+		SQL: `CREATE TABLE IF NOT EXISTS user_tokens (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			purpose VARCHAR(32) NOT NULL,
+			token_hash CHAR(64) NOT NULL UNIQUE,
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_user_tokens_user_purpose (user_id, purpose, created_at),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+	},
+	{
+		// Tokens issued before this time are rejected by every service
+		ID:  "user-service/007_users_sessions_revoked_at",
+		SQL: "ALTER TABLE users ADD COLUMN sessions_revoked_at TIMESTAMP NULL",
+	},
+	{
+		// Sealed TOTP secret; set at enrollment, enabled once a code confirms it
+		ID:  "user-service/008_users_totp_secret",
+		SQL: "ALTER TABLE users ADD COLUMN totp_secret VARCHAR(255) NULL",
+	},
+	{
+		ID:  "user-service/009_users_totp_enabled_at",
+		SQL: "ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMP NULL",
+	},
+	{
+		// Last accepted time step, so a code cannot be replayed
+		ID:  "user-service/010_users_totp_last_step",
+		SQL: "ALTER TABLE users ADD COLUMN totp_last_step BIGINT NULL",
+	},
+	{
+		ID: "user-service/011_user_recovery_codes",
+		SQL: `CREATE TABLE IF NOT EXISTS user_recovery_codes (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			code_hash CHAR(64) NOT NULL,
+			used_at TIMESTAMP NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_user_recovery_codes_user (user_id, code_hash),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+	},
+	{
+		ID: "user-service/012_user_identities",
+		SQL: `CREATE TABLE IF NOT EXISTS user_identities (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			provider VARCHAR(64) NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			email VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE KEY uq_user_identities_subject (provider, subject),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+	},
+	{
+		ID: "user-service/013_user_sessions",
+		SQL: `CREATE TABLE IF NOT EXISTS user_sessions (
+			id CHAR(32) PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			device_name VARCHAR(100) NOT NULL DEFAULT '',
+			user_agent VARCHAR(512) NOT NULL DEFAULT '',
+			ip_address VARCHAR(45) NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP NOT NULL,
+			revoked_at TIMESTAMP NULL,
+			INDEX idx_user_sessions_user (user_id, revoked_at),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+	},
+	{
+		// Failed passwords in a row; reset by a successful login or an unlock
+		ID:  "user-service/014_users_failed_login_count",
+		SQL: "ALTER TABLE users ADD COLUMN failed_login_count INT NOT NULL DEFAULT 0",
+	},
+	{
+		ID:  "user-service/015_users_last_failed_login_at",
+		SQL: "ALTER TABLE users ADD COLUMN last_failed_login_at TIMESTAMP NULL",
+	},
+	{
+		ID:  "user-service/016_users_locked_until",
+		SQL: "ALTER TABLE users ADD COLUMN locked_until TIMESTAMP NULL",
+	},
+	{
+		// Failed logins by IP, including unknown emails; pruned once out of the window
+		ID: "user-service/017_login_failures",
+		SQL: `CREATE TABLE IF NOT EXISTS login_failures (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			ip_address VARCHAR(45) NOT NULL,
+			email VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_login_failures_ip (ip_address, created_at),
+			INDEX idx_login_failures_created (created_at)
+		)`,
+	},
+	{
+		ID: "user-service/018_audit_log",
+		SQL: `CREATE TABLE IF NOT EXISTS audit_log (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			user_id BIGINT NULL,
+			actor_id BIGINT NULL,
+			event VARCHAR(64) NOT NULL,
+			ip_address VARCHAR(45) NOT NULL DEFAULT '',
+			detail VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			INDEX idx_audit_log_user (user_id, created_at),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+		)`,
+	},
+	{
+		ID: "user-service/019_data_exports",
+		SQL: `CREATE TABLE IF NOT EXISTS data_exports (
+			id CHAR(32) PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			status VARCHAR(16) NOT NULL DEFAULT 'pending',
+			file_path VARCHAR(512) NOT NULL DEFAULT '',
+			error VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			completed_at TIMESTAMP NULL,
+			expires_at TIMESTAMP NULL,
+			INDEX idx_data_exports_user (user_id, created_at),
+			INDEX idx_data_exports_expires (expires_at),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+	},
+	{
+		// No foreign key: the record outlives the account it deletes
+		ID: "user-service/020_account_deletions",
+		SQL: `CREATE TABLE IF NOT EXISTS account_deletions (
+			id CHAR(32) PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			email_hash CHAR(64) NOT NULL,
+			status VARCHAR(16) NOT NULL DEFAULT 'scheduled',
+			requested_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			scheduled_for TIMESTAMP NOT NULL,
+			cancelled_at TIMESTAMP NULL,
+			purged_at TIMESTAMP NULL,
+			completed_at TIMESTAMP NULL,
+			INDEX idx_account_deletions_user (user_id, status),
+			INDEX idx_account_deletions_due (status, scheduled_for)
+		)`,
+	},
+	{
+		ID: "user-service/021_account_deletion_confirmations",
+		SQL: `CREATE TABLE IF NOT EXISTS account_deletion_confirmations (
+			deletion_id CHAR(32) NOT NULL,
+			service VARCHAR(32) NOT NULL,
+			records BIGINT NOT NULL DEFAULT 0,
+			confirmed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (deletion_id, service),
+			FOREIGN KEY (deletion_id) REFERENCES account_deletions(id) ON DELETE CASCADE
+		)`,
+	},
+	{
+		ID: "user-service/022_user_roles",
+		SQL: `CREATE TABLE IF NOT EXISTS user_roles (
+			user_id BIGINT NOT NULL,
+			role VARCHAR(32) NOT NULL,
+			granted_by BIGINT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, role),
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+	},
+	{
+		// Disabled accounts cannot sign in until an administrator enables them again
+		ID:  "user-service/023_users_disabled_at",
+		SQL: `ALTER TABLE users ADD COLUMN disabled_at TIMESTAMP NULL`,
+	},
+	{
+		// Language, time zone and currency stay on users; NULL here means the default
+		ID: "user-service/024_user_settings",
+		SQL: `CREATE TABLE IF NOT EXISTS user_settings (
+			user_id BIGINT PRIMARY KEY,
+			week_start VARCHAR(16) NULL,
+			theme VARCHAR(16) NULL,
+			notify_habit_reminders BOOLEAN NULL,
+			notify_weekly_summary BOOLEAN NULL,
+			notify_product_updates BOOLEAN NULL,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+		)`,
+	},
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/tracing"
+)
+
+// RoleRepository handles the roles granted to users; see shared/authz for
+// what each role permits
+type RoleRepository struct {
+	db *sql.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *sql.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// List returns the roles granted to a user, beyond the user role everyone has
+func (r *RoleRepository) List(ctx context.Context, userID int64) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "RoleRepository.List")
+	defer span.End()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT role FROM user_roles WHERE user_id = ? ORDER BY role", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// ListByUsers returns the roles granted to each of userIDs; users without
+// any are left out of the map
+func (r *RoleRepository) ListByUsers(ctx context.Context, userIDs []int64) (map[int64][]string, error) {
+	ctx, span := tracing.Start(ctx, "RoleRepository.ListByUsers")
+	defer span.End()
+
+	roles := map[int64][]string{}
+	if len(userIDs) == 0 {
+		return roles, nil
+	}
+
+	args := make([]any, len(userIDs))
+	for i, id := range userIDs {
+		args[i] = id
+	}
+	query := "SELECT user_id, role FROM user_roles WHERE user_id IN (?" + strings.Repeat(", ?", len(userIDs)-1) + ") ORDER BY user_id, role"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID int64
+		var role string
+		if err := rows.Scan(&userID, &role); err != nil {
+			return nil, err
+		}
+		roles[userID] = append(roles[userID], role)
+	}
+	return roles, rows.Err()
+}
+
+// Grant gives a user role if they do not have it yet
+func (r *RoleRepository) Grant(ctx context.Context, userID int64, role string, grantedBy int64) error {
+	ctx, span := tracing.Start(ctx, "RoleRepository.Grant")
+	defer span.End()
+
+	_, err := r.db.ExecContext(ctx, "INSERT IGNORE INTO user_roles (user_id, role, granted_by) VALUES (?, ?, ?)",
+		userID, role, nullableID(grantedBy))
+	return err
+}
+
+// Set replaces a user's roles with roles and signs them out everywhere, so
+// no token keeps permissions they no longer have
+func (r *RoleRepository) Set(ctx context.Context, userID int64, roles []string, grantedBy int64) error {
+	ctx, span := tracing.Start(ctx, "RoleRepository.Set")
+	defer span.End()
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM user_roles WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		for _, role := range roles {
+			_, err := tx.ExecContext(ctx, "INSERT IGNORE INTO user_roles (user_id, role, granted_by) VALUES (?, ?, ?)",
+				userID, role, nullableID(grantedBy))
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET sessions_revoked_at = CURRENT_TIMESTAMP WHERE id = ?", userID); err != nil {
+			return err
+		}
+		_, err := revokeSessions(ctx, tx, userID, "")
+		return err
+	})
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"time"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/tracing"
+)
+
+// Session is one sign-in of a user on a device. Every JWT carries the ID of
+// the session it was issued for.
+type Session struct {
+	ID         string
+	UserID     int64
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+}
+
+// SessionRepository handles user sessions
+type SessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create stores a new session, giving it a random ID
+func (r *SessionRepository) Create(ctx context.Context, session *Session) error {
+	ctx, span := tracing.Start(ctx, "SessionRepository.Create")
+	defer span.End()
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	session.ID = hex.EncodeToString(id)
+
+	query := `
+		INSERT INTO user_sessions (id, user_id, device_name, user_agent, ip_address, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, session.ID, session.UserID, session.DeviceName, session.UserAgent, session.IPAddress, session.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	session.CreatedAt = time.Now()
+	session.LastSeenAt = session.CreatedAt
+	return nil
+}
+
+// ListActive returns the user's sessions that are neither revoked nor
+// expired, most recently used first
+func (r *SessionRepository) ListActive(ctx context.Context, userID int64) ([]Session, error) {
+	ctx, span := tracing.Start(ctx, "SessionRepository.ListActive")
+	defer span.End()
+
+	query := `
+		SELECT id, user_id, device_name, user_agent, ip_address, created_at, last_seen_at, expires_at
+		FROM user_sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []Session
+	for rows.Next() {
+		var s Session
+		err := rows.Scan(&s.ID, &s.UserID, &s.DeviceName, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Revoke signs out one of the user's sessions. It returns sql.ErrNoRows if
+// the user has no such active session.
+func (r *SessionRepository) Revoke(ctx context.Context, userID int64, sessionID string) error {
+	ctx, span := tracing.Start(ctx, "SessionRepository.Revoke")
+	defer span.End()
+
+	query := `
+		UPDATE user_sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RevokeOthers signs out every session of the user except keepID and
+// returns how many were signed out
+func (r *SessionRepository) RevokeOthers(ctx context.Context, userID int64, keepID string) (int64, error) {
+	ctx, span := tracing.Start(ctx, "SessionRepository.RevokeOthers")
+	defer span.End()
+
+	var revoked int64
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		var err error
+		revoked, err = revokeSessions(ctx, tx, userID, keepID)
+		return err
+	})
+	return revoked, err
+}
+
+// revokeSessions signs out the user's sessions within tx, except keepID if set
+func revokeSessions(ctx context.Context, tx *sql.Tx, userID int64, keepID string) (int64, error) {
+	query := `
+		UPDATE user_sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND id <> ? AND revoked_at IS NULL
+	`
+
+	result, err := tx.ExecContext(ctx, query, userID, keepID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
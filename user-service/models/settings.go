@@ -0,0 +1,123 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/events"
+	"dailytrackr/shared/tracing"
+)
+
+// Settings are a user's preferences. Language, time zone and currency live on
+// the users row; the rest are in user_settings and take their defaults until
+// first saved.
+type Settings struct {
+	UserID         int64
+	Timezone       string
+	WeekStart      string
+	Currency       string
+	Language       string
+	Theme          string
+	HabitReminders bool
+	WeeklySummary  bool
+	ProductUpdates bool
+}
+
+// SettingsRepository handles user settings
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+// NewSettingsRepository creates a new settings repository
+func NewSettingsRepository(db *sql.DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+// Get returns a user's settings, with defaults for those never saved
+func (r *SettingsRepository) Get(ctx context.Context, userID int64) (*Settings, error) {
+	ctx, span := tracing.Start(ctx, "SettingsRepository.Get")
+	defer span.End()
+
+	query := `
+		SELECT u.id, u.timezone, u.currency, u.language,
+		       COALESCE(s.week_start, ?), COALESCE(s.theme, ?),
+		       COALESCE(s.notify_habit_reminders, ?), COALESCE(s.notify_weekly_summary, ?), COALESCE(s.notify_product_updates, ?)
+		FROM users u
+		LEFT JOIN user_settings s ON s.user_id = u.id
+		WHERE u.id = ?
+	`
+
+	settings := &Settings{}
+	err := r.db.QueryRowContext(ctx, query,
+		constants.DefaultWeekStart, constants.DefaultTheme,
+		constants.DefaultHabitReminders, constants.DefaultWeeklySummary, constants.DefaultProductUpdates,
+		userID,
+	).Scan(
+		&settings.UserID,
+		&settings.Timezone,
+		&settings.Currency,
+		&settings.Language,
+		&settings.WeekStart,
+		&settings.Theme,
+		&settings.HabitReminders,
+		&settings.WeeklySummary,
+		&settings.ProductUpdates,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// Save stores every setting for settings.UserID. Language, time zone and
+// currency are profile data too, so a user.updated event is recorded with them.
+func (r *SettingsRepository) Save(ctx context.Context, settings *Settings) error {
+	ctx, span := tracing.Start(ctx, "SettingsRepository.Save")
+	defer span.End()
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE users 
+			SET timezone = ?, currency = ?, language = ?, updated_at = CURRENT_TIMESTAMP 
+			WHERE id = ?
+		`, settings.Timezone, settings.Currency, settings.Language, settings.UserID)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return sql.ErrNoRows
+		}
+
+		payload := events.UserPayload{
+			Language: settings.Language,
+			Timezone: settings.Timezone,
+			Currency: settings.Currency,
+		}
+		if err := tx.QueryRowContext(ctx, "SELECT username, email FROM users WHERE id = ?", settings.UserID).Scan(&payload.Username, &payload.Email); err != nil {
+			return err
+		}
+		if err := recordUserEvent(ctx, tx, events.UserUpdated, settings.UserID, payload); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO user_settings (user_id, week_start, theme, notify_habit_reminders, notify_weekly_summary, notify_product_updates)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE
+				week_start = VALUES(week_start), theme = VALUES(theme),
+				notify_habit_reminders = VALUES(notify_habit_reminders),
+				notify_weekly_summary = VALUES(notify_weekly_summary),
+				notify_product_updates = VALUES(notify_product_updates)
+		`, settings.UserID, settings.WeekStart, settings.Theme, settings.HabitReminders, settings.WeeklySummary, settings.ProductUpdates)
+		return err
+	})
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/tracing"
+)
+
+// Token purposes
+const (
+	TokenEmailVerification = "email_verification"
+	TokenPasswordReset     = "password_reset"
+	TokenMFAChallenge      = "mfa_challenge"
+	TokenAccountUnlock     = "account_unlock"
+)
+
+// TokenRepository stores single-use tokens handed to users, such as emailed
+// links and sign-in challenges. Only a
+// hash of each token is kept, and issuing a new token for a purpose
+// invalidates the user's earlier ones.
+type TokenRepository struct {
+	db *sql.DB
+}
+
+// NewTokenRepository creates a new token repository
+func NewTokenRepository(db *sql.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// Create stores a token hash for userID, replacing any unused token for the same purpose
+func (r *TokenRepository) Create(ctx context.Context, userID int64, purpose, tokenHash string, expiresAt time.Time) error {
+	ctx, span := tracing.Start(ctx, "TokenRepository.Create")
+	defer span.End()
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE user_tokens
+			SET used_at = CURRENT_TIMESTAMP
+			WHERE user_id = ? AND purpose = ? AND used_at IS NULL
+		`, userID, purpose)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+			VALUES (?, ?, ?, ?)
+		`, userID, purpose, tokenHash, expiresAt.UTC())
+		return err
+	})
+}
+
+// Consume marks an unused, unexpired token as used and runs use, if not nil,
+// with its owner in the same transaction. It returns sql.ErrNoRows for
+// unknown, used or expired tokens.
+func (r *TokenRepository) Consume(ctx context.Context, purpose, tokenHash string, use func(tx *sql.Tx, userID int64) error) (int64, error) {
+	ctx, span := tracing.Start(ctx, "TokenRepository.Consume")
+	defer span.End()
+
+	var userID int64
+	err := database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		query := `
+			SELECT id, user_id FROM user_tokens
+			WHERE token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > UTC_TIMESTAMP()
+			FOR UPDATE
+		`
+
+		var id int64
+		if err := tx.QueryRowContext(ctx, query, tokenHash, purpose).Scan(&id, &userID); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = ?", id); err != nil {
+			return err
+		}
+
+		if use == nil {
+			return nil
+		}
+		return use(tx, userID)
+	})
+	return userID, err
+}
+
+// LastIssued returns when the user was last sent a token for purpose; ok is false if never
+func (r *TokenRepository) LastIssued(ctx context.Context, userID int64, purpose string) (issuedAt time.Time, ok bool, err error) {
+	ctx, span := tracing.Start(ctx, "TokenRepository.LastIssued")
+	defer span.End()
+
+	var last sql.NullTime
+	query := "SELECT MAX(created_at) FROM user_tokens WHERE user_id = ? AND purpose = ?"
+	if err := r.db.QueryRowContext(ctx, query, userID, purpose).Scan(&last); err != nil {
+		return time.Time{}, false, err
+	}
+	return last.Time, last.Valid, nil
+}
@@ -1,20 +1,47 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"strings"
 	"time"
+
+	"dailytrackr/shared/database"
+	"dailytrackr/shared/events"
+	"dailytrackr/shared/tracing"
 )
 
 // User represents the user model
 type User struct {
-	ID           int64     `json:"id" db:"id"`
-	Username     string    `json:"username" db:"username"`
-	Email        string    `json:"email" db:"email"`
-	PasswordHash string    `json:"-" db:"password_hash"` // Hidden from JSON
-	Bio          string    `json:"bio" db:"bio"`
-	ProfilePhoto string    `json:"profile_photo" db:"profile_photo"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID              int64      `json:"id" db:"id"`
+	Username        string     `json:"username" db:"username"`
+	Email           string     `json:"email" db:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at" db:"email_verified_at"` // nil until the address is confirmed
+	TOTPEnabledAt   *time.Time `json:"totp_enabled_at" db:"totp_enabled_at"`     // nil unless two-factor authentication is on
+	DisabledAt      *time.Time `json:"disabled_at" db:"disabled_at"`             // set while an administrator has disabled the account
+	PasswordHash    string     `json:"-" db:"password_hash"`                     // Hidden from JSON
+	Bio             string     `json:"bio" db:"bio"`
+	ProfilePhoto    string     `json:"profile_photo" db:"profile_photo"`
+	Language        string     `json:"language" db:"language"`
+	Timezone        string     `json:"timezone" db:"timezone"`
+	Currency        string     `json:"currency" db:"currency"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// EmailVerified reports whether the user has confirmed their email address
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// TwoFactorEnabled reports whether sign-in requires an authenticator code
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// Disabled reports whether an administrator has disabled the account
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
 }
 
 // UserRepository handles database operations for users
@@ -28,13 +55,16 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 }
 
 // Create creates a new user in the database
-func (r *UserRepository) Create(user *User) error {
+func (r *UserRepository) Create(ctx context.Context, user *User) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Create")
+	defer span.End()
+
 	query := `
-		INSERT INTO users (username, email, password_hash) 
-		VALUES (?, ?, ?)
+		INSERT INTO users (username, email, password_hash, language, timezone, currency) 
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query, user.Username, user.Email, user.PasswordHash)
+	result, err := r.db.ExecContext(ctx, query, user.Username, user.Email, user.PasswordHash, user.Language, user.Timezone, user.Currency)
 	if err != nil {
 		return err
 	}
@@ -47,28 +77,37 @@ func (r *UserRepository) Create(user *User) error {
 	user.ID = id
 
 	// Get the created user to populate timestamps
-	return r.GetByIDInto(user.ID, user)
+	return r.GetByIDInto(ctx, user.ID, user)
 }
 
 // GetByEmail retrieves a user by email
-func (r *UserRepository) GetByEmail(email string) (*User, error) {
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetByEmail")
+	defer span.End()
+
 	user := &User{}
 	query := `
 		SELECT id, username, email, password_hash, 
 		       COALESCE(bio, '') as bio, 
 		       COALESCE(profile_photo, '') as profile_photo,
-		       created_at, updated_at 
+		       language, timezone, currency, email_verified_at, totp_enabled_at, disabled_at, created_at, updated_at 
 		FROM users 
 		WHERE email = ?
 	`
 
-	err := r.db.QueryRow(query, email).Scan(
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
 		&user.Bio,
 		&user.ProfilePhoto,
+		&user.Language,
+		&user.Timezone,
+		&user.Currency,
+		&user.EmailVerifiedAt,
+		&user.TOTPEnabledAt,
+		&user.DisabledAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -81,29 +120,41 @@ func (r *UserRepository) GetByEmail(email string) (*User, error) {
 }
 
 // GetByID retrieves a user by ID
-func (r *UserRepository) GetByID(id int64) (*User, error) {
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (*User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetByID")
+	defer span.End()
+
 	user := &User{}
-	return user, r.GetByIDInto(id, user)
+	return user, r.GetByIDInto(ctx, id, user)
 }
 
 // GetByIDInto retrieves a user by ID into existing struct
-func (r *UserRepository) GetByIDInto(id int64, user *User) error {
+func (r *UserRepository) GetByIDInto(ctx context.Context, id int64, user *User) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetByIDInto")
+	defer span.End()
+
 	query := `
 		SELECT id, username, email, password_hash, 
 		       COALESCE(bio, '') as bio, 
 		       COALESCE(profile_photo, '') as profile_photo,
-		       created_at, updated_at 
+		       language, timezone, currency, email_verified_at, totp_enabled_at, disabled_at, created_at, updated_at 
 		FROM users 
 		WHERE id = ?
 	`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
 		&user.Bio,
 		&user.ProfilePhoto,
+		&user.Language,
+		&user.Timezone,
+		&user.Currency,
+		&user.EmailVerifiedAt,
+		&user.TOTPEnabledAt,
+		&user.DisabledAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -112,24 +163,33 @@ func (r *UserRepository) GetByIDInto(id int64, user *User) error {
 }
 
 // GetByUsername retrieves a user by username
-func (r *UserRepository) GetByUsername(username string) (*User, error) {
+func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetByUsername")
+	defer span.End()
+
 	user := &User{}
 	query := `
 		SELECT id, username, email, password_hash, 
 		       COALESCE(bio, '') as bio, 
 		       COALESCE(profile_photo, '') as profile_photo,
-		       created_at, updated_at 
+		       language, timezone, currency, email_verified_at, totp_enabled_at, disabled_at, created_at, updated_at 
 		FROM users 
 		WHERE username = ?
 	`
 
-	err := r.db.QueryRow(query, username).Scan(
+	err := r.db.QueryRowContext(ctx, query, username).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.PasswordHash,
 		&user.Bio,
 		&user.ProfilePhoto,
+		&user.Language,
+		&user.Timezone,
+		&user.Currency,
+		&user.EmailVerifiedAt,
+		&user.TOTPEnabledAt,
+		&user.DisabledAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -141,31 +201,108 @@ func (r *UserRepository) GetByUsername(username string) (*User, error) {
 	return user, nil
 }
 
-// EmailExists checks if an email already exists
-func (r *UserRepository) EmailExists(email string) (bool, error) {
-	var count int
-	query := "SELECT COUNT(*) FROM users WHERE email = ?"
-	err := r.db.QueryRow(query, email).Scan(&count)
-	return count > 0, err
+// likeEscaper escapes LIKE wildcards so searched text matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Search lists users whose username or email contains query (all users when
+// it is empty), newest first, with the total number matching
+func (r *UserRepository) Search(ctx context.Context, query string, offset, limit int) ([]User, int, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Search")
+	defer span.End()
+
+	where := `WHERE ? = '' OR username LIKE ? ESCAPE '\\' OR email LIKE ? ESCAPE '\\'`
+	pattern := "%" + likeEscaper.Replace(query) + "%"
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users "+where, query, pattern, pattern).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, username, email, 
+		       COALESCE(bio, '') as bio, 
+		       COALESCE(profile_photo, '') as profile_photo,
+		       language, timezone, currency, email_verified_at, totp_enabled_at, disabled_at, created_at, updated_at 
+		FROM users 
+		`+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, query, pattern, pattern, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var user User
+		err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Bio,
+			&user.ProfilePhoto,
+			&user.Language,
+			&user.Timezone,
+			&user.Currency,
+			&user.EmailVerifiedAt,
+			&user.TOTPEnabledAt,
+			&user.DisabledAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
 }
 
-// UsernameExists checks if a username already exists
-func (r *UserRepository) UsernameExists(username string) (bool, error) {
-	var count int
-	query := "SELECT COUNT(*) FROM users WHERE username = ?"
-	err := r.db.QueryRow(query, username).Scan(&count)
-	return count > 0, err
+// Disable stops a user signing in and signs them out of every session
+func (r *UserRepository) Disable(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Disable")
+	defer span.End()
+
+	query := `
+		UPDATE users 
+		SET disabled_at = COALESCE(disabled_at, CURRENT_TIMESTAMP), sessions_revoked_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP 
+		WHERE id = ?
+	`
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, userID)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return sql.ErrNoRows
+		}
+
+		_, err = revokeSessions(ctx, tx, userID, "")
+		return err
+	})
 }
 
-// Update updates user information (username, email, bio)
-func (r *UserRepository) Update(user *User) error {
+// Enable lets a disabled user sign in again
+func (r *UserRepository) Enable(ctx context.Context, userID int64) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Enable")
+	defer span.End()
+
 	query := `
 		UPDATE users 
-		SET username = ?, email = ?, bio = ?, updated_at = CURRENT_TIMESTAMP 
+		SET disabled_at = NULL, updated_at = CURRENT_TIMESTAMP 
 		WHERE id = ?
 	`
 
-	result, err := r.db.Exec(query, user.Username, user.Email, user.Bio, user.ID)
+	result, err := r.db.ExecContext(ctx, query, userID)
 	if err != nil {
 		return err
 	}
@@ -182,15 +319,92 @@ func (r *UserRepository) Update(user *User) error {
 	return nil
 }
 
+// EmailExists checks if an email already exists
+func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.EmailExists")
+	defer span.End()
+
+	var count int
+	query := "SELECT COUNT(*) FROM users WHERE email = ?"
+	err := r.db.QueryRowContext(ctx, query, email).Scan(&count)
+	return count > 0, err
+}
+
+// UsernameExists checks if a username already exists
+func (r *UserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.UsernameExists")
+	defer span.End()
+
+	var count int
+	query := "SELECT COUNT(*) FROM users WHERE username = ?"
+	err := r.db.QueryRowContext(ctx, query, username).Scan(&count)
+	return count > 0, err
+}
+
+// Update updates user information (username, email, bio, language, timezone, currency);
+// a changed email is saved unverified when EmailVerifiedAt has been cleared
+func (r *UserRepository) Update(ctx context.Context, user *User) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Update")
+	defer span.End()
+
+	query := `
+		UPDATE users 
+		SET username = ?, email = ?, bio = ?, language = ?, timezone = ?, currency = ?, email_verified_at = ?, updated_at = CURRENT_TIMESTAMP 
+		WHERE id = ?
+	`
+
+	return database.WithTx(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, user.Username, user.Email, user.Bio, user.Language, user.Timezone, user.Currency, user.EmailVerifiedAt, user.ID)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return sql.ErrNoRows
+		}
+
+		return recordUserEvent(ctx, tx, events.UserUpdated, user.ID, events.UserPayload{
+			Username: user.Username,
+			Email:    user.Email,
+			Language: user.Language,
+			Timezone: user.Timezone,
+			Currency: user.Currency,
+		})
+	})
+}
+
+// MarkEmailVerified records within tx that the user confirmed their email address
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, tx *sql.Tx, userID int64) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.MarkEmailVerified")
+	defer span.End()
+
+	query := `
+		UPDATE users 
+		SET email_verified_at = COALESCE(email_verified_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP 
+		WHERE id = ?
+	`
+
+	_, err := tx.ExecContext(ctx, query, userID)
+	return err
+}
+
 // UpdatePassword updates user password
-func (r *UserRepository) UpdatePassword(userID int64, passwordHash string) error {
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdatePassword")
+	defer span.End()
+
 	query := `
 		UPDATE users 
 		SET password_hash = ?, updated_at = CURRENT_TIMESTAMP 
 		WHERE id = ?
 	`
 
-	result, err := r.db.Exec(query, passwordHash, userID)
+	result, err := r.db.ExecContext(ctx, query, passwordHash, userID)
 	if err != nil {
 		return err
 	}
@@ -207,15 +421,40 @@ func (r *UserRepository) UpdatePassword(userID int64, passwordHash string) error
 	return nil
 }
 
+// ResetPassword sets a new password hash within tx, signs the user out of
+// every existing session and lifts any login lockout
+func (r *UserRepository) ResetPassword(ctx context.Context, tx *sql.Tx, userID int64, passwordHash string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.ResetPassword")
+	defer span.End()
+
+	query := `
+		UPDATE users 
+		SET password_hash = ?, sessions_revoked_at = CURRENT_TIMESTAMP,
+			failed_login_count = 0, last_failed_login_at = NULL, locked_until = NULL,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+
+	if _, err := tx.ExecContext(ctx, query, passwordHash, userID); err != nil {
+		return err
+	}
+
+	_, err := revokeSessions(ctx, tx, userID, "")
+	return err
+}
+
 // UpdateProfilePhoto updates user profile photo
-func (r *UserRepository) UpdateProfilePhoto(userID int64, photoURL string) error {
+func (r *UserRepository) UpdateProfilePhoto(ctx context.Context, userID int64, photoURL string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdateProfilePhoto")
+	defer span.End()
+
 	query := `
 		UPDATE users 
 		SET profile_photo = ?, updated_at = CURRENT_TIMESTAMP 
 		WHERE id = ?
 	`
 
-	result, err := r.db.Exec(query, photoURL, userID)
+	result, err := r.db.ExecContext(ctx, query, photoURL, userID)
 	if err != nil {
 		return err
 	}
@@ -232,23 +471,11 @@ func (r *UserRepository) UpdateProfilePhoto(userID int64, photoURL string) error
 	return nil
 }
 
-// Delete deletes a user
-func (r *UserRepository) Delete(id int64) error {
-	query := "DELETE FROM users WHERE id = ?"
-
-	result, err := r.db.Exec(query, id)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
+// recordUserEvent writes a user.* event to the outbox within tx
+func recordUserEvent(ctx context.Context, tx *sql.Tx, eventType string, userID int64, payload events.UserPayload) error {
+	event, err := events.New(eventType, userID, payload)
 	if err != nil {
 		return err
 	}
-
-	if rowsAffected == 0 {
-		return sql.ErrNoRows
-	}
-
-	return nil
+	return events.Record(ctx, tx, event)
 }
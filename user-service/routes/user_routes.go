@@ -1,6 +1,9 @@
 package routes
 
 import (
+	"dailytrackr/shared/authz"
+	"dailytrackr/shared/middleware/ginmw"
+	"dailytrackr/shared/sessions"
 	"dailytrackr/user-service/handlers"
 	"dailytrackr/user-service/middleware"
 
@@ -8,17 +11,41 @@ import (
 )
 
 // SetupUserRoutes sets up all user-related routes
-func SetupUserRoutes(r *gin.Engine, userHandlers *handlers.UserHandlers) {
+func SetupUserRoutes(r *gin.Engine, userHandlers *handlers.UserHandlers, checker *sessions.Checker) {
 	// Public routes (no authentication required)
 	auth := r.Group("/auth")
 	{
 		auth.POST("/register", userHandlers.Register)
 		auth.POST("/login", userHandlers.Login)
+		auth.POST("/login/mfa", userHandlers.LoginMFA) // second step when two-factor authentication is on
+
+		// Email verification; the link in the email opens the GET form
+		auth.GET("/verify-email", userHandlers.VerifyEmail)
+		auth.POST("/verify-email", userHandlers.VerifyEmail)
+		auth.POST("/resend-verification", middleware.AuthMiddleware(checker), userHandlers.ResendVerification)
+
+		// Password reset; the emailed link carries the token to post with the new password
+		auth.POST("/forgot-password", userHandlers.ForgotPassword)
+		auth.POST("/reset-password", userHandlers.ResetPassword)
+
+		// Lifting a login lockout with the link emailed when it started
+		auth.GET("/unlock-account", userHandlers.UnlockAccount)
+		auth.POST("/unlock-account", userHandlers.UnlockAccount)
+
+		// Sign-in with OpenID Connect providers; the provider redirects back to the callback
+		auth.GET("/oidc/:provider/login", userHandlers.OIDCLogin)
+		auth.GET("/oidc/:provider/callback", userHandlers.OIDCCallback)
+
+		// Account deletion records, looked up by the ID returned on deletion
+		auth.GET("/account-deletions/:id", userHandlers.GetAccountDeletion)
 	}
 
+	// Data export downloads; the signed link is the credential
+	r.GET("/exports/download", userHandlers.DownloadExport)
+
 	// Protected routes (authentication required)
 	api := r.Group("/api/v1")
-	api.Use(middleware.AuthMiddleware())
+	api.Use(middleware.AuthMiddleware(checker))
 	{
 		// User profile routes
 		users := api.Group("/users")
@@ -28,19 +55,47 @@ func SetupUserRoutes(r *gin.Engine, userHandlers *handlers.UserHandlers) {
 			users.PUT("/profile", userHandlers.UpdateProfile)
 			users.PATCH("/profile", userHandlers.UpdateProfile) // Support both PUT and PATCH
 
+			// Preferences such as week start, theme and notifications
+			users.GET("/settings", userHandlers.GetSettings)
+			users.PATCH("/settings", userHandlers.UpdateSettings)
+
 			// Password management
 			users.PUT("/password", userHandlers.ChangePassword)
 			users.PATCH("/password", userHandlers.ChangePassword) // Support both PUT and PATCH
 
+			// Two-factor authentication
+			users.POST("/2fa/enroll", userHandlers.EnrollTwoFactor)
+			users.POST("/2fa/confirm", userHandlers.ConfirmTwoFactor)
+			users.POST("/2fa/disable", userHandlers.DisableTwoFactor)
+
+			// Signed-in devices
+			users.GET("/sessions", userHandlers.ListSessions)
+			users.DELETE("/sessions", userHandlers.RevokeOtherSessions) // all but the current one
+			users.DELETE("/sessions/:sessionId", userHandlers.RevokeSession)
+
 			// Profile photo management
 			users.POST("/profile/photo", userHandlers.UploadProfilePhoto)
 			users.PUT("/profile/photo", userHandlers.UploadProfilePhoto) // Alternative endpoint
 
 			// Account management
 			users.DELETE("/account", userHandlers.DeleteAccount)
+			users.GET("/export", userHandlers.ExportData) // personal data export, built in the background
 
 			// For other services to get user info
 			users.GET("/:id", userHandlers.GetUserByID)
 		}
+
+		// Administration; each route requires a permission from the caller's roles
+		admin := api.Group("/admin/users")
+		{
+			admin.GET("", ginmw.RequirePermission(authz.PermUsersRead), userHandlers.AdminListUsers)
+			admin.GET("/:id", ginmw.RequirePermission(authz.PermUsersRead), userHandlers.AdminGetUser)
+			admin.GET("/:id/usage", ginmw.RequirePermission(authz.PermUsageRead), userHandlers.AdminGetUsage)
+			admin.POST("/:id/disable", ginmw.RequirePermission(authz.PermUsersManage), userHandlers.AdminDisableUser)
+			admin.POST("/:id/enable", ginmw.RequirePermission(authz.PermUsersManage), userHandlers.AdminEnableUser)
+			admin.POST("/:id/unlock", ginmw.RequirePermission(authz.PermUsersManage), userHandlers.AdminUnlockUser)
+			admin.POST("/:id/2fa/reset", ginmw.RequirePermission(authz.PermUsersManage), userHandlers.AdminResetTwoFactor)
+			admin.PUT("/:id/roles", ginmw.RequirePermission(authz.PermRolesManage), userHandlers.AdminSetRoles)
+		}
 	}
 }
@@ -0,0 +1,57 @@
+package services
+
+import "strings"
+
+// maxDeviceName matches user_sessions.device_name
+const maxDeviceName = 100
+
+// DeviceName labels a session for the sessions list: the name the client
+// sent, or else a description such as "Chrome on Windows" from its user agent
+func DeviceName(clientName, userAgent string) string {
+	if name := strings.TrimSpace(clientName); name != "" {
+		if len(name) > maxDeviceName {
+			name = name[:maxDeviceName]
+		}
+		return name
+	}
+
+	browser := firstMatch(userAgent, [][2]string{
+		// Order matters: Edge and Opera also claim Chrome, Chrome also claims Safari
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"okhttp", "Android app"},
+		{"CFNetwork", "iOS app"},
+	})
+	os := firstMatch(userAgent, [][2]string{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	})
+
+	switch {
+	case browser != "" && os != "":
+		return browser + " on " + os
+	case browser != "":
+		return browser
+	case os != "":
+		return os
+	default:
+		return "Unknown device"
+	}
+}
+
+// firstMatch returns the label of the first marker found in userAgent
+func firstMatch(userAgent string, markers [][2]string) string {
+	for _, marker := range markers {
+		if strings.Contains(userAgent, marker[0]) {
+			return marker[1]
+		}
+	}
+	return ""
+}
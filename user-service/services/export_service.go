@@ -0,0 +1,374 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"dailytrackr/shared/client"
+	"dailytrackr/shared/config"
+	"dailytrackr/shared/constants"
+	"dailytrackr/shared/dto"
+	"dailytrackr/shared/tracing"
+)
+
+// maxExportPhoto caps the size of a single photo copied into an export
+const maxExportPhoto = 20 << 20
+
+// ExportService packages everything the services hold about a user into a
+// ZIP archive: JSON (and CSV for tabular data) per service plus the photos
+// the records point to. It calls the other services as the user, so the
+// context must carry their authorization (client.WithAuthorization).
+type ExportService struct {
+	clients *client.Clients
+	http    *http.Client
+	dir     string
+}
+
+// NewExportService creates an export service writing archives to EXPORT_DIR
+func NewExportService(cfg *config.Config) *ExportService {
+	return &ExportService{
+		clients: client.New(cfg),
+		http:    &http.Client{Timeout: 30 * time.Second},
+		dir:     cfg.ExportDir,
+	}
+}
+
+// exportManifest describes an archive; photos that could not be copied are
+// listed with the reason, their URLs stay in the records
+type exportManifest struct {
+	GeneratedAt   time.Time      `json:"generated_at"`
+	UserID        int64          `json:"user_id"`
+	Files         []string       `json:"files"`
+	MissingPhotos []missingPhoto `json:"missing_photos,omitempty"`
+}
+
+type missingPhoto struct {
+	URL   string `json:"url"`
+	Error string `json:"error"`
+}
+
+// exportArchive writes files into a ZIP, recording them for the manifest
+type exportArchive struct {
+	zip      *zip.Writer
+	manifest exportManifest
+}
+
+// Build writes the export archive named id for profile and returns its path.
+// account holds what the user service keeps besides the profile, each entry
+// becoming a JSON file of that name.
+func (s *ExportService) Build(ctx context.Context, id string, profile dto.UserResponse, account map[string]any) (string, error) {
+	ctx, span := tracing.Start(ctx, "ExportService.Build")
+	defer span.End()
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", err
+	}
+
+	// Written under a temporary name, so a half-built archive is never served
+	final := filepath.Join(s.dir, id+".zip")
+	file, err := os.CreateTemp(s.dir, id+"-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	archive := &exportArchive{
+		zip:      zip.NewWriter(file),
+		manifest: exportManifest{GeneratedAt: time.Now().UTC(), UserID: profile.ID},
+	}
+	if err := s.write(ctx, archive, profile, account); err != nil {
+		return "", err
+	}
+
+	if err := archive.zip.Close(); err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(file.Name(), final); err != nil {
+		return "", err
+	}
+	return final, nil
+}
+
+// write gathers every section of the export into archive
+func (s *ExportService) write(ctx context.Context, archive *exportArchive, profile dto.UserResponse, account map[string]any) error {
+	if err := archive.json("profile.json", profile); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(account))
+	for name := range account {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := archive.json(name+".json", account[name]); err != nil {
+			return err
+		}
+	}
+
+	if profile.ProfilePhoto != "" {
+		s.photo(ctx, archive, "photos/profile", profile.ProfilePhoto)
+	}
+
+	activities, err := s.activities(ctx)
+	if err != nil {
+		return fmt.Errorf("activities: %w", err)
+	}
+	if err := archive.json("activities/activities.json", activities); err != nil {
+		return err
+	}
+	if err := archive.csv("activities/activities.csv", activityRows(activities)); err != nil {
+		return err
+	}
+	for _, activity := range activities {
+		if activity.PhotoURL != "" {
+			s.photo(ctx, archive, "photos/activity-"+strconv.FormatInt(activity.ID, 10), activity.PhotoURL)
+		}
+	}
+
+	habits, logs, err := s.habits(ctx)
+	if err != nil {
+		return fmt.Errorf("habits: %w", err)
+	}
+	if err := archive.json("habits/habits.json", habits); err != nil {
+		return err
+	}
+	if err := archive.csv("habits/habits.csv", habitRows(habits)); err != nil {
+		return err
+	}
+	if err := archive.json("habits/habit_logs.json", logs); err != nil {
+		return err
+	}
+	if err := archive.csv("habits/habit_logs.csv", habitLogRows(logs)); err != nil {
+		return err
+	}
+	for _, log := range logs {
+		if log.PhotoURL != "" {
+			s.photo(ctx, archive, "photos/habit-log-"+strconv.FormatInt(log.ID, 10), log.PhotoURL)
+		}
+	}
+
+	summaries, err := s.clients.AI.DailySummaries(ctx)
+	if err != nil {
+		return fmt.Errorf("ai summaries: %w", err)
+	}
+	if err := archive.json("ai/daily_summaries.json", summaries); err != nil {
+		return err
+	}
+	if err := archive.csv("ai/daily_summaries.csv", summaryRows(summaries)); err != nil {
+		return err
+	}
+
+	dashboard, err := s.clients.Stat.Dashboard(ctx)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+	if err := archive.json("stats/dashboard.json", dashboard); err != nil {
+		return err
+	}
+	progress, err := s.clients.Stat.HabitProgress(ctx)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+	if err := archive.json("stats/habit_progress.json", progress); err != nil {
+		return err
+	}
+
+	return archive.json("manifest.json", archive.manifest)
+}
+
+// activities returns all of the user's activities, following the cursor
+func (s *ExportService) activities(ctx context.Context) ([]dto.ActivityResponse, error) {
+	activities := []dto.ActivityResponse{}
+	opts := client.ActivityListOptions{PageOptions: client.PageOptions{Limit: constants.MaxPageLimit}}
+	for {
+		page, err := s.clients.Activity.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, page.Activities...)
+
+		if page.NextCursor == "" {
+			return activities, nil
+		}
+		opts.Cursor = page.NextCursor
+	}
+}
+
+// habits returns all of the user's habits and all of their logs
+func (s *ExportService) habits(ctx context.Context) ([]dto.HabitResponse, []dto.HabitLogResponse, error) {
+	habits, err := s.clients.Habit.List(ctx, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logs := []dto.HabitLogResponse{}
+	for _, habit := range habits {
+		opts := client.PageOptions{Limit: constants.MaxPageLimit}
+		for {
+			page, err := s.clients.Habit.ListLogs(ctx, habit.ID, opts)
+			if err != nil {
+				return nil, nil, err
+			}
+			logs = append(logs, page.Logs...)
+
+			if page.NextCursor == "" {
+				break
+			}
+			opts.Cursor = page.NextCursor
+		}
+	}
+	return habits, logs, nil
+}
+
+// photo copies the image at rawURL into the archive as name plus the URL's
+// extension. A photo that cannot be fetched is noted in the manifest
+// instead of failing the export.
+func (s *ExportService) photo(ctx context.Context, archive *exportArchive, name, rawURL string) {
+	if err := s.copyPhoto(ctx, archive, name, rawURL); err != nil {
+		archive.manifest.MissingPhotos = append(archive.manifest.MissingPhotos, missingPhoto{URL: rawURL, Error: err.Error()})
+	}
+}
+
+func (s *ExportService) copyPhoto(ctx context.Context, archive *exportArchive, name, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return errors.New("not an http(s) URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxExportPhoto {
+		return errors.New("photo too large")
+	}
+
+	ext := path.Ext(u.Path)
+	if ext == "" {
+		ext = ".jpg"
+	}
+
+	w, err := archive.create(name + ext)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, io.LimitReader(resp.Body, maxExportPhoto))
+	return err
+}
+
+// create adds a file to the archive
+func (a *exportArchive) create(name string) (io.Writer, error) {
+	a.manifest.Files = append(a.manifest.Files, name)
+	return a.zip.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: a.manifest.GeneratedAt,
+	})
+}
+
+// json adds v to the archive as indented JSON
+func (a *exportArchive) json(name string, v any) error {
+	w, err := a.create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// csv adds rows to the archive; the first row is the header
+func (a *exportArchive) csv(name string, rows [][]string) error {
+	w, err := a.create(name)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.WriteAll(rows)
+	return writer.Error()
+}
+
+func activityRows(activities []dto.ActivityResponse) [][]string {
+	rows := [][]string{{"id", "title", "start_time", "duration_mins", "cost", "currency", "photo_url", "note", "created_at", "updated_at"}}
+	for _, a := range activities {
+		cost, currency := "", ""
+		if a.Cost != nil {
+			cost, currency = strconv.FormatFloat(a.Cost.Major(), 'f', -1, 64), a.Cost.Currency
+		}
+		rows = append(rows, []string{
+			strconv.FormatInt(a.ID, 10), a.Title, csvTime(a.StartTime), strconv.Itoa(a.DurationMins),
+			cost, currency, a.PhotoURL, a.Note, csvTime(a.CreatedAt), csvTime(a.UpdatedAt),
+		})
+	}
+	return rows
+}
+
+func habitRows(habits []dto.HabitResponse) [][]string {
+	rows := [][]string{{"id", "title", "start_date", "end_date", "reminder_time", "created_at", "updated_at"}}
+	for _, h := range habits {
+		rows = append(rows, []string{
+			strconv.FormatInt(h.ID, 10), h.Title, csvDate(h.StartDate), csvDate(h.EndDate),
+			h.ReminderTime, csvTime(h.CreatedAt), csvTime(h.UpdatedAt),
+		})
+	}
+	return rows
+}
+
+func habitLogRows(logs []dto.HabitLogResponse) [][]string {
+	rows := [][]string{{"id", "habit_id", "date", "status", "photo_url", "note", "created_at", "updated_at"}}
+	for _, l := range logs {
+		rows = append(rows, []string{
+			strconv.FormatInt(l.ID, 10), strconv.FormatInt(l.HabitID, 10), csvDate(l.Date), l.Status,
+			l.PhotoURL, l.Note, csvTime(l.CreatedAt), csvTime(l.UpdatedAt),
+		})
+	}
+	return rows
+}
+
+func summaryRows(summaries []dto.DailySummary) [][]string {
+	rows := [][]string{{"id", "date", "summary_text", "ai_generated", "created_at", "updated_at"}}
+	for _, s := range summaries {
+		rows = append(rows, []string{
+			strconv.FormatInt(s.ID, 10), csvDate(s.Date), s.SummaryText, strconv.FormatBool(s.AIGenerated),
+			csvTime(s.CreatedAt), csvTime(s.UpdatedAt),
+		})
+	}
+	return rows
+}
+
+func csvTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func csvDate(t time.Time) string {
+	return t.Format(constants.DateFormat)
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"dailytrackr/shared/config"
+	"dailytrackr/shared/tracing"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// oidcLoginTTL is how long a user has to finish signing in at the provider
+const oidcLoginTTL = 10 * time.Minute
+
+// ErrOIDCLogin is returned when a callback does not match the login that
+// started it (state, nonce or expiry) or the provider rejects the code
+var ErrOIDCLogin = errors.New("oidc login failed")
+
+// OIDCLogin is the state of a sign-in in progress, kept by the browser in a
+// signed cookie between the redirect to the provider and the callback
+type OIDCLogin struct {
+	Provider  string `json:"p"`
+	State     string `json:"s"`
+	Nonce     string `json:"n"`
+	Verifier  string `json:"v"` // PKCE code verifier
+	ExpiresAt int64  `json:"e"`
+}
+
+// OIDCIdentity is what a provider tells us about the signed-in user
+type OIDCIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// OIDCService signs users in with OpenID Connect providers using the
+// authorization code flow with PKCE
+type OIDCService struct {
+	providers map[string]*oidcProvider
+	secret    []byte
+	client    *http.Client
+}
+
+type oidcProvider struct {
+	config      config.OIDCProvider
+	redirectURL string
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCService creates a service for the configured providers. Discovery
+// happens on first use, so a provider being down does not stop startup.
+func NewOIDCService(cfg *config.Config) *OIDCService {
+	providers := make(map[string]*oidcProvider, len(cfg.OIDCProviders))
+	for _, provider := range cfg.OIDCProviders {
+		providers[provider.Name] = &oidcProvider{
+			config:      provider,
+			redirectURL: cfg.PublicURL + "/auth/oidc/" + provider.Name + "/callback",
+		}
+	}
+
+	return &OIDCService{
+		providers: providers,
+		secret:    []byte("oidc:" + cfg.JWTSecret),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Has reports whether a provider is configured
+func (s *OIDCService) Has(name string) bool {
+	_, ok := s.providers[name]
+	return ok
+}
+
+// Start begins a sign-in with provider and returns the URL to send the user
+// to, plus the login state to keep until the callback
+func (s *OIDCService) Start(ctx context.Context, name string) (authURL string, login OIDCLogin, err error) {
+	ctx, span := tracing.Start(ctx, "OIDCService.Start")
+	defer span.End()
+
+	provider, err := s.provider(ctx, name)
+	if err != nil {
+		return "", OIDCLogin{}, err
+	}
+
+	login = OIDCLogin{
+		Provider:  name,
+		State:     randomString(),
+		Nonce:     randomString(),
+		Verifier:  oauth2.GenerateVerifier(),
+		ExpiresAt: time.Now().Add(oidcLoginTTL).Unix(),
+	}
+
+	authURL = provider.oauth.AuthCodeURL(login.State,
+		oauth2.S256ChallengeOption(login.Verifier),
+		oidc.Nonce(login.Nonce),
+	)
+	return authURL, login, nil
+}
+
+// Finish exchanges the authorization code from a callback and verifies the ID token
+func (s *OIDCService) Finish(ctx context.Context, login OIDCLogin, state, code string) (*OIDCIdentity, error) {
+	ctx, span := tracing.Start(ctx, "OIDCService.Finish")
+	defer span.End()
+
+	if state == "" || !hmac.Equal([]byte(state), []byte(login.State)) || time.Now().Unix() > login.ExpiresAt {
+		return nil, ErrOIDCLogin
+	}
+
+	provider, err := s.provider(ctx, login.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = oidc.ClientContext(ctx, s.client)
+	token, err := provider.oauth.Exchange(ctx, code, oauth2.VerifierOption(login.Verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: exchange: %v", ErrOIDCLogin, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: no id_token in token response", ErrOIDCLogin)
+	}
+
+	idToken, err := provider.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLogin, err)
+	}
+	if !hmac.Equal([]byte(idToken.Nonce), []byte(login.Nonce)) {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOIDCLogin)
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     any    `json:"email_verified"` // some providers send "true"
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrOIDCLogin, err)
+	}
+
+	return &OIDCIdentity{
+		Provider:          login.Provider,
+		Subject:           idToken.Subject,
+		Email:             strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified:     claims.EmailVerified == true || claims.EmailVerified == "true",
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// EncodeLogin signs login for the state cookie
+func (s *OIDCService) EncodeLogin(login OIDCLogin) (string, error) {
+	payload, err := json.Marshal(login)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// DecodeLogin checks the signature of a state cookie and returns the login it holds
+func (s *OIDCService) DecodeLogin(cookie string) (OIDCLogin, error) {
+	var login OIDCLogin
+
+	encoded, signature, found := strings.Cut(cookie, ".")
+	if !found {
+		return login, ErrOIDCLogin
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return login, ErrOIDCLogin
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return login, ErrOIDCLogin
+	}
+	if err := json.Unmarshal(payload, &login); err != nil {
+		return login, ErrOIDCLogin
+	}
+	return login, nil
+}
+
+// provider returns a configured provider, running discovery the first time
+func (s *OIDCService) provider(ctx context.Context, name string) (*oidcProvider, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown OIDC provider %q", name)
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	if provider.oauth != nil {
+		return provider, nil
+	}
+
+	// The provider keeps this context to fetch signing keys later, so it must outlive the request
+	discovered, err := oidc.NewProvider(oidc.ClientContext(context.WithoutCancel(ctx), s.client), provider.config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("discover %s: %w", name, err)
+	}
+
+	provider.oauth = &oauth2.Config{
+		ClientID:     provider.config.ClientID,
+		ClientSecret: provider.config.ClientSecret,
+		Endpoint:     discovered.Endpoint(),
+		RedirectURL:  provider.redirectURL,
+		Scopes:       provider.config.Scopes,
+	}
+	provider.verifier = discovered.Verifier(&oidc.Config{ClientID: provider.config.ClientID})
+	return provider, nil
+}
+
+// sign computes the HMAC of a state cookie payload
+func (s *OIDCService) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// randomString returns 32 random bytes, base64url-encoded
+func randomString() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
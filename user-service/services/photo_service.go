@@ -3,7 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"mime/multipart"
 	"path/filepath"
 	"strings"
@@ -11,9 +11,12 @@ import (
 
 	"dailytrackr/shared/config"
 	"dailytrackr/shared/constants"
+	"dailytrackr/shared/metrics"
+	"dailytrackr/shared/tracing"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type PhotoService struct {
@@ -23,15 +26,14 @@ type PhotoService struct {
 
 // NewPhotoService creates a new photo service instance
 func NewPhotoService(cfg *config.Config) *PhotoService {
-	log.Printf("Initializing PhotoService for User Service...")
-	log.Printf("Cloudinary Config - Name: '%s', Key: '%s', Secret Set: %v",
-		cfg.CloudinaryCloudName,
-		cfg.CloudinaryAPIKey,
-		cfg.CloudinaryAPISecret != "")
+	slog.Debug("initializing photo service",
+		"cloud_name", cfg.CloudinaryCloudName,
+		"api_key", cfg.CloudinaryAPIKey,
+		"api_secret_set", cfg.CloudinaryAPISecret != "")
 
 	// Initialize Cloudinary if credentials are available
 	if cfg.CloudinaryCloudName == "" || cfg.CloudinaryAPIKey == "" || cfg.CloudinaryAPISecret == "" {
-		log.Printf("⚠️  Warning: Cloudinary credentials not configured. Photo upload will be disabled.")
+		slog.Warn("cloudinary credentials not configured, photo upload disabled")
 		return &PhotoService{
 			cloudinary: nil,
 			config:     cfg,
@@ -44,14 +46,14 @@ func NewPhotoService(cfg *config.Config) *PhotoService {
 		cfg.CloudinaryAPISecret,
 	)
 	if err != nil {
-		log.Printf("❌ Failed to initialize Cloudinary client: %v", err)
+		slog.Error("failed to initialize cloudinary client", "error", err)
 		return &PhotoService{
 			cloudinary: nil,
 			config:     cfg,
 		}
 	}
 
-	log.Println("✅ Cloudinary client initialized successfully for User Service!")
+	slog.Info("cloudinary client initialized")
 
 	return &PhotoService{
 		cloudinary: cld,
@@ -60,7 +62,7 @@ func NewPhotoService(cfg *config.Config) *PhotoService {
 }
 
 // UploadPhoto uploads a profile photo to Cloudinary
-func (s *PhotoService) UploadPhoto(file *multipart.FileHeader) (string, error) {
+func (s *PhotoService) UploadPhoto(ctx context.Context, file *multipart.FileHeader) (string, error) {
 	// Check if Cloudinary is available
 	if s.cloudinary == nil {
 		return "", fmt.Errorf("photo upload service is not available. Please configure Cloudinary credentials")
@@ -87,14 +89,15 @@ func (s *PhotoService) UploadPhoto(file *multipart.FileHeader) (string, error) {
 	filename := s.generateProfileFilename()
 
 	// Upload to Cloudinary with profile-specific settings
-	log.Printf("Uploading profile photo '%s' to Cloudinary...", filename)
+	slog.Debug("uploading profile photo", "public_id", filename)
 
 	// Create bool pointers for Cloudinary parameters
 	uniqueFilename := true
 	useFilename := false
 
+	ctx, span := tracing.StartClient(ctx, "cloudinary.upload", attribute.String("cloudinary.folder", constants.ProfilePhotoPath))
 	uploadResult, err := s.cloudinary.Upload.Upload(
-		context.Background(),
+		ctx,
 		src,
 		uploader.UploadParams{
 			PublicID:       filename,
@@ -107,11 +110,13 @@ func (s *PhotoService) UploadPhoto(file *multipart.FileHeader) (string, error) {
 		},
 	)
 
+	tracing.End(span, err)
+	metrics.ObserveUpload("profile", err)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload to Cloudinary: %v", err)
 	}
 
-	log.Printf("✅ Profile photo uploaded successfully. Secure URL: %s", uploadResult.SecureURL)
+	slog.Info("profile photo uploaded", "url", uploadResult.SecureURL)
 	return uploadResult.SecureURL, nil
 }
 
@@ -150,8 +155,22 @@ func (s *PhotoService) generateRandomString(length int) string {
 	return string(result)
 }
 
+// DeleteStoredPhoto deletes a photo this service stored and does nothing for
+// any other URL, so purging a user's data never waits on a photo that cannot
+// be deleted here
+func (s *PhotoService) DeleteStoredPhoto(ctx context.Context, url string) error {
+	if !strings.Contains(url, "cloudinary.com") {
+		return nil
+	}
+	if s.cloudinary == nil {
+		slog.Warn("photo service is not available, leaving photo in place", "url", url)
+		return nil
+	}
+	return s.DeletePhoto(ctx, url)
+}
+
 // DeletePhoto removes a photo from Cloudinary (optional feature)
-func (s *PhotoService) DeletePhoto(publicID string) error {
+func (s *PhotoService) DeletePhoto(ctx context.Context, publicID string) error {
 	if s.cloudinary == nil {
 		return fmt.Errorf("photo service is not available")
 	}
@@ -181,18 +200,20 @@ func (s *PhotoService) DeletePhoto(publicID string) error {
 		}
 	}
 
-	log.Printf("Deleting photo with public ID: %s", publicID)
+	slog.Debug("deleting photo", "public_id", publicID)
 
-	_, err := s.cloudinary.Upload.Destroy(context.Background(), uploader.DestroyParams{
+	ctx, span := tracing.StartClient(ctx, "cloudinary.destroy", attribute.String("cloudinary.public_id", publicID))
+	_, err := s.cloudinary.Upload.Destroy(ctx, uploader.DestroyParams{
 		PublicID: publicID,
 	})
+	tracing.End(span, err)
 
 	if err != nil {
-		log.Printf("Failed to delete photo: %v", err)
+		slog.Error("failed to delete photo", "public_id", publicID, "error", err)
 		return fmt.Errorf("failed to delete photo: %v", err)
 	}
 
-	log.Printf("✅ Photo deleted successfully: %s", publicID)
+	slog.Info("photo deleted", "public_id", publicID)
 	return nil
 }
 
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenServiceVerify(t *testing.T) {
+	s := NewTokenService("test-secret")
+
+	token, hash, err := s.Issue("email_verification")
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	nonce, signature, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name    string
+		service *TokenService
+		purpose string
+		token   string
+		wantOK  bool
+	}{
+		{"valid", s, "email_verification", token, true},
+		{"other purpose", s, "password_reset", token, false},
+		{"other secret", NewTokenService("other-secret"), "email_verification", token, false},
+		{"tampered nonce", s, "email_verification", flipFirst(nonce) + "." + signature, false},
+		{"tampered signature", s, "email_verification", nonce + "." + flipFirst(signature), false},
+		{"signature not base64", s, "email_verification", nonce + ".!!!", false},
+		{"no signature", s, "email_verification", nonce, false},
+		{"empty", s, "email_verification", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.service.Verify(tt.purpose, tt.token)
+			if ok != tt.wantOK {
+				t.Fatalf("Verify() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got != hash {
+				t.Errorf("Verify() hash = %q, want the hash from Issue %q", got, hash)
+			}
+		})
+	}
+}
+
+// flipFirst changes the first character of a base64url string to another valid one
+func flipFirst(s string) string {
+	if s[0] == 'A' {
+		return "B" + s[1:]
+	}
+	return "A" + s[1:]
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"dailytrackr/shared/config"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// TOTP parameters (RFC 6238 defaults, which every authenticator app supports)
+const (
+	totpIssuer = "DailyTrackr"
+	totpDigits = 6
+	totpPeriod = 30 // seconds
+	totpSkew   = 1  // steps accepted either side of now, for clock drift
+
+	recoveryCodeCount = 10
+	qrCodeSize        = 256 // pixels
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPService generates and checks time-based one-time passwords. Secrets are
+// sealed with AES-GCM before they are stored, since unlike passwords they
+// must be read back to check a code.
+type TOTPService struct {
+	aead cipher.AEAD
+}
+
+// NewTOTPService creates a TOTP service sealing secrets with TOTP_ENCRYPTION_KEY,
+// or with a key derived from the JWT secret when that is not set
+func NewTOTPService(cfg *config.Config) *TOTPService {
+	key := cfg.TOTPEncryptionKey
+	if key == "" {
+		key = "totp:" + cfg.JWTSecret
+	}
+	sum := sha256.Sum256([]byte(key))
+
+	block, _ := aes.NewCipher(sum[:]) // a 32-byte key cannot fail
+	aead, _ := cipher.NewGCM(block)
+	return &TOTPService{aead: aead}
+}
+
+// NewSecret returns a random base32 secret
+func (s *TOTPService) NewSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base32NoPadding.EncodeToString(secret), nil
+}
+
+// URI returns the otpauth:// URI authenticator apps import, labelled with account
+func (s *TOTPService) URI(account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", totpIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(totpIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// QRCode renders uri as a PNG QR code
+func (s *TOTPService) QRCode(uri string) ([]byte, error) {
+	return qrcode.Encode(uri, qrcode.Medium, qrCodeSize)
+}
+
+// Validate checks code against secret at now and returns the time step it
+// matched, so callers can refuse to accept the same step twice
+func (s *TOTPService) Validate(secret, code string, now time.Time) (step int64, ok bool) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		candidate := current + offset
+		if hmac.Equal([]byte(hotp(key, candidate)), []byte(code)) {
+			return candidate, true
+		}
+	}
+	return 0, false
+}
+
+// IsTOTPCode reports whether code looks like an authenticator code rather than a recovery code
+func (s *TOTPService) IsTOTPCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Seal encrypts a secret for storage
+func (s *TOTPService) Seal(secret string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a secret sealed by Seal
+func (s *TOTPService) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < s.aead.NonceSize() {
+		return "", errors.New("sealed secret too short")
+	}
+
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	secret, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// NewRecoveryCodes returns a fresh set of recovery codes, formatted for the
+// user, and the hashes to store for them
+func (s *TOTPService) NewRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+
+		code := base32NoPadding.EncodeToString(raw)[:10]
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, Hash(code))
+	}
+	return codes, hashes, nil
+}
+
+// RecoveryCodeHash returns the stored hash of a recovery code as the user
+// typed it, ignoring case, spaces and dashes
+func (s *TOTPService) RecoveryCodeHash(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return Hash(code)
+}
+
+// hotp computes the RFC 4226 one-time password for counter
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"dailytrackr/shared/config"
+)
+
+// rfc6238Secret is the RFC 6238 SHA-1 test key "12345678901234567890" in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPServiceValidate(t *testing.T) {
+	s := NewTOTPService(&config.Config{JWTSecret: "test-secret"})
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      int64
+		wantStep int64
+		wantOK   bool
+	}{
+		// Codes are the last six digits of the RFC 6238 appendix B values
+		{"rfc 6238 at 59", rfc6238Secret, "287082", 59, 1, true},
+		{"rfc 6238 at 1111111109", rfc6238Secret, "081804", 1111111109, 37037036, true},
+		{"rfc 6238 at 1234567890", rfc6238Secret, "005924", 1234567890, 41152263, true},
+		{"rfc 6238 at 2000000000", rfc6238Secret, "279037", 2000000000, 66666666, true},
+		{"lower-case secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", 59, 1, true},
+		{"one step late", rfc6238Secret, "287082", 59 + 30, 1, true},
+		{"one step early", rfc6238Secret, "287082", 59 - 30, 1, true},
+		{"two steps late", rfc6238Secret, "287082", 59 + 60, 0, false},
+		{"two steps early", rfc6238Secret, "081804", 1111111109 - 60, 0, false},
+		{"wrong code", rfc6238Secret, "287083", 59, 0, false},
+		{"too short", rfc6238Secret, "28708", 59, 0, false},
+		{"too long", rfc6238Secret, "2870820", 59, 0, false},
+		{"empty code", rfc6238Secret, "", 59, 0, false},
+		{"invalid secret", "not base32!", "287082", 59, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := s.Validate(tt.secret, tt.code, time.Unix(tt.now, 0))
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate() = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
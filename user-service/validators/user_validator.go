@@ -7,6 +7,9 @@ import (
 	"unicode"
 
 	"dailytrackr/shared/dto"
+	"dailytrackr/shared/i18n"
+	"dailytrackr/shared/money"
+	"dailytrackr/shared/utils"
 )
 
 // UserValidator provides advanced validation for user-related operations
@@ -34,6 +37,16 @@ func (v *UserValidator) ValidateRegistration(req dto.RegisterRequest) error {
 		return err
 	}
 
+	// Validate time zone if provided
+	if req.Timezone != "" && !utils.ValidTimezone(req.Timezone) {
+		return errors.New("timezone must be an IANA time zone, e.g. Asia/Jakarta")
+	}
+
+	// Validate currency if provided
+	if req.Currency != "" && !money.Valid(req.Currency) {
+		return errors.New("currency must be a supported ISO 4217 code, e.g. IDR")
+	}
+
 	return nil
 }
 
@@ -60,6 +73,21 @@ func (v *UserValidator) ValidateProfileUpdate(req dto.UpdateProfileRequest) erro
 		}
 	}
 
+	// Validate language if provided
+	if req.Language != nil && !i18n.Supported(*req.Language) {
+		return errors.New("language must be one of: en, id")
+	}
+
+	// Validate time zone if provided
+	if req.Timezone != nil && !utils.ValidTimezone(*req.Timezone) {
+		return errors.New("timezone must be an IANA time zone, e.g. Asia/Jakarta")
+	}
+
+	// Validate currency if provided
+	if req.Currency != nil && !money.Valid(*req.Currency) {
+		return errors.New("currency must be a supported ISO 4217 code, e.g. IDR")
+	}
+
 	return nil
 }
 